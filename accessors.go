@@ -0,0 +1,67 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// GetInt retrieves key as by [Vault.Get] and parses its value as a
+// base-10 int.
+func (v *vault) GetInt(ctx context.Context, key string) (int, error) {
+	return getParsed(ctx, v, key, "int", strconv.Atoi)
+}
+
+// GetBool retrieves key as by [Vault.Get] and parses its value with
+// [strconv.ParseBool], so "1", "t", "TRUE", "false" and similar are
+// accepted.
+func (v *vault) GetBool(ctx context.Context, key string) (bool, error) {
+	return getParsed(ctx, v, key, "bool", strconv.ParseBool)
+}
+
+// GetDuration retrieves key as by [Vault.Get] and parses its value with
+// [time.ParseDuration], e.g. "1h30m".
+func (v *vault) GetDuration(ctx context.Context, key string) (time.Duration, error) {
+	return getParsed(ctx, v, key, "duration", time.ParseDuration)
+}
+
+// GetOr retrieves key as by [Vault.Get], including any auto-refresh. If
+// the key still cannot be found, it returns an entry holding def with
+// Source "default" instead of [ErrNotFound]. Other errors, including a
+// failed refresh, are returned as-is.
+func (v *vault) GetOr(ctx context.Context, key, def string) (Entry, error) {
+	e, err := v.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return Entry{Key: key, Value: def, Source: "default"}, nil
+	}
+	return e, err
+}
+
+// getParsed resolves key and converts its value with parse. Parse failures
+// name the key and the raw value.
+func getParsed[T any](ctx context.Context, v *vault, key, kind string, parse func(string) (T, error)) (T, error) {
+	var zero T
+
+	e, err := v.Get(ctx, key)
+	if err != nil {
+		return zero, err
+	}
+
+	out, err := parse(e.Value)
+	if err != nil {
+		return zero, fmt.Errorf("vault: parse %q value %q as %s: %w", key, e.Value, kind, unwrapNumError(err))
+	}
+	return out, nil
+}
+
+// unwrapNumError strips the [strconv.NumError] wrapper, whose message
+// repeats the raw value already included by the caller.
+func unwrapNumError(err error) error {
+	var numErr *strconv.NumError
+	if errors.As(err, &numErr) {
+		return numErr.Err
+	}
+	return err
+}
@@ -0,0 +1,180 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func newAccessorVault(t *testing.T, value string) vault.Vault {
+	t.Helper()
+
+	v := vault.New()
+	require.NoError(t, v.Set(context.Background(), vault.Entry{Key: "k", Value: value}))
+	return v
+}
+
+func TestGetInt(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{value: "42", want: 42},
+		{value: "-7", want: -7},
+		{value: "0", want: 0},
+		{value: "4.2", wantErr: true},
+		{value: "forty", wantErr: true},
+		{value: "", wantErr: true},
+		{value: "99999999999999999999", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newAccessorVault(t, tt.value).GetInt(context.Background(), "k")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), `"k"`)
+				assert.Contains(t, err.Error(), strconv.Quote(tt.value))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetBool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    bool
+		wantErr bool
+	}{
+		{value: "true", want: true},
+		{value: "TRUE", want: true},
+		{value: "t", want: true},
+		{value: "1", want: true},
+		{value: "false", want: false},
+		{value: "F", want: false},
+		{value: "0", want: false},
+		{value: "yes", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newAccessorVault(t, tt.value).GetBool(context.Background(), "k")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), strconv.Quote(tt.value))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "1h30m", want: 90 * time.Minute},
+		{value: "250ms", want: 250 * time.Millisecond},
+		{value: "0", want: 0},
+		{value: "90", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := newAccessorVault(t, tt.value).GetDuration(context.Background(), "k")
+			if tt.wantErr {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), strconv.Quote(tt.value))
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGetInt_missingKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := vault.New().GetInt(context.Background(), "nope")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestGetOr(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	t.Run("hit", func(t *testing.T) {
+		t.Parallel()
+
+		got, err := newAccessorVault(t, "stored").GetOr(ctx, "k", "fallback")
+		require.NoError(t, err)
+		assert.Equal(t, "stored", got.Value)
+	})
+
+	t.Run("default after refresh", func(t *testing.T) {
+		t.Parallel()
+
+		calls := 0
+		src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+			calls++
+			return []vault.Entry{{Key: "other", Value: "v"}}, nil
+		})
+
+		got, err := vault.New(vault.WithSource(src)).GetOr(ctx, "k", "fallback")
+		require.NoError(t, err)
+		assert.Equal(t, vault.Entry{Key: "k", Value: "fallback", Source: "default"}, got)
+		assert.Equal(t, 1, calls, "the source should be consulted before falling back")
+	})
+
+	t.Run("refreshed value beats default", func(t *testing.T) {
+		t.Parallel()
+
+		src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+			return []vault.Entry{{Key: "k", Value: "fetched"}}, nil
+		})
+
+		got, err := vault.New(vault.WithSource(src)).GetOr(ctx, "k", "fallback")
+		require.NoError(t, err)
+		assert.Equal(t, "fetched", got.Value)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		t.Parallel()
+
+		errBroken := errors.New("broken")
+		v := vault.New(vault.WithStore(&failStore{err: errBroken}))
+
+		_, err := v.GetOr(ctx, "k", "fallback")
+		require.ErrorIs(t, err, errBroken)
+	})
+}
@@ -0,0 +1,109 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Start launches a goroutine that calls [Vault.Refresh] once every
+// interval set by [WithRefreshInterval], until ctx is cancelled or
+// [Vault.Stop] is called. Failed refreshes are passed to the handler set
+// by [WithRefreshErrorHandler], if any. Start does nothing if the loop is
+// already running, no interval is configured, or the vault is closed.
+func (v *vault) Start(ctx context.Context) {
+	if v.interval <= 0 {
+		return
+	}
+
+	v.loopMu.Lock()
+	defer v.loopMu.Unlock()
+
+	if v.closed.Load() {
+		return
+	}
+	if v.loopDone != nil {
+		select {
+		case <-v.loopDone: // exited after its context was cancelled
+		default:
+			return
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	v.loopStop, v.loopDone = cancel, done
+
+	go v.refreshLoop(ctx, done)
+}
+
+// Stop stops the loop launched by [Vault.Start] and waits for it to exit.
+// It is safe to call when the loop is not running.
+func (v *vault) Stop() {
+	v.loopMu.Lock()
+	defer v.loopMu.Unlock()
+
+	if v.loopDone == nil {
+		return
+	}
+	v.loopStop()
+	<-v.loopDone
+	v.loopStop, v.loopDone = nil, nil
+}
+
+// Close shuts the vault down. It stops the loop launched by [Vault.Start],
+// waiting for it to exit, and then closes the configured store if it
+// implements [io.Closer], unless the vault is a [Vault.Scoped] view, which
+// leaves the shared store open. The wrapping stores in this package, such
+// as [Encrypted] and [Chain], pass Close on to the stores they wrap. Afterwards every operation returns
+// [ErrClosed].
+// Operations already in flight are not interrupted, though they may fail
+// once the store is closed. Close is idempotent; calls after the first
+// return nil.
+func (v *vault) Close() error {
+	if !v.closed.CompareAndSwap(false, true) {
+		return nil
+	}
+	v.Stop()
+	if v.unsubscribe != nil {
+		v.unsubscribe()
+	}
+
+	if v.parent != nil {
+		return nil
+	}
+	if err := closeStore(v.config.store); err != nil {
+		return fmt.Errorf("vault: close: %w", err)
+	}
+	return nil
+}
+
+// checkOpen returns [ErrClosed] once [Vault.Close] has been called on
+// the vault, or on the vault it is a [Vault.Scoped] view of.
+func (v *vault) checkOpen() error {
+	if v.closed.Load() {
+		return ErrClosed
+	}
+	if v.parent != nil {
+		return v.parent.checkOpen()
+	}
+	return nil
+}
+
+func (v *vault) refreshLoop(ctx context.Context, done chan<- struct{}) {
+	defer close(done)
+
+	ticker := time.NewTicker(v.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := v.Refresh(ctx); err != nil && ctx.Err() == nil && v.onRefreshError != nil {
+				v.onRefreshError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,263 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func countingSource(calls *atomic.Int32, err error) vault.Source {
+	return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		return []vault.Entry{{Key: "k", Value: "v"}}, err
+	})
+}
+
+func TestStart_refreshesRepeatedly(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	v := vault.New(
+		vault.WithSource(countingSource(&calls, nil)),
+		vault.WithRefreshInterval(5*time.Millisecond),
+	)
+
+	v.Start(context.Background())
+	defer v.Stop()
+
+	require.Eventually(t, func() bool { return calls.Load() >= 3 }, time.Second, time.Millisecond)
+
+	got, err := v.Get(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+}
+
+func TestStart_idempotentAndStopWaits(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	v := vault.New(
+		vault.WithSource(countingSource(&calls, nil)),
+		vault.WithRefreshInterval(2*time.Millisecond),
+	)
+
+	v.Start(context.Background())
+	v.Start(context.Background())
+	require.Eventually(t, func() bool { return calls.Load() >= 2 }, time.Second, time.Millisecond)
+
+	v.Stop()
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load(), "no refresh may run after Stop returns")
+
+	v.Stop() // safe to call again
+}
+
+func TestStart_contextCancelStopsLoop(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	v := vault.New(
+		vault.WithSource(countingSource(&calls, nil)),
+		vault.WithRefreshInterval(2*time.Millisecond),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	v.Start(ctx)
+	require.Eventually(t, func() bool { return calls.Load() >= 1 }, time.Second, time.Millisecond)
+	cancel()
+	v.Stop()
+
+	// The loop can be started again after its context was cancelled.
+	before := calls.Load()
+	v.Start(context.Background())
+	defer v.Stop()
+	require.Eventually(t, func() bool { return calls.Load() > before }, time.Second, time.Millisecond)
+}
+
+func TestStart_errorHandler(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("upstream down")
+	errs := make(chan error, 10)
+
+	var calls atomic.Int32
+	v := vault.New(
+		vault.WithSource(countingSource(&calls, errFetch)),
+		vault.WithRefreshInterval(2*time.Millisecond),
+		vault.WithRefreshErrorHandler(func(err error) {
+			select {
+			case errs <- err:
+			default:
+			}
+		}),
+	)
+
+	v.Start(context.Background())
+	defer v.Stop()
+
+	select {
+	case err := <-errs:
+		require.ErrorIs(t, err, errFetch)
+	case <-time.After(time.Second):
+		t.Fatal("error handler was not called")
+	}
+}
+
+func TestStart_withoutInterval(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	v := vault.New(vault.WithSource(countingSource(&calls, nil)))
+
+	v.Start(context.Background())
+	time.Sleep(10 * time.Millisecond)
+	v.Stop()
+
+	assert.Zero(t, calls.Load())
+}
+
+type closingStore struct {
+	*vault.Memory
+	closes atomic.Int32
+	err    error
+}
+
+func (s *closingStore) Close() error {
+	s.closes.Add(1)
+	return s.err
+}
+
+func TestClose_stopsLoopAndClosesStore(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	store := &closingStore{Memory: vault.NewMemory()}
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithSource(countingSource(&calls, nil)),
+		vault.WithRefreshInterval(2*time.Millisecond),
+	)
+
+	v.Start(context.Background())
+	require.Eventually(t, func() bool { return calls.Load() >= 1 }, time.Second, time.Millisecond)
+
+	require.NoError(t, v.Close())
+	stopped := calls.Load()
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load(), "no refresh may run after Close returns")
+	assert.Equal(t, int32(1), store.closes.Load())
+
+	require.NoError(t, v.Close(), "Close is idempotent")
+	assert.Equal(t, int32(1), store.closes.Load())
+
+	v.Start(context.Background())
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, stopped, calls.Load(), "Start does nothing once closed")
+}
+
+func TestClose_operationsFail(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, v.Close())
+
+	_, err := v.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrClosed)
+	require.ErrorIs(t, v.Set(ctx, vault.Entry{Key: "k"}), vault.ErrClosed)
+	require.ErrorIs(t, v.Delete(ctx, "k"), vault.ErrClosed)
+	require.ErrorIs(t, v.Refresh(ctx), vault.ErrClosed)
+	_, err = v.List(ctx)
+	require.ErrorIs(t, err, vault.ErrClosed)
+	_, err = v.Keys(ctx)
+	require.ErrorIs(t, err, vault.ErrClosed)
+	_, err = v.GetMany(ctx, []string{"k"})
+	require.ErrorIs(t, err, vault.ErrClosed)
+	_, err = v.Watch(ctx)
+	require.ErrorIs(t, err, vault.ErrClosed)
+}
+
+func TestClose_storeError(t *testing.T) {
+	t.Parallel()
+
+	errClose := errors.New("flush failed")
+	v := vault.New(vault.WithStore(&closingStore{Memory: vault.NewMemory(), err: errClose}))
+
+	require.ErrorIs(t, v.Close(), errClose)
+}
+
+func TestClose_wrappedStores(t *testing.T) {
+	t.Parallel()
+
+	key := make([]byte, 32)
+	errClose := errors.New("flush failed")
+
+	tests := []struct {
+		name string
+		wrap func(t *testing.T, a, b vault.Store) vault.Store
+		both bool
+	}{
+		{name: "encrypted", wrap: func(t *testing.T, a, _ vault.Store) vault.Store {
+			t.Helper()
+			s, err := vault.Encrypted(a, key)
+			require.NoError(t, err)
+			return s
+		}},
+		{name: "signed", wrap: func(_ *testing.T, a, _ vault.Store) vault.Store { return vault.Signed(a, key) }},
+		{name: "read-only", wrap: func(_ *testing.T, a, _ vault.Store) vault.Store { return vault.ReadOnly(a) }},
+		{name: "chain", both: true, wrap: func(_ *testing.T, a, b vault.Store) vault.Store { return vault.Chain(a, b) }},
+		{name: "overlay", both: true, wrap: func(_ *testing.T, a, b vault.Store) vault.Store { return vault.Overlay(a, b) }},
+		{name: "sharded", both: true, wrap: func(_ *testing.T, a, b vault.Store) vault.Store {
+			return vault.NewShardedStore([]vault.Store{a, b}, nil)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			a := &closingStore{Memory: vault.NewMemory(), err: errClose}
+			b := &closingStore{Memory: vault.NewMemory()}
+			v := vault.New(vault.WithStore(tt.wrap(t, a, b)))
+
+			require.ErrorIs(t, v.Close(), errClose)
+			assert.Equal(t, int32(1), a.closes.Load())
+			if tt.both {
+				assert.Equal(t, int32(1), b.closes.Load(), "every wrapped store is closed")
+			}
+		})
+	}
+}
+
+func TestClose_flushesThroughEncrypted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.json")
+	key := make([]byte, 32)
+
+	m, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+	store, err := vault.Encrypted(m, key)
+	require.NoError(t, err)
+	v := vault.New(vault.WithStore(store))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, v.Close())
+
+	reopened, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+	store, err = vault.Encrypted(reopened, key)
+	require.NoError(t, err)
+	e, err := store.Get(ctx, "k")
+	require.NoError(t, err, "closing the vault flushes the persisted store")
+	assert.Equal(t, "v", e.Value)
+}
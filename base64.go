@@ -0,0 +1,122 @@
+package vault
+
+import (
+	"context"
+	"encoding/base64"
+	"strings"
+)
+
+// base64Prefix tags encoded values so that values written before
+// [WithBase64Values] was enabled are still read back unchanged.
+const base64Prefix = "b64:"
+
+// base64Store encodes [Entry.Value] on the way into the wrapped store and
+// decodes it on the way out. The vault wraps its effective store exactly
+// once, so values are never encoded twice.
+type base64Store struct {
+	Store
+}
+
+func (s base64Store) Get(ctx context.Context, key string) (Entry, error) {
+	e, err := s.Store.Get(ctx, key)
+	if err != nil {
+		return Entry{}, err
+	}
+	return decodeValue(e), nil
+}
+
+func (s base64Store) GetMany(ctx context.Context, keys []string) (map[string]Entry, error) {
+	found, err := getMany(ctx, s.Store, keys)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range found {
+		found[k] = decodeValue(e)
+	}
+	return found, nil
+}
+
+func (s base64Store) Exists(ctx context.Context, key string) (bool, error) {
+	return exists(ctx, s.Store, key)
+}
+
+func (s base64Store) Keys(ctx context.Context) ([]string, error) {
+	return listKeys(ctx, s.Store)
+}
+
+func (s base64Store) Snapshot(ctx context.Context) (map[string]Entry, error) {
+	snap, err := snapshot(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range snap {
+		snap[k] = decodeValue(e)
+	}
+	return snap, nil
+}
+
+func (s base64Store) ListByTag(ctx context.Context, key, value string) ([]Entry, error) {
+	entries, err := listByTag(ctx, s.Store, key, value)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i] = decodeValue(entries[i])
+	}
+	return entries, nil
+}
+
+func (s base64Store) ListPrefix(ctx context.Context, prefix string) ([]Entry, error) {
+	entries, err := listPrefix(ctx, s.Store, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i] = decodeValue(entries[i])
+	}
+	return entries, nil
+}
+
+func (s base64Store) KeysPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return keysPrefix(ctx, s.Store, prefix)
+}
+
+func (s base64Store) Set(ctx context.Context, entry Entry) error {
+	entry.Value = base64Prefix + base64.StdEncoding.EncodeToString([]byte(entry.Value))
+	return s.Store.Set(ctx, entry)
+}
+
+func (s base64Store) SetMany(ctx context.Context, entries []Entry) error {
+	encoded := make([]Entry, len(entries))
+	for i, e := range entries {
+		e.Value = base64Prefix + base64.StdEncoding.EncodeToString([]byte(e.Value))
+		encoded[i] = e
+	}
+	return setMany(ctx, s.Store, encoded)
+}
+
+func (s base64Store) DeleteMany(ctx context.Context, keys []string) error {
+	return deleteMany(ctx, s.Store, keys)
+}
+
+func (s base64Store) List(ctx context.Context) ([]Entry, error) {
+	entries, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i] = decodeValue(entries[i])
+	}
+	return entries, nil
+}
+
+func decodeValue(e Entry) Entry {
+	encoded, ok := strings.CutPrefix(e.Value, base64Prefix)
+	if !ok {
+		return e
+	}
+	if raw, err := base64.StdEncoding.DecodeString(encoded); err == nil {
+		e.Value = string(raw)
+	}
+	return e
+}
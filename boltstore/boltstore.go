@@ -0,0 +1,247 @@
+// Package boltstore implements a [vault.Store] backed by a bbolt
+// database file, for single-binary applications that want entries to
+// survive restarts without running a separate database.
+//
+// Each namespace is a top-level bucket named after it, and entries are
+// stored in it as JSON keyed by entry key. The store without a namespace
+// uses the bucket "/", which no valid namespace can name.
+package boltstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"iter"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"github.com/bjaus/vault"
+)
+
+// rootBucket holds the entries of the store without a namespace.
+const rootBucket = "/"
+
+// pageSize is how many entries [Store.ListStream] reads per transaction.
+const pageSize = 100
+
+// openTimeout bounds how long Open waits for another process to release
+// its lock on the file.
+const openTimeout = time.Second
+
+// Store is a [vault.Store] persisted to a bbolt database. It is safe for
+// concurrent use and implements [vault.Namespaced]; namespaced stores
+// share the database of the store they came from.
+type Store struct {
+	db     *bolt.DB
+	bucket []byte
+}
+
+// Open opens the database at path, creating it if it does not exist.
+// bbolt allows one process at a time to open a file, so Open fails if
+// another process still holds it after a second.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: open %s: %w", path, err)
+	}
+	return &Store{db: db, bucket: []byte(rootBucket)}, nil
+}
+
+// Close closes the database. Every store sharing it, namespaced or not,
+// becomes unusable.
+func (s *Store) Close() error {
+	if err := s.db.Close(); err != nil {
+		return fmt.Errorf("boltstore: close: %w", err)
+	}
+	return nil
+}
+
+// WithNamespace returns a [vault.Store] scoped to ns, backed by the
+// bucket named ns. It panics if ns is not valid according to
+// [vault.ValidateNamespace].
+func (s *Store) WithNamespace(ns string) vault.Store {
+	if err := vault.ValidateNamespace(ns); err != nil {
+		panic(err)
+	}
+	return &Store{db: s.db, bucket: []byte(ns)}
+}
+
+// Get retrieves an entry by key.
+func (s *Store) Get(ctx context.Context, key string) (vault.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return vault.Entry{}, err
+	}
+
+	var data []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		if b := tx.Bucket(s.bucket); b != nil {
+			// Values are only valid during the transaction.
+			data = bytes.Clone(b.Get([]byte(key)))
+		}
+		return nil
+	})
+	if err != nil {
+		return vault.Entry{}, fmt.Errorf("boltstore: get %q: %w", key, err)
+	}
+	if data == nil {
+		return vault.Entry{}, vault.ErrNotFound
+	}
+	e, err := decode(data)
+	if err != nil {
+		return vault.Entry{}, fmt.Errorf("boltstore: get %q: %w", key, err)
+	}
+	return e, nil
+}
+
+// Set stores an entry, creating the namespace bucket if needed.
+func (s *Store) Set(ctx context.Context, entry vault.Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("boltstore: marshal %q: %w", entry.Key, err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists(s.bucket)
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(entry.Key), data)
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: set %q: %w", entry.Key, err)
+	}
+	return nil
+}
+
+// Delete removes an entry. Deleting a missing key is not an error.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+	if err != nil {
+		return fmt.Errorf("boltstore: delete %q: %w", key, err)
+	}
+	return nil
+}
+
+// List returns every entry in the namespace, sorted by key, read in a
+// single transaction. [Store.ListStream] reads large namespaces a page
+// at a time instead.
+func (s *Store) List(ctx context.Context) ([]vault.Entry, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	var entries []vault.Entry
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, data []byte) error {
+			e, err := decode(data)
+			if err != nil {
+				return fmt.Errorf("%q: %w", k, err)
+			}
+			entries = append(entries, e)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("boltstore: list: %w", err)
+	}
+	return entries, nil
+}
+
+// ListStream yields the entries in the store's bucket in key order. They
+// are read a page of 100 at a time, each page in its own read
+// transaction, so no transaction is open while yielding and the loop body
+// may write to the store. Entries written during iteration ahead of the
+// current position are seen. Iteration stops with ctx's error if ctx is
+// done.
+func (s *Store) ListStream(ctx context.Context) (iter.Seq2[vault.Entry, error], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return func(yield func(vault.Entry, error) bool) {
+		var after []byte
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(vault.Entry{}, err)
+				return
+			}
+
+			page, last, err := s.page(after)
+			if err != nil {
+				yield(vault.Entry{}, fmt.Errorf("boltstore: list: %w", err))
+				return
+			}
+			for _, e := range page {
+				if !yield(e, nil) {
+					return
+				}
+			}
+			if len(page) < pageSize {
+				return
+			}
+			after = last
+		}
+	}, nil
+}
+
+// page reads up to pageSize entries whose keys sort after the key after,
+// or from the start when after is nil, and returns the last key read.
+func (s *Store) page(after []byte) ([]vault.Entry, []byte, error) {
+	var (
+		entries []vault.Entry
+		last    []byte
+	)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(s.bucket)
+		if b == nil {
+			return nil
+		}
+
+		c := b.Cursor()
+		k, data := c.First()
+		if after != nil {
+			k, data = c.Seek(after)
+			if bytes.Equal(k, after) {
+				k, data = c.Next()
+			}
+		}
+		for ; k != nil && len(entries) < pageSize; k, data = c.Next() {
+			e, err := decode(data)
+			if err != nil {
+				return fmt.Errorf("%q: %w", k, err)
+			}
+			entries = append(entries, e)
+			last = bytes.Clone(k)
+		}
+		return nil
+	})
+	return entries, last, err
+}
+
+func decode(data []byte) (vault.Entry, error) {
+	var e vault.Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return vault.Entry{}, fmt.Errorf("unmarshal: %w", err)
+	}
+	return e, nil
+}
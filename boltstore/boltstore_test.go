@@ -0,0 +1,179 @@
+package boltstore_test
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/boltstore"
+)
+
+func open(t *testing.T, path string) *boltstore.Store {
+	t.Helper()
+
+	s, err := boltstore.Open(path)
+	require.NoError(t, err)
+	return s
+}
+
+func TestStore_CRUD(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := open(t, filepath.Join(t.TempDir(), "vault.db"))
+	t.Cleanup(func() { _ = s.Close() }) //nolint:errcheck // test cleanup
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1", Tags: map[string]string{"env": "prod"}}))
+
+	e, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", e.Value)
+	assert.Equal(t, "prod", e.Tags["env"])
+
+	entries, err = s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Key)
+	assert.Equal(t, "b", entries[1].Key)
+
+	require.NoError(t, s.Delete(ctx, "a"))
+	require.NoError(t, s.Delete(ctx, "missing"))
+	_, err = s.Get(ctx, "a")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_namespaceIsolation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := open(t, filepath.Join(t.TempDir(), "vault.db"))
+	t.Cleanup(func() { _ = s.Close() }) //nolint:errcheck // test cleanup
+
+	prod := s.WithNamespace("prod")
+	dev := s.WithNamespace("dev")
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "root"}))
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "k", Value: "prod"}))
+
+	e, err := prod.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "prod", e.Value)
+
+	e, err = s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "root", e.Value)
+
+	_, err = dev.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	entries, err := prod.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	require.NoError(t, prod.Delete(ctx, "k"))
+	_, err = s.Get(ctx, "k")
+	require.NoError(t, err)
+
+	assert.Panics(t, func() { s.WithNamespace("/bad") })
+}
+
+func TestStore_persistsAcrossRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.db")
+
+	s := open(t, path)
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v", Source: "manual"}))
+	require.NoError(t, s.WithNamespace("prod").Set(ctx, vault.Entry{Key: "k", Value: "p"}))
+	require.NoError(t, s.Close())
+
+	s = open(t, path)
+	t.Cleanup(func() { _ = s.Close() }) //nolint:errcheck // test cleanup
+
+	e, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+	assert.Equal(t, "manual", e.Source)
+
+	e, err = s.WithNamespace("prod").Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "p", e.Value)
+
+	v := vault.New(vault.WithStore(s), vault.WithNamespace("prod"))
+	e, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "p", e.Value)
+}
+
+func TestStore_cancelledContext(t *testing.T) {
+	t.Parallel()
+
+	s := open(t, filepath.Join(t.TempDir(), "vault.db"))
+	t.Cleanup(func() { _ = s.Close() }) //nolint:errcheck // test cleanup
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, s.Set(ctx, vault.Entry{Key: "k"}), context.Canceled)
+	require.ErrorIs(t, s.Delete(ctx, "k"), context.Canceled)
+	_, err = s.List(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestStore_ListStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := open(t, filepath.Join(t.TempDir(), "vault.db"))
+	t.Cleanup(func() { _ = s.Close() }) //nolint:errcheck // test cleanup
+
+	const n = 250 // more than one page
+	for i := range n {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: fmt.Sprintf("k%03d", i), Value: "v"}))
+	}
+
+	seq, err := s.ListStream(ctx)
+	require.NoError(t, err)
+	var keys []string
+	for e, err := range seq {
+		require.NoError(t, err)
+		keys = append(keys, e.Key)
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: e.Key, Value: "updated"}), "the loop body may write")
+	}
+	require.Len(t, keys, n)
+	assert.Equal(t, "k000", keys[0])
+	assert.Equal(t, "k249", keys[n-1])
+
+	seq, err = s.ListStream(ctx)
+	require.NoError(t, err)
+	var seen int
+	for e, err := range seq {
+		require.NoError(t, err)
+		assert.Equal(t, "updated", e.Value)
+		if seen++; seen == 3 {
+			break
+		}
+	}
+	assert.Equal(t, 3, seen)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = s.ListStream(cctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
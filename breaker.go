@@ -0,0 +1,67 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, wrapped in a [*SourceError], for a source
+// skipped because its [WithCircuitBreaker] breaker is open.
+var ErrCircuitOpen = errors.New("vault: circuit open")
+
+// circuitBreakers tracks consecutive fetch failures per source index for
+// [WithCircuitBreaker].
+type circuitBreakers struct {
+	mu       sync.Mutex
+	failures map[int]int
+	until    map[int]time.Time
+}
+
+// circuitOpen returns an error wrapping [ErrCircuitOpen] if the i-th
+// source's breaker is open. Once the cooldown has passed, the source may
+// be fetched again; a further failure reopens the breaker straight away.
+func (v *vault) circuitOpen(i int) error {
+	if v.breakerThreshold <= 0 {
+		return nil
+	}
+
+	v.breakers.mu.Lock()
+	defer v.breakers.mu.Unlock()
+
+	until, ok := v.breakers.until[i]
+	if !ok || !v.now().Before(until) {
+		return nil
+	}
+	return fmt.Errorf("%w until %s", ErrCircuitOpen, until.Format(time.RFC3339))
+}
+
+// recordFetch counts a fetch of the i-th source towards its breaker. A
+// success resets the count, and reaching the threshold opens the breaker
+// for the cooldown. Fetches cut short by ctx are not counted, since they
+// say nothing about the source.
+func (v *vault) recordFetch(ctx context.Context, i int, err error) {
+	if v.breakerThreshold <= 0 || (err != nil && ctx.Err() != nil) {
+		return
+	}
+
+	v.breakers.mu.Lock()
+	defer v.breakers.mu.Unlock()
+
+	if err == nil {
+		delete(v.breakers.failures, i)
+		delete(v.breakers.until, i)
+		return
+	}
+
+	if v.breakers.failures == nil {
+		v.breakers.failures = make(map[int]int)
+		v.breakers.until = make(map[int]time.Time)
+	}
+	v.breakers.failures[i]++
+	if v.breakers.failures[i] >= v.breakerThreshold {
+		v.breakers.until[i] = v.now().Add(v.breakerCooldown)
+	}
+}
@@ -0,0 +1,75 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/clocktest"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	errDown := errors.New("upstream down")
+
+	var (
+		calls   atomic.Int32
+		healthy atomic.Bool
+	)
+	flaky := vault.NamedSource("flaky", vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		if !healthy.Load() {
+			return nil, errDown
+		}
+		return []vault.Entry{{Key: "flaky", Value: "v"}}, nil
+	}))
+	steady := vault.NamedSource("steady", vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "steady", Value: "v"}}, nil
+	}))
+	v := vault.New(
+		vault.WithSource(flaky),
+		vault.WithSource(steady),
+		vault.WithClock(clock),
+		vault.WithCircuitBreaker(2, time.Minute),
+	)
+
+	for range 2 {
+		require.ErrorIs(t, v.Refresh(ctx), errDown)
+	}
+	require.Equal(t, int32(2), calls.Load())
+
+	err := v.Refresh(ctx)
+	require.ErrorIs(t, err, vault.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load(), "an open breaker skips the source")
+
+	result, err := v.RefreshReport(ctx)
+	require.NoError(t, err)
+	require.Len(t, result.Failed(), 1)
+	assert.Equal(t, "flaky", result.Failed()[0].Name)
+	require.ErrorIs(t, result.Failed()[0].Err, vault.ErrCircuitOpen)
+	assert.Equal(t, int32(2), calls.Load())
+
+	clock.Advance(time.Minute)
+	require.ErrorIs(t, v.Refresh(ctx), errDown)
+	assert.Equal(t, int32(3), calls.Load(), "the source is retried after the cooldown")
+	require.ErrorIs(t, v.Refresh(ctx), vault.ErrCircuitOpen, "a failed retry reopens the breaker")
+
+	clock.Advance(time.Minute)
+	healthy.Store(true)
+	require.NoError(t, v.Refresh(ctx))
+	assert.Equal(t, int32(4), calls.Load())
+
+	healthy.Store(false)
+	require.ErrorIs(t, v.Refresh(ctx), errDown)
+	require.ErrorIs(t, v.Refresh(ctx), errDown, "a success resets the failure count")
+	assert.Equal(t, int32(6), calls.Load())
+}
@@ -0,0 +1,162 @@
+package vault
+
+import (
+	"context"
+	"crypto/rand"
+	"sync"
+)
+
+// Invalidation tells the vaults subscribed to a [Broker] that keys
+// changed in another vault and that their own copies are out of date.
+type Invalidation struct {
+	// Origin identifies the vault that published the invalidation, so
+	// that it can ignore its own.
+	Origin string `json:"origin"`
+
+	// Namespace is the namespace the keys were written to: the one set by
+	// [WithNamespaceContext] or [WithSourceNamespace], or else the
+	// publishing vault's own, which is empty without [WithNamespace].
+	Namespace string `json:"namespace,omitempty"`
+
+	Keys []string `json:"keys"`
+}
+
+// Broker carries [Invalidation] messages between vaults, for vaults that
+// cache the same configuration in separate stores. See [WithBroker].
+//
+// [MemoryBroker] connects vaults within one process. To connect processes,
+// back a Broker with a message bus such as Redis pub/sub: Publish encodes
+// the invalidation, for example as JSON, and publishes it to a channel,
+// and Subscribe starts a goroutine that receives from the channel, decodes
+// each message and calls fn, stopping when unsubscribe is called.
+type Broker interface {
+	// Publish delivers inv to every subscriber, including the publisher's
+	// own subscription.
+	Publish(ctx context.Context, inv Invalidation) error
+
+	// Subscribe registers fn to receive every invalidation published from
+	// now on, until unsubscribe is called. fn must be safe to call
+	// concurrently.
+	Subscribe(fn func(Invalidation)) (unsubscribe func())
+}
+
+// MemoryBroker is a [Broker] for vaults in the same process. Publish calls
+// each subscriber in turn before returning.
+type MemoryBroker struct {
+	mu   sync.Mutex
+	next int
+	subs map[int]func(Invalidation)
+}
+
+// NewMemoryBroker creates a [MemoryBroker] with no subscribers.
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{subs: make(map[int]func(Invalidation))}
+}
+
+// Publish calls every subscriber with inv. It fails only if ctx is done.
+func (b *MemoryBroker) Publish(ctx context.Context, inv Invalidation) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	b.mu.Lock()
+	subs := make([]func(Invalidation), 0, len(b.subs))
+	for _, fn := range b.subs {
+		subs = append(subs, fn)
+	}
+	b.mu.Unlock()
+
+	for _, fn := range subs {
+		fn(inv)
+	}
+	return nil
+}
+
+// Subscribe registers fn until the returned function is called.
+func (b *MemoryBroker) Subscribe(fn func(Invalidation)) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	id := b.next
+	b.next++
+	b.subs[id] = fn
+	return func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+	}
+}
+
+// newVaultID returns a random identifier for [Invalidation.Origin].
+func newVaultID() string {
+	return rand.Text()
+}
+
+// invalidate publishes that keys in namespace ns, or the vault's own
+// namespace when ns is empty, were written. Failures are logged rather
+// than returned, since the write itself succeeded.
+func (v *vault) invalidate(ctx context.Context, ns string, keys ...string) {
+	if v.broker == nil || len(keys) == 0 {
+		return
+	}
+	if ns == "" {
+		ns = v.namespace
+	}
+
+	inv := Invalidation{Origin: v.id, Namespace: ns, Keys: keys}
+	if err := v.broker.Publish(context.WithoutCancel(ctx), inv); err != nil {
+		v.logger.Warn("vault: publish invalidation failed", "keys", len(keys), "error", err)
+	}
+}
+
+// invalidateScoped is [vault.invalidate] for keys written through a store
+// returned by [vault.scoped].
+func (v *vault) invalidateScoped(ctx context.Context, keys ...string) {
+	ns, _ := ctx.Value(namespaceKey{}).(string) //nolint:errcheck // absent means the vault's own namespace
+	v.invalidate(ctx, ns, keys...)
+}
+
+// receive drops the keys of an invalidation published by another
+// vault from the store, so that the next read misses and refreshes from
+// the sources even within the TTL window. Only vaults with sources
+// subscribe. The views of the
+// invalidated namespace are marked and notified in the same way.
+func (v *vault) receive(inv Invalidation) {
+	if inv.Origin == v.id || v.closed.Load() {
+		return
+	}
+
+	store := v.cache
+	if n, ok := v.config.store.(Namespaced); ok && inv.Namespace != v.namespace {
+		switch {
+		case inv.Namespace == "":
+			store = v.decorate(v.config.store)
+		case ValidateNamespace(inv.Namespace) != nil:
+			return
+		default:
+			store = v.decorate(n.WithNamespace(inv.Namespace))
+		}
+	}
+
+	if err := deleteMany(context.Background(), store, inv.Keys); err != nil {
+		v.logger.Error("vault: apply invalidation failed", "keys", len(inv.Keys), "error", err)
+		return
+	}
+
+	v.dropped(inv.Keys)
+	for _, view := range v.viewsOf(inv.Namespace) {
+		view.dropped(inv.Keys)
+	}
+}
+
+// dropped records that keys were removed by an invalidation, so that the
+// next miss refreshes, and tells the vault's watchers.
+func (v *vault) dropped(keys []string) {
+	v.mu.Lock()
+	v.invalidated = true
+	v.mu.Unlock()
+
+	for _, key := range keys {
+		v.watch.publish(Event{Key: key, Op: OpDelete})
+	}
+}
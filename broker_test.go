@@ -0,0 +1,124 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+var noEntries = vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+	return nil, nil
+})
+
+func TestBroker_setInvalidatesOtherVaults(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithBroker(broker))
+	bStore := vault.NewMemory()
+	b := vault.New(vault.WithStore(bStore), vault.WithSource(noEntries), vault.WithBroker(broker))
+
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "k", Value: "old"}))
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "other", Value: "kept"}))
+
+	require.NoError(t, a.Set(ctx, vault.Entry{Key: "k", Value: "new"}))
+
+	_, err := b.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound, "the stale copy is dropped")
+	_, err = b.Get(ctx, "other")
+	require.NoError(t, err)
+
+	e, err := a.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "new", e.Value, "a vault ignores its own invalidations")
+}
+
+func TestBroker_invalidationReloadsFromSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var value atomic.Pointer[string]
+	set := func(s string) { value.Store(&s) }
+	set("v1")
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: *value.Load()}}, nil
+	})
+
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithSource(src), vault.WithBroker(broker))
+	b := vault.New(vault.WithSource(src), vault.WithBroker(broker))
+	require.NoError(t, a.Refresh(ctx))
+	require.NoError(t, b.Refresh(ctx))
+
+	set("v2")
+	require.NoError(t, a.Refresh(ctx))
+
+	e, err := b.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", e.Value)
+
+	e, err = a.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", e.Value, "b's reload does not invalidate a in turn")
+}
+
+func TestBroker_namespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithNamespace("prod"), vault.WithBroker(broker))
+	bStore := vault.NewMemory()
+	b := vault.New(vault.WithStore(bStore), vault.WithSource(noEntries), vault.WithNamespace("qa"), vault.WithBroker(broker))
+
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "k", Value: "qa"}))
+	require.NoError(t, bStore.WithNamespace("prod").Set(ctx, vault.Entry{Key: "k", Value: "prod"}))
+
+	require.NoError(t, a.Delete(ctx, "k"))
+
+	_, err := b.Get(ctx, "k")
+	require.NoError(t, err, "other namespaces are untouched")
+	_, err = bStore.WithNamespace("prod").Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestBroker_closeUnsubscribes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithBroker(broker))
+	bStore := vault.NewMemory()
+	b := vault.New(vault.WithStore(bStore), vault.WithSource(noEntries), vault.WithBroker(broker))
+
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, b.Close())
+	require.NoError(t, a.Set(ctx, vault.Entry{Key: "k", Value: "new"}))
+
+	e, err := bStore.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+}
+
+func TestBroker_noSourcesOnlyPublishes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithBroker(broker))
+	bStore := vault.NewMemory()
+	require.NoError(t, bStore.Set(ctx, vault.Entry{Key: "k", Value: "only copy"}))
+	b := vault.New(vault.WithStore(bStore), vault.WithReadOnly(), vault.WithBroker(broker))
+
+	require.NoError(t, a.Set(ctx, vault.Entry{Key: "k", Value: "new"}))
+
+	e, err := b.Get(ctx, "k")
+	require.NoError(t, err, "a vault without sources keeps its entries")
+	assert.Equal(t, "only copy", e.Value)
+}
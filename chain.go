@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// Chain layers stores into a read-through hierarchy, fastest first, for
+// example memory, then keychain, then a remote store.
+//
+// Get tries each store in order and returns the first hit, copying the
+// entry into the earlier stores that missed it; failures while copying
+// are ignored, since the read itself succeeded. Any error other than
+// [ErrNotFound] is returned immediately. Set and Delete are applied to
+// every store, and the errors of all that fail are joined. List merges
+// the stores, keeping the entry from the earliest store for each key.
+// Close closes every store that implements [io.Closer] and joins their
+// errors.
+//
+// If every store implements [Namespaced], so does the returned store.
+func Chain(stores ...Store) Store {
+	c := chainStore{stores: stores}
+	ns := make([]Namespaced, len(stores))
+	for i, s := range stores {
+		n, ok := s.(Namespaced)
+		if !ok {
+			return c
+		}
+		ns[i] = n
+	}
+	return namespacedChainStore{chainStore: c, ns: ns}
+}
+
+type chainStore struct {
+	stores []Store
+}
+
+type namespacedChainStore struct {
+	chainStore
+	ns []Namespaced
+}
+
+func (c namespacedChainStore) WithNamespace(namespace string) Store {
+	scoped := make([]Store, len(c.ns))
+	for i, n := range c.ns {
+		scoped[i] = n.WithNamespace(namespace)
+	}
+	return Chain(scoped...)
+}
+
+func (c chainStore) Get(ctx context.Context, key string) (Entry, error) {
+	for i, s := range c.stores {
+		e, err := s.Get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return Entry{}, fmt.Errorf("vault: chain: store %d: %w", i, err)
+		}
+
+		for _, earlier := range c.stores[:i] {
+			_ = earlier.Set(ctx, e) //nolint:errcheck // best-effort read-through
+		}
+		return e, nil
+	}
+	return Entry{}, ErrNotFound
+}
+
+func (c chainStore) Set(ctx context.Context, entry Entry) error {
+	var errs []error
+	for i, s := range c.stores {
+		if err := s.Set(ctx, entry); err != nil {
+			errs = append(errs, fmt.Errorf("vault: chain: store %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c chainStore) Delete(ctx context.Context, key string) error {
+	var errs []error
+	for i, s := range c.stores {
+		if err := s.Delete(ctx, key); err != nil {
+			errs = append(errs, fmt.Errorf("vault: chain: store %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c chainStore) Close() error {
+	var errs []error
+	for i, s := range c.stores {
+		if err := closeStore(s); err != nil {
+			errs = append(errs, fmt.Errorf("vault: chain: store %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (c chainStore) List(ctx context.Context) ([]Entry, error) {
+	results := make([][]Entry, len(c.stores))
+	for i, s := range c.stores {
+		entries, err := s.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("vault: chain: store %d: %w", i, err)
+		}
+		results[i] = entries
+	}
+	return mergeEntries(results, FirstWins), nil
+}
@@ -0,0 +1,107 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestChain_readThrough(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fast, mid, slow := vault.NewMemory(), vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, slow.Set(ctx, vault.Entry{Key: "k", Value: "from-slow"}))
+
+	chain := vault.Chain(fast, mid, slow)
+
+	got, err := chain.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "from-slow", got.Value)
+
+	for name, s := range map[string]vault.Store{"fast": fast, "mid": mid} {
+		e, err := s.Get(ctx, "k")
+		require.NoError(t, err, name)
+		assert.Equal(t, "from-slow", e.Value, name)
+	}
+
+	_, err = chain.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestChain_writeFanOut(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a, b := vault.NewMemory(), vault.NewMemory()
+	chain := vault.Chain(a, b)
+
+	require.NoError(t, chain.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	for _, s := range []vault.Store{a, b} {
+		_, err := s.Get(ctx, "k")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, chain.Delete(ctx, "k"))
+	for _, s := range []vault.Store{a, b} {
+		_, err := s.Get(ctx, "k")
+		require.ErrorIs(t, err, vault.ErrNotFound)
+	}
+}
+
+func TestChain_listPrefersEarlierStores(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a, b := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, a.Set(ctx, vault.Entry{Key: "shared", Value: "from-a"}))
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "shared", Value: "from-b"}))
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "only-b", Value: "b"}))
+
+	entries, err := vault.Chain(a, b).List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	byKey := make(map[string]string)
+	for _, e := range entries {
+		byKey[e.Key] = e.Value
+	}
+	assert.Equal(t, map[string]string{"shared": "from-a", "only-b": "b"}, byKey)
+}
+
+func TestChain_errorsSurfaceImmediately(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errBroken := errors.New("broken")
+	last := vault.NewMemory()
+	require.NoError(t, last.Set(ctx, vault.Entry{Key: "k"}))
+
+	chain := vault.Chain(vault.NewMemory(), &failStore{err: errBroken}, last)
+
+	_, err := chain.Get(ctx, "k")
+	require.ErrorIs(t, err, errBroken)
+}
+
+func TestChain_namespaced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	a, b := vault.NewMemory(), vault.NewMemory()
+	chain := vault.Chain(a, b)
+	require.Implements(t, (*vault.Namespaced)(nil), chain)
+	assert.NotImplements(t, (*vault.Namespaced)(nil), vault.Chain(a, &failStore{}))
+
+	v := vault.New(vault.WithStore(chain), vault.WithNamespace("prod"))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	_, err := b.WithNamespace("prod").Get(ctx, "k")
+	require.NoError(t, err)
+	_, err = b.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
@@ -0,0 +1,20 @@
+package vault
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// Checksum returns the hex-encoded SHA-256 hash of the entry's key and
+// value. The key is prefixed with its length, so that no two distinct
+// key and value pairs hash the same input. Other fields, such as
+// [Entry.CreatedAt], do not affect it, so it changes only when the
+// content does. It is computed on each call and suitable as an HTTP ETag.
+func (e Entry) Checksum() string {
+	h := sha256.New()
+	h.Write(binary.AppendUvarint(nil, uint64(len(e.Key))))
+	h.Write([]byte(e.Key))
+	h.Write([]byte(e.Value))
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,32 @@
+package vault_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjaus/vault"
+)
+
+func TestEntry_Checksum(t *testing.T) {
+	t.Parallel()
+
+	a := vault.Entry{Key: "k", Value: "v"}
+	b := vault.Entry{Key: "k", Value: "v", Source: "ssm", CreatedAt: time.Now()}
+	assert.Equal(t, a.Checksum(), b.Checksum(), "only the key and value count")
+	assert.Len(t, a.Checksum(), 64)
+
+	assert.NotEqual(t, a.Checksum(), vault.Entry{Key: "k", Value: "w"}.Checksum())
+	assert.NotEqual(t, a.Checksum(), vault.Entry{Key: "j", Value: "v"}.Checksum())
+	assert.NotEqual(t,
+		vault.Entry{Key: "ab", Value: "c"}.Checksum(),
+		vault.Entry{Key: "a", Value: "bc"}.Checksum(),
+		"the key and value are kept apart",
+	)
+	assert.NotEqual(t,
+		vault.Entry{Key: "a\x00b", Value: "c"}.Checksum(),
+		vault.Entry{Key: "a", Value: "b\x00c"}.Checksum(),
+		"even when the key contains a NUL byte",
+	)
+}
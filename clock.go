@@ -0,0 +1,23 @@
+package vault
+
+import "time"
+
+// Clock tells the vault the current time. The clock set with [WithClock]
+// decides entry timestamps, expiry and the auto-refresh window, so tests
+// can advance time instead of sleeping; the clocktest package provides a
+// manual implementation.
+type Clock interface {
+	Now() time.Time
+}
+
+// realClock is the default [Clock], backed by [time.Now].
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+// now returns the current time according to the configured [Clock].
+func (v *vault) now() time.Time { return v.clock.Now() }
+
+// since returns the time elapsed since t according to the configured
+// [Clock].
+func (v *vault) since(t time.Time) time.Duration { return v.clock.Now().Sub(t) }
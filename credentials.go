@@ -0,0 +1,46 @@
+package vault
+
+import "context"
+
+// Credentials are request-scoped secrets a [Source] authenticates with,
+// for sources whose caller identity varies per request. Attach them with
+// [WithCredentials]; sources read them with [CredentialsFromContext].
+// The sources in this module use the fields that apply to them and ignore
+// the rest.
+type Credentials struct {
+	// Token is a bearer token. httpsource sends it in the Authorization
+	// header, replacing one set with httpsource.WithHeader.
+	Token string
+
+	// AccessKeyID, SecretAccessKey and SessionToken are AWS credentials.
+	// ssmsource signs its requests with them instead of the client's own
+	// when AccessKeyID is set.
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// credentialsKey is the context key for [WithCredentials].
+type credentialsKey struct{}
+
+// WithCredentials returns a copy of ctx carrying creds for sources to
+// authenticate with. [Vault.Refresh] and the auto-refresh of [Vault.Get]
+// fetch with the caller's context, so the credentials reach every source.
+//
+// Credentials do not isolate callers from each other. Concurrent
+// auto-refreshes share a single fetch, made with the credentials of
+// whichever caller started it, and what any fetch returns is written to
+// the shared store, where every caller of the vault can read it,
+// whatever credentials they hold. To keep callers with different access
+// apart, give each its own [Vault.Scoped] view, which refreshes into its
+// own namespace, and check access before calling it.
+func WithCredentials(ctx context.Context, creds Credentials) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, creds)
+}
+
+// CredentialsFromContext returns the credentials attached to ctx by
+// [WithCredentials], and whether there were any.
+func CredentialsFromContext(ctx context.Context) (Credentials, bool) {
+	creds, ok := ctx.Value(credentialsKey{}).(Credentials)
+	return creds, ok
+}
@@ -0,0 +1,43 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestCredentialsFromContext(t *testing.T) {
+	t.Parallel()
+
+	_, ok := vault.CredentialsFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := vault.WithCredentials(context.Background(), vault.Credentials{Token: "t0ken"})
+	creds, ok := vault.CredentialsFromContext(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "t0ken", creds.Token)
+}
+
+func TestCredentials_reachSourceOnRefresh(t *testing.T) {
+	t.Parallel()
+
+	src := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		creds, _ := vault.CredentialsFromContext(ctx)
+		return []vault.Entry{{Key: "caller", Value: creds.Token}}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	ctx := vault.WithCredentials(context.Background(), vault.Credentials{Token: "alice"})
+	e, err := v.Get(ctx, "caller")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", e.Value)
+
+	require.NoError(t, v.Refresh(vault.WithCredentials(context.Background(), vault.Credentials{Token: "bob"})))
+	e, err = v.Get(context.Background(), "caller")
+	require.NoError(t, err)
+	assert.Equal(t, "bob", e.Value)
+}
@@ -0,0 +1,46 @@
+package vault
+
+import (
+	"fmt"
+	"time"
+)
+
+// VaultInfo describes how a vault was configured, as returned by
+// [Vault.Describe]. It is meant for debugging and logging.
+type VaultInfo struct {
+	// StoreType is the Go type of the store set with [WithStore], such as
+	// "*keychain.Store", before any wrapping the vault applies itself.
+	StoreType string
+
+	// Namespace is the namespace set with [WithNamespace], if any.
+	Namespace string
+
+	// NamespaceActive reports whether Namespace is in effect. It is false
+	// when no namespace is set, and also when one is set but the store
+	// does not implement [Namespaced], in which case the namespace is
+	// silently ignored.
+	NamespaceActive bool
+
+	// TTL is the global TTL set with [WithTTL]. Zero means entries never
+	// expire unless they set [Entry.ExpiresAt] or have a per-source TTL.
+	TTL time.Duration
+
+	// Sources is the number of configured sources.
+	Sources int
+
+	// ReadOnly reports whether the vault was created with [WithReadOnly].
+	ReadOnly bool
+}
+
+// Describe reports how the vault was configured.
+func (v *vault) Describe() VaultInfo {
+	_, namespaced := v.config.store.(Namespaced)
+	return VaultInfo{
+		StoreType:       fmt.Sprintf("%T", v.config.store),
+		Namespace:       v.namespace,
+		NamespaceActive: v.namespace != "" && namespaced,
+		TTL:             v.ttl,
+		Sources:         len(v.sources),
+		ReadOnly:        v.readOnly,
+	}
+}
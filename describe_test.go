@@ -0,0 +1,42 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bjaus/vault"
+)
+
+func TestDescribe(t *testing.T) {
+	t.Parallel()
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+	v := vault.New(
+		vault.WithNamespace("prod"),
+		vault.WithTTL(time.Hour),
+		vault.WithSource(src),
+		vault.WithSource(src),
+	)
+
+	assert.Equal(t, vault.VaultInfo{
+		StoreType:       "*vault.Memory",
+		Namespace:       "prod",
+		NamespaceActive: true,
+		TTL:             time.Hour,
+		Sources:         2,
+	}, v.Describe())
+}
+
+func TestDescribe_namespaceIgnored(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New(vault.WithStore(listOnlyStore{vault.NewMemory()}), vault.WithNamespace("prod"))
+
+	info := v.Describe()
+	assert.Equal(t, "vault_test.listOnlyStore", info.StoreType)
+	assert.Equal(t, "prod", info.Namespace)
+	assert.False(t, info.NamespaceActive, "the store does not implement Namespaced")
+}
@@ -0,0 +1,124 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// DiffOp describes how [Vault.Refresh] would change a key, as reported by
+// [Diff].
+type DiffOp int
+
+// Diff operations reported by [Diff].
+const (
+	// DiffUnchanged reports a key whose stored value matches the source.
+	DiffUnchanged DiffOp = iota + 1
+	// DiffAdd reports a key produced by a source but not yet stored.
+	DiffAdd
+	// DiffUpdate reports a key whose stored value differs from the source.
+	DiffUpdate
+	// DiffDelete reports a stored key that no source produces.
+	DiffDelete
+)
+
+// String returns the lower-case name of the operation.
+func (o DiffOp) String() string {
+	switch o {
+	case DiffUnchanged:
+		return "unchanged"
+	case DiffAdd:
+		return "add"
+	case DiffUpdate:
+		return "update"
+	case DiffDelete:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
+
+// Change is one difference reported by [Diff] or to [WithOnRefresh]. Old
+// is the zero value for [DiffAdd] and New is the zero value for
+// [DiffDelete].
+type Change struct {
+	// Namespace is the mount namespace of a source added with
+	// [WithSourceNamespace], or empty for the vault's own store.
+	Namespace string
+	Key       string
+	Old       Entry
+	New       Entry
+	Op        DiffOp
+}
+
+var errDiffUnsupported = errors.New("vault implementation does not support diff")
+
+// Diff fetches from every source of v, exactly as [Vault.Refresh] would,
+// and compares the result with the store without writing anything.
+// Entries are compared by value only, so differing metadata such as
+// [Entry.CreatedAt] does not count as a change. Stored entries marked
+// [Entry.ReadOnly] are reported unchanged when [WithSkipReadOnly] is set.
+//
+// [DiffDelete] reports stored keys that no source produces. Refresh never
+// removes them; they are listed so that entries set by hand or left behind
+// by a removed source can be found. Changes are sorted by namespace, then
+// key.
+func Diff(ctx context.Context, v Vault) ([]Change, error) {
+	d, ok := v.(interface {
+		diff(ctx context.Context) ([]Change, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("vault: diff: %w", errDiffUnsupported)
+	}
+	return d.diff(ctx)
+}
+
+func (v *vault) diff(ctx context.Context) ([]Change, error) {
+	batches, err := v.fetchAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := map[string]Store{"": v.cache}
+	incoming := make(map[targetKey]Entry)
+	for _, b := range v.prepare(batches) {
+		targets[b.namespace] = b.target
+		for _, e := range b.entries {
+			incoming[targetKey{namespace: b.namespace, key: e.Key}] = e
+		}
+	}
+
+	var changes []Change
+	for ns, target := range targets {
+		current, err := target.List(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("vault: diff: %w", err)
+		}
+		for _, old := range current {
+			tk := targetKey{namespace: ns, key: old.Key}
+			e, ok := incoming[tk]
+			delete(incoming, tk)
+
+			c := Change{Namespace: ns, Key: old.Key, Old: old, New: e}
+			switch {
+			case !ok:
+				c.Op = DiffDelete
+			case old.Value == e.Value, old.ReadOnly && v.skipReadOnly:
+				c.Op = DiffUnchanged
+			default:
+				c.Op = DiffUpdate
+			}
+			changes = append(changes, c)
+		}
+	}
+	for tk, e := range incoming {
+		changes = append(changes, Change{Namespace: tk.namespace, Key: tk.key, New: e, Op: DiffAdd})
+	}
+
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Key, b.Key))
+	})
+	return changes, nil
+}
@@ -0,0 +1,94 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "same", Value: "1", Source: "old"}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "changed", Value: "old"}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "manual", Value: "m"}))
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{
+			{Key: "same", Value: "1", Source: "ssm"},
+			{Key: "changed", Value: "new"},
+			{Key: "added", Value: "a"},
+		}, nil
+	})
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+
+	changes, err := vault.Diff(ctx, v)
+	require.NoError(t, err)
+
+	ops := make(map[string]vault.DiffOp, len(changes))
+	keys := make([]string, 0, len(changes))
+	for _, c := range changes {
+		ops[c.Key] = c.Op
+		keys = append(keys, c.Key)
+	}
+	assert.Equal(t, []string{"added", "changed", "manual", "same"}, keys)
+	assert.Equal(t, map[string]vault.DiffOp{
+		"added":   vault.DiffAdd,
+		"changed": vault.DiffUpdate,
+		"manual":  vault.DiffDelete,
+		"same":    vault.DiffUnchanged,
+	}, ops)
+
+	assert.Equal(t, "old", changes[1].Old.Value)
+	assert.Equal(t, "new", changes[1].New.Value)
+	assert.Empty(t, changes[0].Old.Key)
+
+	_, err = store.Get(ctx, "added")
+	require.ErrorIs(t, err, vault.ErrNotFound, "diff must not write")
+	got, err := store.Get(ctx, "changed")
+	require.NoError(t, err)
+	assert.Equal(t, "old", got.Value)
+}
+
+func TestDiff_namespacedSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+	v := vault.New(vault.WithNamespace("app"), vault.WithSourceNamespace(src, "shared"))
+
+	changes, err := vault.Diff(ctx, v)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, vault.Change{Namespace: "shared", Key: "k", New: vault.Entry{Key: "k", Value: "v"}, Op: vault.DiffAdd}, changes[0])
+}
+
+func TestDiff_sourceError(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("boom")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errFetch })
+	v := vault.New(vault.WithSource(src))
+
+	_, err := vault.Diff(context.Background(), v)
+	require.ErrorIs(t, err, errFetch)
+}
+
+func TestDiffOp_String(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "add", vault.DiffAdd.String())
+	assert.Equal(t, "update", vault.DiffUpdate.String())
+	assert.Equal(t, "delete", vault.DiffDelete.String())
+	assert.Equal(t, "unchanged", vault.DiffUnchanged.String())
+	assert.Equal(t, "unknown", vault.DiffOp(0).String())
+}
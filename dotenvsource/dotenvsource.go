@@ -0,0 +1,134 @@
+// Package dotenvsource implements a [vault.Source] that reads entries from
+// a dotenv (.env) file.
+//
+// Each non-blank line has the form KEY=value, optionally preceded by
+// "export ". Lines starting with "#" are comments. Values may be wrapped
+// in single quotes, taken literally, or double quotes, in which \n, \t,
+// \" and \\ are unescaped. Unquoted values end at an inline " #" comment
+// and are trimmed of surrounding whitespace.
+package dotenvsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bjaus/vault"
+)
+
+// ErrSyntax is returned, wrapped with the file path and line number, when
+// a line of the file cannot be parsed.
+var ErrSyntax = errors.New("dotenvsource: syntax error")
+
+var (
+	errNoAssignment      = errors.New("expected KEY=value")
+	errUnterminatedQuote = errors.New("unterminated quote")
+)
+
+// Source is a [vault.Source] over a dotenv file. The file is read on every
+// Fetch. It implements [vault.Named] using the file path.
+type Source struct {
+	path string
+}
+
+// New creates a [Source] for the file at path.
+func New(path string) *Source {
+	return &Source{path: path}
+}
+
+// Name returns the file path.
+func (s *Source) Name() string { return s.path }
+
+// Fetch parses the file and returns one entry per variable, with Source
+// set to the file path. If the file does not exist, the error wraps
+// [os.ErrNotExist].
+func (s *Source) Fetch(_ context.Context) ([]vault.Entry, error) {
+	data, err := os.ReadFile(s.path) //nolint:gosec // path is caller-provided by design
+	if err != nil {
+		return nil, fmt.Errorf("dotenvsource: read %s: %w", s.path, err)
+	}
+
+	var entries []vault.Entry
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		key, value, ok, err := parseLine(sc.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%w: %s:%d: %w", ErrSyntax, s.path, n, err)
+		}
+		if ok {
+			entries = append(entries, vault.Entry{Key: key, Value: value, Source: s.path})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("dotenvsource: read %s: %w", s.path, err)
+	}
+	return entries, nil
+}
+
+// parseLine parses one line of a dotenv file into a key and value. ok is
+// false for blank lines and comments.
+func parseLine(line string) (string, string, bool, error) {
+	line = strings.TrimSpace(line)
+	if line == "" || strings.HasPrefix(line, "#") {
+		return "", "", false, nil
+	}
+	line = strings.TrimPrefix(line, "export ")
+
+	key, raw, found := strings.Cut(line, "=")
+	key = strings.TrimSpace(key)
+	if !found || key == "" {
+		return "", "", false, errNoAssignment
+	}
+
+	value, err := parseValue(strings.TrimSpace(raw))
+	if err != nil {
+		return "", "", false, fmt.Errorf("%s: %w", key, err)
+	}
+	return key, value, true, nil
+}
+
+func parseValue(raw string) (string, error) {
+	if raw == "" {
+		return "", nil
+	}
+
+	switch quote := raw[0]; quote {
+	case '\'':
+		end := strings.IndexByte(raw[1:], '\'')
+		if end < 0 {
+			return "", errUnterminatedQuote
+		}
+		return raw[1 : end+1], nil
+	case '"':
+		var b strings.Builder
+		for i := 1; i < len(raw); i++ {
+			c := raw[i]
+			switch {
+			case c == '"':
+				return b.String(), nil
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					b.WriteByte('\n')
+				case 't':
+					b.WriteByte('\t')
+				default:
+					b.WriteByte(raw[i])
+				}
+			default:
+				b.WriteByte(c)
+			}
+		}
+		return "", errUnterminatedQuote
+	}
+
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	return strings.TrimSpace(raw), nil
+}
@@ -0,0 +1,99 @@
+package dotenvsource_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/dotenvsource"
+)
+
+func writeEnv(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), ".env")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestSource_Fetch(t *testing.T) {
+	t.Parallel()
+
+	path := writeEnv(t, `# database settings
+DB_HOST=db.internal
+
+export API_TOKEN=sk-123
+  SPACED = padded value   
+PLAIN=value # trailing comment
+HASH=a#b
+SINGLE='literal $HOME \n # kept'
+DOUBLE="line1\nline2 \"quoted\" # kept"
+EMPTY=
+EMPTY_QUOTED=""
+`)
+
+	entries, err := dotenvsource.New(path).Fetch(context.Background())
+	require.NoError(t, err)
+
+	got := make(map[string]string, len(entries))
+	for _, e := range entries {
+		assert.Equal(t, path, e.Source)
+		got[e.Key] = e.Value
+	}
+
+	assert.Equal(t, map[string]string{
+		"DB_HOST":      "db.internal",
+		"API_TOKEN":    "sk-123",
+		"SPACED":       "padded value",
+		"PLAIN":        "value",
+		"HASH":         "a#b",
+		"SINGLE":       `literal $HOME \n # kept`,
+		"DOUBLE":       "line1\nline2 \"quoted\" # kept",
+		"EMPTY":        "",
+		"EMPTY_QUOTED": "",
+	}, got)
+}
+
+func TestSource_Fetch_missingFile(t *testing.T) {
+	t.Parallel()
+
+	_, err := dotenvsource.New(filepath.Join(t.TempDir(), ".env")).Fetch(context.Background())
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestSource_Fetch_malformed(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"no equals":           "JUST_A_WORD\n",
+		"unterminated double": "K=\"open\n",
+		"unterminated single": "K='open\n",
+		"empty key":           "=value\n",
+	}
+
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := dotenvsource.New(writeEnv(t, content)).Fetch(context.Background())
+			require.ErrorIs(t, err, dotenvsource.ErrSyntax)
+			assert.Contains(t, err.Error(), ":1:")
+		})
+	}
+}
+
+func TestSource_withVault(t *testing.T) {
+	t.Parallel()
+
+	path := writeEnv(t, "REGION=us-east-1\n")
+	v := vault.New(vault.WithSource(dotenvsource.New(path)))
+
+	e, err := v.Get(context.Background(), "REGION")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", e.Value)
+}
@@ -0,0 +1,199 @@
+package vault
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+// ErrDecrypt is returned, wrapped, when a value read through an
+// [Encrypted] store cannot be decrypted, typically because it was written
+// with a different key or has been tampered with.
+var ErrDecrypt = errors.New("vault: decrypt failed")
+
+// Encrypted wraps inner so that [Entry.Value] is encrypted with AES-GCM
+// before it is written and decrypted after it is read. key must be 16, 24
+// or 32 bytes, selecting AES-128, AES-192 or AES-256.
+//
+// Only the value is encrypted; the key, timestamps and source are stored
+// in plaintext so listing and indexing keep working. Each ciphertext is
+// bound to its entry key, so a value copied to another key fails to
+// decrypt. Closing the returned store closes inner, if it implements
+// [io.Closer]. If inner implements [Namespaced], so does the returned
+// store.
+func Encrypted(inner Store, key []byte) (Store, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("vault: encrypted store: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("vault: encrypted store: %w", err)
+	}
+	return newEncryptedStore(inner, aead), nil
+}
+
+func newEncryptedStore(inner Store, aead cipher.AEAD) Store {
+	s := encryptedStore{Store: inner, aead: aead}
+	if ns, ok := inner.(Namespaced); ok {
+		return namespacedEncryptedStore{encryptedStore: s, ns: ns}
+	}
+	return s
+}
+
+type encryptedStore struct {
+	Store
+	aead cipher.AEAD
+}
+
+type namespacedEncryptedStore struct {
+	encryptedStore
+	ns Namespaced
+}
+
+func (s namespacedEncryptedStore) WithNamespace(namespace string) Store {
+	return newEncryptedStore(s.ns.WithNamespace(namespace), s.aead)
+}
+
+func (s encryptedStore) Close() error {
+	return closeStore(s.Store)
+}
+
+func (s encryptedStore) Get(ctx context.Context, key string) (Entry, error) {
+	e, err := s.Store.Get(ctx, key)
+	if err != nil {
+		return Entry{}, err
+	}
+	return s.open(e)
+}
+
+func (s encryptedStore) GetMany(ctx context.Context, keys []string) (map[string]Entry, error) {
+	found, err := getMany(ctx, s.Store, keys)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range found {
+		if found[k], err = s.open(e); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+func (s encryptedStore) Exists(ctx context.Context, key string) (bool, error) {
+	return exists(ctx, s.Store, key)
+}
+
+func (s encryptedStore) Keys(ctx context.Context) ([]string, error) {
+	return listKeys(ctx, s.Store)
+}
+
+func (s encryptedStore) Snapshot(ctx context.Context) (map[string]Entry, error) {
+	snap, err := snapshot(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+	for k, e := range snap {
+		if snap[k], err = s.open(e); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+func (s encryptedStore) ListByTag(ctx context.Context, key, value string) ([]Entry, error) {
+	entries, err := listByTag(ctx, s.Store, key, value)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if entries[i], err = s.open(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s encryptedStore) ListPrefix(ctx context.Context, prefix string) ([]Entry, error) {
+	entries, err := listPrefix(ctx, s.Store, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if entries[i], err = s.open(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s encryptedStore) KeysPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return keysPrefix(ctx, s.Store, prefix)
+}
+
+func (s encryptedStore) Set(ctx context.Context, entry Entry) error {
+	sealed, err := s.seal(entry)
+	if err != nil {
+		return err
+	}
+	return s.Store.Set(ctx, sealed)
+}
+
+func (s encryptedStore) SetMany(ctx context.Context, entries []Entry) error {
+	sealed := make([]Entry, len(entries))
+	for i, e := range entries {
+		var err error
+		if sealed[i], err = s.seal(e); err != nil {
+			return err
+		}
+	}
+	return setMany(ctx, s.Store, sealed)
+}
+
+func (s encryptedStore) DeleteMany(ctx context.Context, keys []string) error {
+	return deleteMany(ctx, s.Store, keys)
+}
+
+func (s encryptedStore) List(ctx context.Context) ([]Entry, error) {
+	entries, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i, e := range entries {
+		if entries[i], err = s.open(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// seal encrypts the value of e.
+func (s encryptedStore) seal(e Entry) (Entry, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return Entry{}, fmt.Errorf("vault: encrypt %q: %w", e.Key, err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(e.Value), []byte(e.Key))
+	e.Value = base64.StdEncoding.EncodeToString(sealed)
+	return e, nil
+}
+
+// open decrypts the value of e.
+func (s encryptedStore) open(e Entry) (Entry, error) {
+	sealed, err := base64.StdEncoding.DecodeString(e.Value)
+	if err != nil || len(sealed) < s.aead.NonceSize() {
+		return Entry{}, fmt.Errorf("%w: %q: malformed ciphertext", ErrDecrypt, e.Key)
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plain, err := s.aead.Open(nil, nonce, ciphertext, []byte(e.Key))
+	if err != nil {
+		return Entry{}, fmt.Errorf("%w: %q", ErrDecrypt, e.Key)
+	}
+	e.Value = string(plain)
+	return e, nil
+}
@@ -0,0 +1,128 @@
+package vault_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func testKey(b byte) []byte { return bytes.Repeat([]byte{b}, 32) }
+
+func TestEncrypted_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	store, err := vault.Encrypted(inner, testKey(1))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "db-password", Value: "hunter2", Source: "manual"}))
+
+	got, err := store.Get(ctx, "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got.Value)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "hunter2", entries[0].Value)
+
+	raw, err := inner.Get(ctx, "db-password")
+	require.NoError(t, err)
+	assert.NotContains(t, raw.Value, "hunter2", "inner store must never see plaintext")
+	assert.Equal(t, "db-password", raw.Key)
+	assert.Equal(t, "manual", raw.Source)
+}
+
+func TestEncrypted_wrongKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+
+	writer, err := vault.Encrypted(inner, testKey(1))
+	require.NoError(t, err)
+	require.NoError(t, writer.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	reader, err := vault.Encrypted(inner, testKey(2))
+	require.NoError(t, err)
+
+	_, err = reader.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrDecrypt)
+
+	_, err = reader.List(ctx)
+	require.ErrorIs(t, err, vault.ErrDecrypt)
+}
+
+func TestEncrypted_boundToKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	store, err := vault.Encrypted(inner, testKey(1))
+	require.NoError(t, err)
+
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "a", Value: "secret"}))
+	raw, err := inner.Get(ctx, "a")
+	require.NoError(t, err)
+
+	raw.Key = "b"
+	require.NoError(t, inner.Set(ctx, raw))
+
+	_, err = store.Get(ctx, "b")
+	require.ErrorIs(t, err, vault.ErrDecrypt)
+}
+
+func TestEncrypted_invalidKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := vault.Encrypted(vault.NewMemory(), []byte("short"))
+	require.Error(t, err)
+}
+
+func TestEncrypted_withVaultNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store, err := vault.Encrypted(vault.NewMemory(), testKey(1))
+	require.NoError(t, err)
+	require.Implements(t, (*vault.Namespaced)(nil), store)
+
+	prod := vault.New(vault.WithStore(store), vault.WithNamespace("prod"))
+	qa := vault.New(vault.WithStore(store), vault.WithNamespace("qa"))
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "k", Value: "prod-value"}))
+
+	got, err := prod.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-value", got.Value)
+
+	_, err = qa.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestEncrypted_setMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	store, err := vault.Encrypted(inner, testKey(1))
+	require.NoError(t, err)
+
+	bs, ok := store.(vault.BatchSetter)
+	require.True(t, ok)
+	require.NoError(t, bs.SetMany(ctx, []vault.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}))
+
+	got, err := store.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.Value)
+
+	raw, err := inner.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.NotEqual(t, "2", raw.Value)
+}
@@ -0,0 +1,67 @@
+// Package envsource implements a [vault.Source] that reads entries from
+// the process environment.
+package envsource
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/bjaus/vault"
+)
+
+// Source is a [vault.Source] over environment variables whose names start
+// with a prefix. It implements [vault.Named] as "env".
+type Source struct {
+	prefix    string
+	keyMapper func(string) string
+}
+
+// Option configures a [Source].
+type Option func(*Source)
+
+// WithKeyMapper overrides how a variable name, with the prefix already
+// removed, is turned into an entry key. Variables for which fn returns ""
+// are skipped.
+func WithKeyMapper(fn func(string) string) Option {
+	return func(s *Source) { s.keyMapper = fn }
+}
+
+// New creates a [Source] for variables starting with prefix. An empty
+// prefix selects every variable. By default the prefix is removed and the
+// rest of the name is lowercased with "_" replaced by "-", so with prefix
+// "APP_" the variable APP_DB_HOST becomes the key "db-host".
+func New(prefix string, opts ...Option) *Source {
+	s := &Source{prefix: prefix, keyMapper: DefaultKeyMapper}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// DefaultKeyMapper lowercases name and replaces "_" with "-".
+func DefaultKeyMapper(name string) string {
+	return strings.ReplaceAll(strings.ToLower(name), "_", "-")
+}
+
+// Name returns "env".
+func (s *Source) Name() string { return "env" }
+
+// Fetch returns one entry per matching variable, with Source set to "env".
+func (s *Source) Fetch(_ context.Context) ([]vault.Entry, error) {
+	var entries []vault.Entry
+	for _, kv := range os.Environ() {
+		name, value, _ := strings.Cut(kv, "=")
+		rest, ok := strings.CutPrefix(name, s.prefix)
+		if !ok || rest == "" {
+			continue
+		}
+
+		key := s.keyMapper(rest)
+		if key == "" {
+			continue
+		}
+		entries = append(entries, vault.Entry{Key: key, Value: value, Source: "env"})
+	}
+	return entries, nil
+}
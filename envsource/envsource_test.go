@@ -0,0 +1,76 @@
+package envsource_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/envsource"
+)
+
+func byKey(entries []vault.Entry) map[string]vault.Entry {
+	m := make(map[string]vault.Entry, len(entries))
+	for _, e := range entries {
+		m[e.Key] = e
+	}
+	return m
+}
+
+func TestSource_Fetch(t *testing.T) {
+	t.Setenv("VAULTTEST_DB_HOST", "db.internal")
+	t.Setenv("VAULTTEST_API_TOKEN", "sk-123")
+	t.Setenv("VAULTTEST_", "no key")
+	t.Setenv("OTHER_VAR", "ignored")
+
+	entries, err := envsource.New("VAULTTEST_").Fetch(context.Background())
+	require.NoError(t, err)
+
+	got := byKey(entries)
+	assert.Len(t, got, 2)
+	assert.Equal(t, vault.Entry{Key: "db-host", Value: "db.internal", Source: "env"}, got["db-host"])
+	assert.Equal(t, "sk-123", got["api-token"].Value)
+}
+
+func TestSource_Fetch_emptyPrefix(t *testing.T) {
+	t.Setenv("VAULTTEST_ALL", "yes")
+
+	entries, err := envsource.New("").Fetch(context.Background())
+	require.NoError(t, err)
+
+	got := byKey(entries)
+	assert.Equal(t, "yes", got["vaulttest-all"].Value)
+}
+
+func TestSource_WithKeyMapper(t *testing.T) {
+	t.Setenv("VAULTTEST_Keep_Case", "v")
+	t.Setenv("VAULTTEST_SKIP_ME", "v")
+
+	src := envsource.New("VAULTTEST_", envsource.WithKeyMapper(func(name string) string {
+		if strings.HasPrefix(name, "SKIP") {
+			return ""
+		}
+		return name
+	}))
+
+	entries, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+
+	got := byKey(entries)
+	assert.Len(t, got, 1)
+	assert.Contains(t, got, "Keep_Case")
+}
+
+func TestSource_withVault(t *testing.T) {
+	t.Setenv("VAULTTEST_REGION", "us-east-1")
+
+	v := vault.New(vault.WithSource(envsource.New("VAULTTEST_")))
+
+	e, err := v.Get(context.Background(), "region")
+	require.NoError(t, err)
+	assert.Equal(t, "us-east-1", e.Value)
+	assert.Equal(t, "env", e.Source)
+}
@@ -0,0 +1,56 @@
+package vault
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrSource matches, with [errors.Is], every [*SourceError].
+var ErrSource = errors.New("vault: source failed")
+
+// StoreError records a failed store operation, so that callers can tell
+// with [errors.As] which operation and key failed without parsing the
+// message. Err is the underlying cause; sentinels such as [ErrNotFound]
+// and [ErrBackendUnavailable] still match through it with [errors.Is].
+type StoreError struct {
+	// Op is the operation that failed, such as "get", "set" or "marshal".
+	Op string
+
+	// Key is the entry key the operation concerned, or empty for
+	// operations on the store as a whole.
+	Key string
+
+	Err error
+}
+
+func (e *StoreError) Error() string {
+	if e.Key == "" {
+		return e.Op + ": " + e.Err.Error()
+	}
+	return e.Op + " " + strconv.Quote(e.Key) + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying cause.
+func (e *StoreError) Unwrap() error { return e.Err }
+
+// SourceError records a failed [Source.Fetch] during [Vault.Refresh],
+// identifying the source by its position among the configured sources
+// and its name. It matches [ErrSource] with [errors.Is].
+type SourceError struct {
+	// Index is the position of the source in the order it was added with
+	// [WithSource] or [WithSourceNamespace].
+	Index int
+
+	// Name is the [Named] name of the source, or "source N" when unnamed.
+	Name string
+
+	Err error
+}
+
+func (e *SourceError) Error() string { return e.Name + ": " + e.Err.Error() }
+
+// Unwrap returns the underlying cause.
+func (e *SourceError) Unwrap() error { return e.Err }
+
+// Is reports whether target is [ErrSource].
+func (e *SourceError) Is(target error) bool { return target == ErrSource }
@@ -0,0 +1,74 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestStoreError_memory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+
+	_, err := m.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	var serr *vault.StoreError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "get", serr.Op)
+	assert.Equal(t, "missing", serr.Key)
+
+	err = m.Rename(ctx, "gone", "new")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "rename", serr.Op)
+	assert.Equal(t, "gone", serr.Key)
+
+	err = m.LoadFrom(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "load", serr.Op)
+	assert.Empty(t, serr.Key)
+}
+
+func TestSourceError(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("down")
+	ok := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+	failing := vault.NamedSource("ssm", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errDown
+	}))
+
+	v := vault.New(vault.WithSource(ok), vault.WithSource(failing))
+	err := v.Refresh(context.Background())
+	require.ErrorIs(t, err, vault.ErrSource)
+	require.ErrorIs(t, err, errDown)
+
+	var serr *vault.SourceError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, 1, serr.Index)
+	assert.Equal(t, "ssm", serr.Name)
+	assert.EqualError(t, err, "vault: refresh: ssm: down")
+}
+
+func TestSourceError_notNamespaced(t *testing.T) {
+	t.Parallel()
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+	v := vault.New(vault.WithStore(listOnlyStore{vault.NewMemory()}), vault.WithSourceNamespace(src, "shared"))
+
+	err := v.Refresh(context.Background())
+	require.ErrorIs(t, err, vault.ErrNotNamespaced)
+
+	var serr *vault.SourceError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, 0, serr.Index)
+}
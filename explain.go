@@ -0,0 +1,102 @@
+package vault
+
+import (
+	"context"
+	"slices"
+	"time"
+)
+
+// Step is one decision taken while resolving a key.
+type Step string
+
+// Steps recorded in a [Resolution].
+const (
+	StepCacheHit       Step = "cache-hit"       // the store had a fresh entry
+	StepCacheMiss      Step = "cache-miss"      // the store had no entry
+	StepExpired        Step = "expired"         // the stored entry had outlived its TTL
+	StepRefreshed      Step = "refreshed"       // an auto-refresh ran and succeeded
+	StepRefreshFailed  Step = "refresh-failed"  // an auto-refresh failed, or is known to be failing
+	StepRefreshSkipped Step = "refresh-skipped" // auto-refresh was not due, or no sources are configured
+	StepServedStale    Step = "served-stale"    // an expired entry was returned instead of an error or a wait
+)
+
+// Resolution describes how [Vault.Explain] resolved a key.
+type Resolution struct {
+	Key   string
+	Steps []Step
+
+	// Source is the [Entry.Source] of the returned entry, if any.
+	Source string
+
+	// Stale is true when the returned entry had expired.
+	Stale bool
+
+	// Duration is the total time taken to resolve the key, and
+	// RefreshDuration the part of it spent in an auto-refresh.
+	Duration        time.Duration
+	RefreshDuration time.Duration
+}
+
+// Explain resolves key exactly as [Vault.Get] would, including any
+// auto-refresh, and reports the path taken. It returns the same error Get
+// would have.
+func (v *vault) Explain(ctx context.Context, key string) (Resolution, error) {
+	r := Resolution{Key: key}
+
+	start := time.Now()
+	e, err := v.resolve(ctx, key, 0, &r)
+	r.Duration = time.Since(start)
+
+	if err == nil {
+		r.Source = e.Source
+	}
+	return r, err
+}
+
+// GetMeta describes where an entry returned by [Vault.GetWithMeta] came
+// from.
+type GetMeta struct {
+	// FromCache is true when the entry was served from the store without
+	// an auto-refresh, including a stale entry served after a failure.
+	FromCache bool
+
+	// Refreshed is true when an auto-refresh ran before the entry was
+	// read.
+	Refreshed bool
+
+	// Stale is true when the entry had expired and was served anyway, for
+	// example by [WithServeStaleOnError] after a failed refresh.
+	Stale bool
+
+	// Age is how long ago the entry was created.
+	Age time.Duration
+}
+
+// GetWithMeta retrieves key exactly as [Vault.Get] would and reports
+// whether the result was served from the store or fetched by an
+// auto-refresh.
+func (v *vault) GetWithMeta(ctx context.Context, key string) (Entry, GetMeta, error) {
+	var r Resolution
+	e, err := v.resolve(ctx, key, 0, &r)
+	if err != nil {
+		return Entry{}, GetMeta{}, err
+	}
+
+	refreshed := slices.Contains(r.Steps, StepRefreshed)
+	return e, GetMeta{
+		FromCache: !refreshed,
+		Refreshed: refreshed,
+		Stale:     r.Stale,
+		Age:       v.since(e.CreatedAt),
+	}, nil
+}
+
+func (r *Resolution) record(s Step) {
+	if r == nil {
+		return
+	}
+	r.Steps = append(r.Steps, s)
+	if s == StepServedStale {
+		r.Stale = true
+	}
+}
@@ -0,0 +1,85 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestExplain_missThenRefresh(t *testing.T) {
+	t.Parallel()
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "v", Source: "ssm"}}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	r, err := v.Explain(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, "k", r.Key)
+	assert.Equal(t, []vault.Step{vault.StepCacheMiss, vault.StepRefreshed, vault.StepCacheHit}, r.Steps)
+	assert.Equal(t, "ssm", r.Source)
+	assert.False(t, r.Stale)
+	assert.Positive(t, r.Duration)
+	assert.LessOrEqual(t, r.RefreshDuration, r.Duration)
+}
+
+func TestExplain_cacheHit(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	r, err := v.Explain(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []vault.Step{vault.StepCacheHit}, r.Steps)
+	assert.Equal(t, "manual", r.Source)
+	assert.Zero(t, r.RefreshDuration)
+}
+
+func TestExplain_expiredWithoutSource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "k", CreatedAt: time.Now().Add(-time.Hour)}))
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Minute))
+
+	r, err := v.Explain(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.Equal(t, []vault.Step{vault.StepExpired, vault.StepRefreshSkipped}, r.Steps)
+}
+
+func TestGetWithMeta(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	e, meta, err := v.GetWithMeta(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+	assert.True(t, meta.Refreshed)
+	assert.False(t, meta.FromCache)
+
+	time.Sleep(5 * time.Millisecond)
+
+	_, meta, err = v.GetWithMeta(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, meta.FromCache)
+	assert.False(t, meta.Refreshed)
+	assert.GreaterOrEqual(t, meta.Age, 5*time.Millisecond)
+
+	_, _, err = vault.New().GetWithMeta(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
@@ -0,0 +1,87 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExportOption configures [ExportJSON] and [ExportYAML].
+type ExportOption func(*exportConfig)
+
+type exportConfig struct {
+	redact bool
+}
+
+// WithRedactedValues makes the export replace every value with "****",
+// so the shape of the configuration can be shared without its secrets.
+func WithRedactedValues(redact bool) ExportOption {
+	return func(c *exportConfig) { c.redact = redact }
+}
+
+// ExportJSON writes every entry listed by v to w as an indented JSON
+// array of [Entry] objects, sorted by key, including metadata such as
+// created_at and source. The output can be read back with [ImportJSON].
+func ExportJSON(ctx context.Context, v Vault, w io.Writer, opts ...ExportOption) error {
+	entries, err := exportEntries(ctx, v, opts)
+	if err != nil {
+		return err
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(entries); err != nil {
+		return fmt.Errorf("vault: export: %w", err)
+	}
+	return nil
+}
+
+// ExportYAML is like [ExportJSON] but writes a YAML sequence, which can
+// be read back with [ImportYAML].
+func ExportYAML(ctx context.Context, v Vault, w io.Writer, opts ...ExportOption) error {
+	entries, err := exportEntries(ctx, v, opts)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]yamlEntry, len(entries))
+	for i, e := range entries {
+		docs[i] = yamlEntry(e)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(docs); err != nil {
+		return fmt.Errorf("vault: export: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return fmt.Errorf("vault: export: %w", err)
+	}
+	return nil
+}
+
+// exportEntries lists v sorted by key, redacting values if asked to.
+func exportEntries(ctx context.Context, v Vault, opts []ExportOption) ([]Entry, error) {
+	var cfg exportConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	entries, err := v.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault: export: %w", err)
+	}
+
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Key, b.Key) })
+	if cfg.redact {
+		for i := range entries {
+			entries[i].Value = redacted
+		}
+	}
+	return entries, nil
+}
@@ -0,0 +1,101 @@
+package vault_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func newExportVault(t *testing.T) vault.Vault {
+	t.Helper()
+
+	ctx := context.Background()
+	created := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	v := vault.New()
+	for _, key := range []string{"zeta", "alpha", "mid"} {
+		require.NoError(t, v.Set(ctx, vault.Entry{Key: key, Value: "secret-" + key, Source: "ssm", CreatedAt: created}))
+	}
+	return v
+}
+
+func TestExportJSON(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := newExportVault(t)
+
+	var first, second bytes.Buffer
+	require.NoError(t, vault.ExportJSON(ctx, v, &first))
+	require.NoError(t, vault.ExportJSON(ctx, v, &second))
+	assert.Equal(t, first.String(), second.String(), "output must be deterministic")
+
+	var docs []map[string]any
+	require.NoError(t, json.Unmarshal(first.Bytes(), &docs))
+	require.Len(t, docs, 3)
+	for i, want := range []string{"alpha", "mid", "zeta"} {
+		assert.Equal(t, want, docs[i]["key"])
+	}
+	assert.Equal(t, "secret-alpha", docs[0]["value"])
+	assert.Equal(t, "ssm", docs[0]["source"])
+	assert.Equal(t, "2024-01-02T15:04:05Z", docs[0]["created_at"])
+
+	restored := vault.New()
+	n, err := vault.ImportJSON(ctx, restored, &first)
+	require.NoError(t, err)
+	assert.Equal(t, 3, n)
+}
+
+func TestExportYAML(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := newExportVault(t)
+
+	var out bytes.Buffer
+	require.NoError(t, vault.ExportYAML(ctx, v, &out))
+
+	doc := out.String()
+	alpha, mid, zeta := strings.Index(doc, "key: alpha"), strings.Index(doc, "key: mid"), strings.Index(doc, "key: zeta")
+	require.NotEqual(t, -1, alpha)
+	assert.Less(t, alpha, mid)
+	assert.Less(t, mid, zeta)
+	assert.Contains(t, doc, "source: ssm")
+	assert.Contains(t, doc, "created_at: 2024-01-02T15:04:05Z")
+
+	restored := vault.New()
+	_, err := vault.ImportYAML(ctx, restored, &out)
+	require.NoError(t, err)
+	got, err := restored.Get(ctx, "mid")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-mid", got.Value)
+	assert.Equal(t, "ssm", got.Source)
+}
+
+func TestExport_redacted(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := newExportVault(t)
+
+	var js, ys bytes.Buffer
+	require.NoError(t, vault.ExportJSON(ctx, v, &js, vault.WithRedactedValues(true)))
+	require.NoError(t, vault.ExportYAML(ctx, v, &ys, vault.WithRedactedValues(true)))
+
+	for _, out := range []string{js.String(), ys.String()} {
+		assert.NotContains(t, out, "secret-")
+		assert.Contains(t, out, "****")
+		assert.Contains(t, out, "alpha")
+	}
+
+	got, err := v.Get(ctx, "alpha")
+	require.NoError(t, err)
+	assert.Equal(t, "secret-alpha", got.Value, "redaction must not touch the store")
+}
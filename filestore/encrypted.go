@@ -0,0 +1,210 @@
+package filestore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/bjaus/vault"
+)
+
+// ErrBadPassphrase is returned, wrapped, when [NewEncrypted] opens a file
+// with a passphrase other than the one it was written with.
+var ErrBadPassphrase = errors.New("filestore: wrong passphrase")
+
+// ErrCorrupt is returned, wrapped, when an encrypted file is not in the
+// expected format or its contents have been modified.
+var ErrCorrupt = errors.New("filestore: encrypted file is corrupt")
+
+// encryptedFormat identifies the envelope written by [NewEncrypted].
+const encryptedFormat = "vault-filestore-encrypted/1"
+
+// Default scrypt cost parameters for new files, as recommended for
+// interactive use in the scrypt paper.
+const (
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+// Limits on the header of an existing file, so that a tampered header
+// cannot make opening it take unbounded time or memory.
+const (
+	maxScryptN = 1 << 20
+	maxScryptR = 32
+	maxScryptP = 16
+	saltSize   = 16
+)
+
+// envelope is the on-disk form of an encrypted store. Everything needed
+// to derive the key, apart from the passphrase, is kept alongside the
+// ciphertext so that the file is self-describing.
+type envelope struct {
+	Format string `json:"format"`
+	KDF    string `json:"kdf"`
+	N      int    `json:"n"`
+	R      int    `json:"r"`
+	P      int    `json:"p"`
+	Salt   []byte `json:"salt"`
+
+	// Check is a digest of a key derived alongside the encryption key. It
+	// tells a wrong passphrase apart from a tampered file.
+	Check []byte `json:"check"`
+
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+// fileCipher seals and opens the whole file for an encrypted store.
+type fileCipher struct {
+	header envelope // without nonce and ciphertext
+	aead   cipher.AEAD
+}
+
+// NewEncrypted opens the encrypted store at path, like [New], but keeps
+// the whole file encrypted with AES-256-GCM under a key derived from
+// passphrase with scrypt. A new file gets a random salt; the salt and
+// scrypt parameters are stored in the file's header. Opening an existing
+// file with a different passphrase fails with [ErrBadPassphrase], and a
+// file whose encrypted contents have been modified, or whose header is
+// malformed or has out-of-range scrypt parameters, fails with
+// [ErrCorrupt]. A salt replaced by another of the same size cannot be
+// told apart from a wrong passphrase and fails with [ErrBadPassphrase].
+func NewEncrypted(path, passphrase string, opts ...Option) (*Store, error) {
+	state := &fileState{
+		entries: make(map[string]vault.Entry),
+		path:    path,
+		mode:    defaultMode,
+	}
+	for _, opt := range opts {
+		opt(state)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is caller-provided by design
+	switch {
+	case errors.Is(err, fs.ErrNotExist) || err == nil && len(data) == 0:
+		salt := make([]byte, saltSize)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, fmt.Errorf("filestore: salt: %w", err)
+		}
+		header := envelope{Format: encryptedFormat, KDF: "scrypt", N: scryptN, R: scryptR, P: scryptP, Salt: salt}
+		if state.cipher, err = newFileCipher(header, passphrase); err != nil {
+			return nil, err
+		}
+		if err := state.flush(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("filestore: read %s: %w", path, err)
+	default:
+		var env envelope
+		if err := json.Unmarshal(data, &env); err != nil || env.Format != encryptedFormat || env.KDF != "scrypt" {
+			return nil, fmt.Errorf("filestore: decode %s: %w", path, ErrCorrupt)
+		}
+		if err := checkHeader(env); err != nil {
+			return nil, fmt.Errorf("filestore: decode %s: %w", path, err)
+		}
+		if state.cipher, err = newFileCipher(env, passphrase); err != nil {
+			return nil, fmt.Errorf("filestore: open %s: %w", path, err)
+		}
+		plain, err := state.cipher.open(env)
+		if err != nil {
+			return nil, fmt.Errorf("filestore: open %s: %w", path, err)
+		}
+		if err := json.Unmarshal(plain, &state.entries); err != nil {
+			return nil, fmt.Errorf("filestore: decode %s: %w", path, err)
+		}
+	}
+
+	return &Store{state: state}, nil
+}
+
+// checkHeader reports an [ErrCorrupt] error if the key derivation
+// parameters in env are outside the range this package writes and
+// accepts.
+func checkHeader(env envelope) error {
+	var reason string
+	switch {
+	case env.N <= 1 || env.N > maxScryptN || env.N&(env.N-1) != 0:
+		reason = fmt.Sprintf("scrypt N %d is not a power of two up to %d", env.N, maxScryptN)
+	case env.R < 1 || env.R > maxScryptR:
+		reason = fmt.Sprintf("scrypt r %d is not between 1 and %d", env.R, maxScryptR)
+	case env.P < 1 || env.P > maxScryptP:
+		reason = fmt.Sprintf("scrypt p %d is not between 1 and %d", env.P, maxScryptP)
+	case len(env.Salt) != saltSize:
+		reason = fmt.Sprintf("salt is %d bytes, want %d", len(env.Salt), saltSize)
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrCorrupt, reason)
+}
+
+// newFileCipher derives the keys for header from passphrase. If header
+// already carries a check digest, the passphrase must match it.
+func newFileCipher(header envelope, passphrase string) (*fileCipher, error) {
+	derived, err := scrypt.Key([]byte(passphrase), header.Salt, header.N, header.R, header.P, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrCorrupt, err)
+	}
+	encKey, checkKey := derived[:32], derived[32:]
+
+	check := sha256.Sum256(checkKey)
+	if header.Check == nil {
+		header.Check = check[:]
+	} else if subtle.ConstantTimeCompare(header.Check, check[:]) != 1 {
+		return nil, ErrBadPassphrase
+	}
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	header.Nonce, header.Ciphertext = nil, nil
+	return &fileCipher{header: header, aead: aead}, nil
+}
+
+// seal encrypts plain into a complete file. The header is authenticated
+// along with the contents.
+func (c *fileCipher) seal(plain []byte) ([]byte, error) {
+	env := c.header
+	env.Nonce = make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(env.Nonce); err != nil {
+		return nil, fmt.Errorf("filestore: encrypt: %w", err)
+	}
+	aad, err := json.Marshal(c.header)
+	if err != nil {
+		return nil, fmt.Errorf("filestore: encrypt: %w", err)
+	}
+	env.Ciphertext = c.aead.Seal(nil, env.Nonce, plain, aad)
+	return json.Marshal(env)
+}
+
+// open decrypts the contents of env.
+func (c *fileCipher) open(env envelope) ([]byte, error) {
+	aad, err := json.Marshal(c.header)
+	if err != nil {
+		return nil, err
+	}
+	if len(env.Nonce) != c.aead.NonceSize() {
+		return nil, ErrCorrupt
+	}
+	plain, err := c.aead.Open(nil, env.Nonce, env.Ciphertext, aad)
+	if err != nil {
+		return nil, ErrCorrupt
+	}
+	return plain, nil
+}
@@ -0,0 +1,124 @@
+package filestore_test
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/filestore"
+)
+
+func TestNewEncrypted_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.enc")
+
+	s, err := filestore.NewEncrypted(path, "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "db-password", Value: "hunter2"}))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(raw), "hunter2")
+	assert.NotContains(t, string(raw), "db-password")
+
+	var header map[string]any
+	require.NoError(t, json.Unmarshal(raw, &header))
+	assert.Equal(t, "scrypt", header["kdf"])
+	assert.NotEmpty(t, header["salt"])
+
+	reopened, err := filestore.NewEncrypted(path, "correct horse")
+	require.NoError(t, err)
+	got, err := reopened.Get(ctx, "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got.Value)
+}
+
+func TestNewEncrypted_wrongPassphrase(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "vault.enc")
+	_, err := filestore.NewEncrypted(path, "correct horse")
+	require.NoError(t, err)
+
+	_, err = filestore.NewEncrypted(path, "battery staple")
+	require.ErrorIs(t, err, filestore.ErrBadPassphrase)
+}
+
+func TestNewEncrypted_tampered(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.enc")
+	s, err := filestore.NewEncrypted(path, "correct horse")
+	require.NoError(t, err)
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	var env map[string]any
+	require.NoError(t, json.Unmarshal(raw, &env))
+	ct, ok := env["ciphertext"].(string)
+	require.True(t, ok)
+	flipped := []byte(ct)
+	flipped[len(flipped)/2] ^= 'A' ^ 'B'
+	env["ciphertext"] = string(flipped)
+	raw, err = json.Marshal(env)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+	_, err = filestore.NewEncrypted(path, "correct horse")
+	require.ErrorIs(t, err, filestore.ErrCorrupt)
+}
+
+func TestNewEncrypted_badHeader(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(env map[string]any){
+		"huge n":         func(env map[string]any) { env["n"] = 1 << 30 },
+		"n not power":    func(env map[string]any) { env["n"] = 3 << 10 },
+		"zero r":         func(env map[string]any) { env["r"] = 0 },
+		"huge p":         func(env map[string]any) { env["p"] = 1 << 20 },
+		"truncated salt": func(env map[string]any) { env["salt"] = "AAAA" },
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(t.TempDir(), "vault.enc")
+			_, err := filestore.NewEncrypted(path, "correct horse")
+			require.NoError(t, err)
+
+			raw, err := os.ReadFile(path)
+			require.NoError(t, err)
+			var env map[string]any
+			require.NoError(t, json.Unmarshal(raw, &env))
+			tamper(env)
+			raw, err = json.Marshal(env)
+			require.NoError(t, err)
+			require.NoError(t, os.WriteFile(path, raw, 0o600))
+
+			_, err = filestore.NewEncrypted(path, "correct horse")
+			require.ErrorIs(t, err, filestore.ErrCorrupt)
+			require.NotErrorIs(t, err, filestore.ErrBadPassphrase)
+		})
+	}
+}
+
+func TestNewEncrypted_plaintextFile(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s, path := newStore(t)
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	_, err := filestore.NewEncrypted(path, "correct horse")
+	require.ErrorIs(t, err, filestore.ErrCorrupt)
+}
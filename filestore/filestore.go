@@ -0,0 +1,312 @@
+// Package filestore implements a [vault.Store] backed by a single JSON
+// file on disk, for hosts without an OS keychain such as headless servers
+// and CI runners.
+//
+// All entries are held in memory and the whole file is rewritten on every
+// mutation. Writes are atomic: data is written to a temporary file in the
+// same directory and renamed over the original, so a crash never leaves a
+// partially written file behind.
+package filestore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/bjaus/vault"
+)
+
+const defaultMode fs.FileMode = 0o600
+
+type fileState struct {
+	mu      sync.RWMutex
+	entries map[string]vault.Entry
+	path    string
+	mode    fs.FileMode
+
+	// cipher is set for stores opened with NewEncrypted.
+	cipher *fileCipher
+}
+
+// Store is a [vault.Store] persisted to a JSON file. It is safe for
+// concurrent use within a process and implements [vault.Namespaced] —
+// namespaces share the same file, with keys stored under a
+// namespace prefix as in [vault.Memory].
+type Store struct {
+	state  *fileState
+	prefix string
+}
+
+// Option configures a file [Store].
+type Option func(*fileState)
+
+// WithFileMode overrides the permissions the file is written with. The
+// default is 0600, since the file may contain secrets.
+func WithFileMode(mode fs.FileMode) Option {
+	return func(s *fileState) { s.mode = mode }
+}
+
+// New opens the store at path, loading any entries already saved there.
+// If the file does not exist it is created.
+func New(path string, opts ...Option) (*Store, error) {
+	state := &fileState{
+		entries: make(map[string]vault.Entry),
+		path:    path,
+		mode:    defaultMode,
+	}
+	for _, opt := range opts {
+		opt(state)
+	}
+
+	data, err := os.ReadFile(path) //nolint:gosec // path is caller-provided by design
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := state.flush(); err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, fmt.Errorf("filestore: read %s: %w", path, err)
+	case len(data) > 0:
+		if err := json.Unmarshal(data, &state.entries); err != nil {
+			return nil, fmt.Errorf("filestore: decode %s: %w", path, err)
+		}
+	}
+
+	return &Store{state: state}, nil
+}
+
+// WithNamespace returns a [vault.Store] scoped to the given namespace.
+// The returned store shares the same file as the original. It panics if
+// ns is not valid according to [vault.ValidateNamespace].
+func (s *Store) WithNamespace(ns string) vault.Store {
+	if err := vault.ValidateNamespace(ns); err != nil {
+		panic(err)
+	}
+	return &Store{state: s.state, prefix: ns + "/"}
+}
+
+// Get retrieves an entry by key.
+func (s *Store) Get(_ context.Context, key string) (vault.Entry, error) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	e, ok := s.state.entries[s.prefix+key]
+	if !ok {
+		return vault.Entry{}, vault.ErrNotFound
+	}
+	return e, nil
+}
+
+// Set stores an entry and rewrites the file. If the write fails the
+// entry is not stored.
+func (s *Store) Set(_ context.Context, entry vault.Entry) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	k := s.prefix + entry.Key
+	prev, had := s.state.entries[k]
+	s.state.entries[k] = entry
+
+	if err := s.state.flush(); err != nil {
+		s.state.restore(k, prev, had)
+		return err
+	}
+	return nil
+}
+
+// SetMany stores several entries and rewrites the file once. If the
+// write fails, or ctx is already done, none of the entries are stored.
+func (s *Store) SetMany(ctx context.Context, entries []vault.Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	type saved struct {
+		prev vault.Entry
+		had  bool
+	}
+	undo := make(map[string]saved, len(entries))
+	for _, e := range entries {
+		k := s.prefix + e.Key
+		if _, ok := undo[k]; !ok {
+			prev, had := s.state.entries[k]
+			undo[k] = saved{prev, had}
+		}
+		s.state.entries[k] = e
+	}
+
+	if err := s.state.flush(); err != nil {
+		for k, u := range undo {
+			s.state.restore(k, u.prev, u.had)
+		}
+		return err
+	}
+	return nil
+}
+
+// Delete removes an entry by key and rewrites the file. Deleting a
+// missing key is not an error.
+func (s *Store) Delete(_ context.Context, key string) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	k := s.prefix + key
+	prev, had := s.state.entries[k]
+	if !had {
+		return nil
+	}
+	delete(s.state.entries, k)
+
+	if err := s.state.flush(); err != nil {
+		s.state.restore(k, prev, had)
+		return err
+	}
+	return nil
+}
+
+// DeleteMany removes several entries and rewrites the file once. If the
+// write fails none of the entries are removed.
+func (s *Store) DeleteMany(_ context.Context, keys []string) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	removed := make(map[string]vault.Entry, len(keys))
+	for _, key := range keys {
+		k := s.prefix + key
+		if prev, had := s.state.entries[k]; had {
+			removed[k] = prev
+			delete(s.state.entries, k)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+
+	if err := s.state.flush(); err != nil {
+		for k, prev := range removed {
+			s.state.restore(k, prev, true)
+		}
+		return err
+	}
+	return nil
+}
+
+// Rename moves the entry at oldKey to newKey and rewrites the file once.
+// If the write fails the store is left unchanged.
+func (s *Store) Rename(_ context.Context, oldKey, newKey string) error {
+	s.state.mu.Lock()
+	defer s.state.mu.Unlock()
+
+	oldK, newK := s.prefix+oldKey, s.prefix+newKey
+	e, ok := s.state.entries[oldK]
+	if !ok {
+		return vault.ErrNotFound
+	}
+	prev, had := s.state.entries[newK]
+
+	delete(s.state.entries, oldK)
+	moved := e
+	moved.Key = newKey
+	s.state.entries[newK] = moved
+
+	if err := s.state.flush(); err != nil {
+		s.state.restore(newK, prev, had)
+		s.state.restore(oldK, e, true)
+		return err
+	}
+	return nil
+}
+
+// List returns all entries in the store (within the current namespace).
+func (s *Store) List(_ context.Context) ([]vault.Entry, error) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	entries := make([]vault.Entry, 0, len(s.state.entries))
+	for k, e := range s.state.entries {
+		if s.prefix == "" || strings.HasPrefix(k, s.prefix) && len(k) > len(s.prefix) {
+			entries = append(entries, e)
+		}
+	}
+
+	return entries, nil
+}
+
+// Keys returns the keys in scope, sorted.
+func (s *Store) Keys(_ context.Context) ([]string, error) {
+	s.state.mu.RLock()
+	defer s.state.mu.RUnlock()
+
+	keys := make([]string, 0, len(s.state.entries))
+	for k := range s.state.entries {
+		if key, ok := strings.CutPrefix(k, s.prefix); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys, nil
+}
+
+func (f *fileState) restore(k string, prev vault.Entry, had bool) {
+	if had {
+		f.entries[k] = prev
+	} else {
+		delete(f.entries, k)
+	}
+}
+
+// flush writes all entries to the file. The caller must hold f.mu.
+func (f *fileState) flush() error {
+	data, err := json.Marshal(f.entries)
+	if err != nil {
+		return fmt.Errorf("filestore: encode: %w", err)
+	}
+	if f.cipher != nil {
+		if data, err = f.cipher.seal(data); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFileAtomic(f.path, data, f.mode); err != nil {
+		return fmt.Errorf("filestore: write %s: %w", f.path, err)
+	}
+	return nil
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place, so readers never observe a partially written file.
+func writeFileAtomic(path string, data []byte, mode fs.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), mode); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,257 @@
+package filestore_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/filestore"
+)
+
+func newStore(t *testing.T) (*filestore.Store, string) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "vault.json")
+	s, err := filestore.New(path)
+	require.NoError(t, err)
+	return s, path
+}
+
+func TestStore_GetSetDelete(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	got, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+
+	require.NoError(t, s.Delete(ctx, "k"))
+
+	_, err = s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_Delete_nonexistent(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	require.NoError(t, s.Delete(context.Background(), "nope"))
+}
+
+func TestStore_CreatesFileWithRestrictedMode(t *testing.T) {
+	t.Parallel()
+
+	_, path := newStore(t)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+}
+
+func TestStore_SurvivesRestart(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1", Source: "test"}))
+	require.NoError(t, s.WithNamespace("prod").Set(ctx, vault.Entry{Key: "a", Value: "prod-1"}))
+
+	reopened, err := filestore.New(path)
+	require.NoError(t, err)
+
+	got, err := reopened.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Value)
+	assert.Equal(t, "test", got.Source)
+
+	got, err = reopened.WithNamespace("prod").Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-1", got.Value)
+}
+
+func TestStore_ConcurrentSetGet(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			key := fmt.Sprintf("k%d", i)
+			assert.NoError(t, s.Set(ctx, vault.Entry{Key: key, Value: key}))
+			got, err := s.Get(ctx, key)
+			assert.NoError(t, err)
+			assert.Equal(t, key, got.Value)
+		}()
+	}
+	wg.Wait()
+
+	reopened, err := filestore.New(path)
+	require.NoError(t, err)
+
+	entries, err := reopened.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 20)
+}
+
+func TestStore_NamespaceList(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	ctx := context.Background()
+
+	prod := s.WithNamespace("prod")
+	qa := s.WithNamespace("qa")
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	require.NoError(t, qa.Set(ctx, vault.Entry{Key: "c", Value: "3"}))
+
+	entries, err := prod.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = s.Get(ctx, "a")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_CorruptFile(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "vault.json")
+	require.NoError(t, os.WriteFile(path, []byte("{not json"), 0o600))
+
+	_, err := filestore.New(path)
+	require.Error(t, err)
+}
+
+func TestStore_ImplementsNamespaced(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+
+	var store vault.Store = s
+	_, ok := store.(vault.Namespaced)
+	assert.True(t, ok, "filestore.Store should implement vault.Namespaced")
+}
+
+func TestStore_Keys(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a"}))
+	require.NoError(t, s.WithNamespace("ns").Set(ctx, vault.Entry{Key: "c"}))
+
+	keys, err := s.WithNamespace("ns").(vault.KeyLister).Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c"}, keys)
+}
+
+func TestStore_SetMany(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "old"}))
+	require.NoError(t, s.SetMany(ctx, []vault.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}))
+
+	reopened, err := filestore.New(path)
+	require.NoError(t, err)
+	got, err := reopened.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Value)
+	_, err = reopened.Get(ctx, "b")
+	require.NoError(t, err)
+}
+
+func TestStore_SetMany_failedWriteStoresNothing(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "old"}))
+	require.NoError(t, os.RemoveAll(filepath.Dir(path)))
+
+	err := s.SetMany(ctx, []vault.Entry{{Key: "a", Value: "new"}, {Key: "b", Value: "2"}})
+	require.Error(t, err)
+
+	got, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "old", got.Value)
+	_, err = s.Get(ctx, "b")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_PersistsTags(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Tags: map[string]string{"env": "prod"}}))
+
+	reopened, err := filestore.New(path)
+	require.NoError(t, err)
+	got, err := reopened.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"env": "prod"}, got.Tags)
+}
+
+func TestStore_Rename(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "api-key", Value: "v", Source: "ssm"}))
+	require.NoError(t, s.Rename(ctx, "api-key", "api-token"))
+	require.ErrorIs(t, s.Rename(ctx, "api-key", "other"), vault.ErrNotFound)
+
+	reopened, err := filestore.New(path)
+	require.NoError(t, err)
+	_, err = reopened.Get(ctx, "api-key")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	got, err := reopened.Get(ctx, "api-token")
+	require.NoError(t, err)
+	assert.Equal(t, vault.Entry{Key: "api-token", Value: "v", Source: "ssm"}, got)
+}
+
+func TestStore_Rename_failedWriteChangesNothing(t *testing.T) {
+	t.Parallel()
+
+	s, path := newStore(t)
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	require.NoError(t, os.RemoveAll(filepath.Dir(path)))
+
+	require.Error(t, s.Rename(ctx, "a", "b"))
+
+	got, err := s.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Value)
+	got, err = s.Get(ctx, "b")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.Value)
+}
@@ -0,0 +1,140 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// GetFresh returns key as the sources have it now, ignoring any cached
+// entry. The sources are fetched, and the entry they produce for key is
+// stored, subject to the same rules as [Vault.Refresh], and returned.
+// Other keys are left as they are, and the auto-refresh window is not
+// reset. It returns [ErrNotFound] if no source has the key, even if the
+// cache does. With no sources configured it behaves like [Vault.Get].
+// Under [WithNamespaceContext], the entry is stored in that namespace.
+func (v *vault) GetFresh(ctx context.Context, key string) (Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+	if len(v.sources) == 0 {
+		return v.Get(ctx, key)
+	}
+
+	found, err := v.refreshKey(ctx, key)
+	if err != nil {
+		return Entry{}, err
+	}
+	if !found {
+		return Entry{}, fmt.Errorf("vault: get fresh %q: %w", key, ErrNotFound)
+	}
+	return store.Get(ctx, key)
+}
+
+// autoRefreshKey is [vault.autoRefresh] for a single key, used when every
+// source implements [KeyFetcher]. Concurrent misses of the same key in
+// the same namespace share one fetch. Under [WithFailFastOnRefreshError],
+// a recent failure is returned without fetching.
+func (v *vault) autoRefreshKey(ctx context.Context, key string) error {
+	ns, _ := ctx.Value(namespaceKey{}).(string) //nolint:errcheck // absent means the vault's own namespace
+	ch := v.inflight.DoChan("key:"+ns+"\x00"+key, func() (any, error) {
+		if err := v.failingRefresh(); err != nil {
+			return nil, err
+		}
+		rctx, cancel := sharedContext(ctx)
+		defer cancel()
+		_, err := v.refreshKey(rctx, key)
+		return nil, err
+	})
+	return await(ctx, ch)
+}
+
+// refreshKey fetches the sources and writes what they produce for key
+// alone, using [KeyFetcher] where a source implements it. Sources that
+// would write to the vault's own store write to the namespace set by
+// [WithNamespaceContext] instead, if any, so that the key lands where
+// the caller reads it. It reports whether any of those sources, rather
+// than one with a [WithSourceNamespace] namespace, produced the key.
+// It counts as a refresh for [Vault.Stats], [Observer] and [Metrics], and
+// a failure is remembered for [WithFailFastOnRefreshError], but success
+// does not reset the auto-refresh window.
+func (v *vault) refreshKey(ctx context.Context, key string) (bool, error) {
+	target, ns, err := v.contextTarget(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	v.refreshStarted()
+	start := time.Now()
+	found, err := v.fetchKey(ctx, key, target, ns)
+	v.metrics.ObserveRefresh(time.Since(start), err)
+	v.refreshFinished(err)
+	return found, err
+}
+
+// fetchKey does the work of refreshKey, writing the vault's own sources
+// to target, the store for namespace ns.
+func (v *vault) fetchKey(ctx context.Context, key string, target Store, ns string) (bool, error) {
+	now := v.now()
+
+	batches := make([]batch, len(v.sources))
+	mounted := make([]bool, len(v.sources))
+	for i, src := range v.sources {
+		b, err := v.newBatch(i, src)
+		if err != nil {
+			return false, v.refreshFailed(now, fmt.Errorf("vault: refresh: %w", err))
+		}
+		if _, mounted[i] = src.(*mountedSource); !mounted[i] {
+			b.target, b.namespace = target, ns
+		}
+		if kf, ok := keyFetcher(b.src); ok {
+			b.src = fetchKeySource(kf, key)
+		}
+		batches[i] = b
+	}
+	if err := v.fetchInto(ctx, batches); err != nil {
+		return false, v.refreshFailed(now, err)
+	}
+	for i := range batches {
+		batches[i].entries = slices.DeleteFunc(slices.Clone(batches[i].entries), func(e Entry) bool {
+			return e.Key != key
+		})
+	}
+
+	prepared := v.prepare(batches)
+	var found bool
+	for i, b := range prepared {
+		found = found || (!mounted[i] && len(b.entries) > 0)
+	}
+	changes, err := v.apply(ctx, now, prepared)
+	if err != nil {
+		return false, v.refreshFailed(now, err)
+	}
+	v.keyRefreshSucceeded()
+	if len(changes) > 0 {
+		v.refreshed(ctx, changes)
+	}
+	return found, nil
+}
+
+// contextTarget returns the store that refreshKey writes the vault's own
+// sources to under ctx, and its namespace: the namespace set by
+// [WithNamespaceContext] when the store implements [Namespaced], or else
+// the vault's own store and "". Like refreshes, it bypasses
+// [WithReadOnly].
+func (v *vault) contextTarget(ctx context.Context) (Store, string, error) {
+	ns, ok := ctx.Value(namespaceKey{}).(string)
+	if _, named := v.config.store.(Namespaced); !ok || !named {
+		return v.cache, "", nil
+	}
+	if err := ValidateNamespace(ns); err != nil {
+		return nil, "", err
+	}
+	store, err := v.mount(ns)
+	if err != nil {
+		return nil, "", err
+	}
+	return store, ns, nil
+}
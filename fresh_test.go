@@ -0,0 +1,82 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestGetFresh_bypassesCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var (
+		fetches atomic.Int32
+		value   atomic.Pointer[string]
+	)
+	set := func(s string) { value.Store(&s) }
+	set("v1")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		fetches.Add(1)
+		return []vault.Entry{
+			{Key: "k", Value: *value.Load()},
+			{Key: "other", Value: *value.Load()},
+		}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	e, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", e.Value)
+	require.Equal(t, int32(1), fetches.Load())
+
+	set("v2")
+	e, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", e.Value, "a cache hit must not consult the source")
+
+	e, err = v.GetFresh(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", e.Value)
+	assert.Equal(t, int32(2), fetches.Load())
+
+	e, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", e.Value, "the fresh entry is stored")
+
+	e, err = v.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", e.Value, "other keys are left alone")
+}
+
+func TestGetFresh_missingFromSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+	v := vault.New(vault.WithSource(src))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "manual"}))
+
+	_, err := v.GetFresh(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestGetFresh_noSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	e, err := v.GetFresh(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+
+	_, err = v.GetFresh(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
@@ -0,0 +1,101 @@
+// Package grpcclient implements a [vault.Store] that talks to a vault
+// served by package grpcserver, so that a remote vault can back a local
+// one, or be used directly, like any other store.
+//
+// gRPC statuses are mapped back to the vault's errors: codes.NotFound
+// becomes [vault.ErrNotFound], codes.FailedPrecondition
+// [vault.ErrReadOnly], codes.Unavailable [vault.ErrBackendUnavailable],
+// and codes.Canceled and codes.DeadlineExceeded the matching context
+// errors.
+package grpcclient
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/grpcserver/vaultpb"
+)
+
+// Store is a [vault.Store] backed by a remote vault.
+type Store struct {
+	client vaultpb.VaultClient
+}
+
+// New creates a [Store] that issues its calls over conn, typically a
+// [grpc.ClientConn]. The caller owns conn and must close it.
+func New(conn grpc.ClientConnInterface) *Store {
+	return &Store{client: vaultpb.NewVaultClient(conn)}
+}
+
+// Get retrieves an entry by key.
+func (s *Store) Get(ctx context.Context, key string) (vault.Entry, error) {
+	resp, err := s.client.Get(ctx, &vaultpb.GetRequest{Key: key})
+	if status.Code(err) == codes.NotFound {
+		return vault.Entry{}, vault.ErrNotFound
+	}
+	if err != nil {
+		return vault.Entry{}, fmt.Errorf("grpcclient: get %q: %w", key, fromStatus(err))
+	}
+	return vaultpb.ToEntry(resp.GetEntry()), nil
+}
+
+// Set stores an entry.
+func (s *Store) Set(ctx context.Context, entry vault.Entry) error {
+	if _, err := s.client.Set(ctx, &vaultpb.SetRequest{Entry: vaultpb.FromEntry(entry)}); err != nil {
+		return fmt.Errorf("grpcclient: set %q: %w", entry.Key, fromStatus(err))
+	}
+	return nil
+}
+
+// Delete removes an entry.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if _, err := s.client.Delete(ctx, &vaultpb.DeleteRequest{Key: key}); err != nil {
+		return fmt.Errorf("grpcclient: delete %q: %w", key, fromStatus(err))
+	}
+	return nil
+}
+
+// List returns every entry of the remote vault.
+func (s *Store) List(ctx context.Context) ([]vault.Entry, error) {
+	resp, err := s.client.List(ctx, &vaultpb.ListRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("grpcclient: list: %w", fromStatus(err))
+	}
+	entries := make([]vault.Entry, len(resp.GetEntries()))
+	for i, e := range resp.GetEntries() {
+		entries[i] = vaultpb.ToEntry(e)
+	}
+	return entries, nil
+}
+
+// Refresh asks the remote vault to refresh from its sources.
+func (s *Store) Refresh(ctx context.Context) error {
+	if _, err := s.client.Refresh(ctx, &vaultpb.RefreshRequest{}); err != nil {
+		return fmt.Errorf("grpcclient: refresh: %w", fromStatus(err))
+	}
+	return nil
+}
+
+// fromStatus wraps a gRPC status error with the vault error its code
+// stands for, if any.
+func fromStatus(err error) error {
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %w", vault.ErrNotFound, err)
+	case codes.FailedPrecondition:
+		return fmt.Errorf("%w: %w", vault.ErrReadOnly, err)
+	case codes.Unavailable:
+		return fmt.Errorf("%w: %w", vault.ErrBackendUnavailable, err)
+	case codes.Canceled:
+		return fmt.Errorf("%w: %w", context.Canceled, err)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,138 @@
+package grpcclient_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/grpcclient"
+	"github.com/bjaus/vault/grpcserver"
+)
+
+// serve runs a gRPC server for v on an in-memory listener and returns a
+// client store connected to it.
+func serve(t *testing.T, v vault.Vault) *grpcclient.Store {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpcserver.New(v).Register(srv)
+	go srv.Serve(lis) //nolint:errcheck // returns once the server is stopped
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() }) //nolint:errcheck // test cleanup
+	return grpcclient.New(conn)
+}
+
+func TestStore_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := serve(t, vault.New())
+
+	created := time.Now()
+	want := vault.Entry{
+		Key:         "db-host",
+		Value:       "db.internal",
+		CreatedAt:   created,
+		Source:      "manual",
+		ReadOnly:    true,
+		RotateEvery: time.Hour,
+		ExpiresAt:   created.Add(24 * time.Hour),
+		Tags:        map[string]string{"env": "prod"},
+		Metadata:    map[string]string{"owner": "ops"},
+	}
+	require.NoError(t, s.Set(ctx, want))
+
+	got, err := s.Get(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, want.Value, got.Value)
+	assert.Equal(t, want.Source, got.Source)
+	assert.True(t, got.ReadOnly)
+	assert.Equal(t, time.Hour, got.RotateEvery)
+	assert.True(t, want.ExpiresAt.Equal(got.ExpiresAt))
+	assert.True(t, got.LastRotated.IsZero())
+	assert.Equal(t, want.Tags, got.Tags)
+	assert.Equal(t, want.Metadata, got.Metadata)
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "db-host", entries[0].Key)
+
+	err = s.Set(ctx, vault.Entry{Key: "db-host", Value: "other"})
+	require.ErrorIs(t, err, vault.ErrReadOnly)
+}
+
+func TestStore_notFound(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := serve(t, vault.New())
+
+	_, err := s.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, s.Delete(ctx, "k"))
+	_, err = s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_asVaultStore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	remote := vault.New(vault.WithSource(vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "api-key", Value: "sk-1"}}, nil
+	})))
+	s := serve(t, remote)
+
+	require.NoError(t, s.Refresh(ctx))
+	got, err := remote.Get(ctx, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", got.Value)
+
+	local := vault.New(vault.WithStore(s))
+	e, err := local.Get(ctx, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", e.Value)
+
+	require.NoError(t, local.Set(ctx, vault.Entry{Key: "token", Value: "t"}))
+	e, err = remote.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "t", e.Value)
+
+	_, err = local.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_unavailable(t *testing.T) {
+	t.Parallel()
+
+	lis := bufconn.Listen(1 << 10)
+	require.NoError(t, lis.Close())
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() }) //nolint:errcheck // test cleanup
+
+	_, err = grpcclient.New(conn).Get(context.Background(), "k")
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+}
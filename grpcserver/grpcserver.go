@@ -0,0 +1,106 @@
+// Package grpcserver serves a [vault.Vault] over gRPC, so that a vault
+// running as a sidecar can be queried by other processes. The service is
+// defined in vaultpb/vault.proto; package grpcclient provides the
+// matching [vault.Store].
+//
+// Errors are returned as gRPC statuses: [vault.ErrNotFound] becomes
+// codes.NotFound, [vault.ErrReadOnly] codes.FailedPrecondition,
+// [vault.ErrBackendUnavailable] codes.Unavailable, and context errors
+// their usual codes. Anything else is reported as codes.Unknown.
+package grpcserver
+
+import (
+	"context"
+	"errors"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/grpcserver/vaultpb"
+)
+
+// Server implements [vaultpb.VaultServer] on top of a [vault.Vault].
+type Server struct {
+	vaultpb.UnimplementedVaultServer
+
+	v vault.Vault
+}
+
+// New creates a [Server] backed by v.
+func New(v vault.Vault) *Server {
+	return &Server{v: v}
+}
+
+// Register registers the server with r, typically a [grpc.Server].
+func (s *Server) Register(r grpc.ServiceRegistrar) {
+	vaultpb.RegisterVaultServer(r, s)
+}
+
+// Get returns the entry stored under the requested key.
+func (s *Server) Get(ctx context.Context, req *vaultpb.GetRequest) (*vaultpb.GetResponse, error) {
+	e, err := s.v.Get(ctx, req.GetKey())
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	return &vaultpb.GetResponse{Entry: vaultpb.FromEntry(e)}, nil
+}
+
+// Set stores the requested entry.
+func (s *Server) Set(ctx context.Context, req *vaultpb.SetRequest) (*vaultpb.SetResponse, error) {
+	if req.GetEntry() == nil {
+		return nil, status.Error(codes.InvalidArgument, "grpcserver: set: missing entry")
+	}
+	if err := s.v.Set(ctx, vaultpb.ToEntry(req.GetEntry())); err != nil {
+		return nil, toStatus(err)
+	}
+	return &vaultpb.SetResponse{}, nil
+}
+
+// Delete removes the entry stored under the requested key.
+func (s *Server) Delete(ctx context.Context, req *vaultpb.DeleteRequest) (*vaultpb.DeleteResponse, error) {
+	if err := s.v.Delete(ctx, req.GetKey()); err != nil {
+		return nil, toStatus(err)
+	}
+	return &vaultpb.DeleteResponse{}, nil
+}
+
+// List returns every entry in the vault.
+func (s *Server) List(ctx context.Context, _ *vaultpb.ListRequest) (*vaultpb.ListResponse, error) {
+	entries, err := s.v.List(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+	resp := &vaultpb.ListResponse{Entries: make([]*vaultpb.Entry, len(entries))}
+	for i, e := range entries {
+		resp.Entries[i] = vaultpb.FromEntry(e)
+	}
+	return resp, nil
+}
+
+// Refresh refreshes the vault from its sources.
+func (s *Server) Refresh(ctx context.Context, _ *vaultpb.RefreshRequest) (*vaultpb.RefreshResponse, error) {
+	if err := s.v.Refresh(ctx); err != nil {
+		return nil, toStatus(err)
+	}
+	return &vaultpb.RefreshResponse{}, nil
+}
+
+// toStatus converts a vault error to a gRPC status error.
+func toStatus(err error) error {
+	var code codes.Code
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return status.FromContextError(err).Err()
+	case errors.Is(err, vault.ErrNotFound):
+		code = codes.NotFound
+	case errors.Is(err, vault.ErrReadOnly):
+		code = codes.FailedPrecondition
+	case errors.Is(err, vault.ErrBackendUnavailable):
+		code = codes.Unavailable
+	default:
+		code = codes.Unknown
+	}
+	return status.Error(code, err.Error())
+}
@@ -0,0 +1,79 @@
+package grpcserver_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/grpcserver"
+	"github.com/bjaus/vault/grpcserver/vaultpb"
+)
+
+func dial(t *testing.T, v vault.Vault) vaultpb.VaultClient {
+	t.Helper()
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpcserver.New(v).Register(srv)
+	go srv.Serve(lis) //nolint:errcheck // returns once the server is stopped
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() }) //nolint:errcheck // test cleanup
+	return vaultpb.NewVaultClient(conn)
+}
+
+func TestServer_codes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errDown := errors.New("down")
+	v := vault.New(
+		vault.WithReadOnly(),
+		vault.WithSource(vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errDown })),
+	)
+	c := dial(t, v)
+
+	_, err := c.Set(ctx, &vaultpb.SetRequest{Entry: &vaultpb.Entry{Key: "k"}})
+	assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = c.Set(ctx, &vaultpb.SetRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = c.Refresh(ctx, &vaultpb.RefreshRequest{})
+	assert.Equal(t, codes.Unknown, status.Code(err))
+	assert.Contains(t, status.Convert(err).Message(), "down")
+}
+
+func TestServer_notFound(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	c := dial(t, vault.New())
+
+	_, err := c.Get(ctx, &vaultpb.GetRequest{Key: "missing"})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+
+	_, err = c.Set(ctx, &vaultpb.SetRequest{Entry: &vaultpb.Entry{Key: "k", Value: "v"}})
+	require.NoError(t, err)
+
+	resp, err := c.List(ctx, &vaultpb.ListRequest{})
+	require.NoError(t, err)
+	require.Len(t, resp.GetEntries(), 1)
+	assert.Equal(t, "v", resp.GetEntries()[0].GetValue())
+	assert.NotNil(t, resp.GetEntries()[0].GetCreatedAt())
+}
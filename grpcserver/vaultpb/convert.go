@@ -0,0 +1,65 @@
+package vaultpb
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/bjaus/vault"
+)
+
+// FromEntry converts e to its wire form. Zero times and durations are left
+// unset.
+func FromEntry(e vault.Entry) *Entry {
+	out := &Entry{
+		Key:         e.Key,
+		Value:       e.Value,
+		CreatedAt:   timestamp(e.CreatedAt),
+		Source:      e.Source,
+		ReadOnly:    e.ReadOnly,
+		LastRotated: timestamp(e.LastRotated),
+		ExpiresAt:   timestamp(e.ExpiresAt),
+		Tags:        e.Tags,
+		Signature:   e.Signature,
+		Metadata:    e.Metadata,
+	}
+	if e.RotateEvery != 0 {
+		out.RotateEvery = durationpb.New(e.RotateEvery)
+	}
+	return out
+}
+
+// ToEntry converts e from its wire form. A nil e yields the zero entry.
+func ToEntry(e *Entry) vault.Entry {
+	out := vault.Entry{
+		Key:         e.GetKey(),
+		Value:       e.GetValue(),
+		CreatedAt:   fromTimestamp(e.GetCreatedAt()),
+		Source:      e.GetSource(),
+		ReadOnly:    e.GetReadOnly(),
+		LastRotated: fromTimestamp(e.GetLastRotated()),
+		ExpiresAt:   fromTimestamp(e.GetExpiresAt()),
+		Tags:        e.GetTags(),
+		Signature:   e.GetSignature(),
+		Metadata:    e.GetMetadata(),
+	}
+	if d := e.GetRotateEvery(); d != nil {
+		out.RotateEvery = d.AsDuration()
+	}
+	return out
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
@@ -0,0 +1,6 @@
+// Package vaultpb holds the protobuf messages and gRPC stubs for the vault
+// service defined in vault.proto, along with conversions to and from
+// [vault.Entry]. Everything but this file and convert.go is generated.
+package vaultpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative vault.proto
@@ -0,0 +1,106 @@
+// Package hashicorpsource implements a [vault.Source] that reads a KV
+// version 2 secret from HashiCorp Vault using the official API client.
+package hashicorpsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+
+	"github.com/bjaus/vault"
+)
+
+// ErrPermissionDenied is returned, wrapped, when HashiCorp Vault rejects
+// the client's credentials for the secret.
+var ErrPermissionDenied = errors.New("hashicorpsource: permission denied")
+
+// ErrNotKVv2 is returned, wrapped, when the response at the path does not
+// have the KV version 2 "data"/"metadata" shape.
+var ErrNotKVv2 = errors.New("hashicorpsource: not a KV v2 secret")
+
+// Source is a [vault.Source] over one KV v2 secret. It implements
+// [vault.Named] as "hashicorp:<path>".
+type Source struct {
+	client *api.Client
+	path   string
+}
+
+// New creates a [Source] that reads the secret at path using client. path
+// is the full API path of the KV v2 data endpoint, including the mount and
+// the "data" segment, e.g. "secret/data/myapp".
+func New(client *api.Client, path string) *Source {
+	return &Source{client: client, path: path}
+}
+
+// Name returns "hashicorp:" followed by the secret path.
+func (s *Source) Name() string { return "hashicorp:" + s.path }
+
+// Fetch reads the latest version of the secret and returns one entry per
+// field. CreatedAt is taken from the version's metadata when present.
+// Non-string field values are encoded as JSON.
+func (s *Source) Fetch(ctx context.Context) ([]vault.Entry, error) {
+	secret, err := s.client.Logical().ReadWithContext(ctx, s.path)
+	if err != nil {
+		var respErr *api.ResponseError
+		if errors.As(err, &respErr) &&
+			(respErr.StatusCode == http.StatusUnauthorized || respErr.StatusCode == http.StatusForbidden) {
+			return nil, fmt.Errorf("hashicorpsource: read %s: %w: %w", s.path, ErrPermissionDenied, err)
+		}
+		return nil, fmt.Errorf("hashicorpsource: read %s: %w", s.path, err)
+	}
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("hashicorpsource: read %s: %w", s.path, vault.ErrNotFound)
+	}
+
+	data, ok := secret.Data["data"].(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("hashicorpsource: read %s: %w", s.path, ErrNotKVv2)
+	}
+
+	created := createdTime(secret.Data["metadata"])
+	name := s.Name()
+
+	entries := make([]vault.Entry, 0, len(data))
+	for key, raw := range data {
+		value, err := stringify(raw)
+		if err != nil {
+			return nil, fmt.Errorf("hashicorpsource: read %s: field %q: %w", s.path, key, err)
+		}
+		entries = append(entries, vault.Entry{Key: key, Value: value, CreatedAt: created, Source: name})
+	}
+	return entries, nil
+}
+
+// createdTime extracts created_time from KV v2 version metadata, returning
+// the zero time if it is absent or malformed.
+func createdTime(metadata any) time.Time {
+	m, ok := metadata.(map[string]any)
+	if !ok {
+		return time.Time{}
+	}
+	s, ok := m["created_time"].(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+func stringify(v any) (string, error) {
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
@@ -0,0 +1,109 @@
+package hashicorpsource_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/hashicorpsource"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// newClient returns a client whose requests are answered by respond.
+func newClient(t *testing.T, respond func(*http.Request) (int, string)) *api.Client {
+	t.Helper()
+
+	cfg := api.DefaultConfig()
+	cfg.Address = "http://hashicorp.test"
+	cfg.MaxRetries = 0
+	cfg.HttpClient = &http.Client{Transport: roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		status, body := respond(r)
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": []string{"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	})}
+
+	client, err := api.NewClient(cfg)
+	require.NoError(t, err)
+	client.SetToken("test-token")
+	return client
+}
+
+func TestSource_Fetch(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(r *http.Request) (int, string) {
+		assert.Equal(t, "/v1/secret/data/myapp", r.URL.Path)
+		assert.Equal(t, "test-token", r.Header.Get("X-Vault-Token"))
+		return http.StatusOK, `{
+			"data": {
+				"data": {"db-password": "hunter2", "port": 5432},
+				"metadata": {"created_time": "2024-03-01T12:00:00.5Z", "version": 3}
+			}
+		}`
+	})
+
+	src := hashicorpsource.New(client, "secret/data/myapp")
+	assert.Equal(t, "hashicorp:secret/data/myapp", src.Name())
+
+	entries, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	got := make(map[string]string)
+	for _, e := range entries {
+		got[e.Key] = e.Value
+		assert.Equal(t, "hashicorp:secret/data/myapp", e.Source)
+		assert.Equal(t, time.Date(2024, 3, 1, 12, 0, 0, 5e8, time.UTC), e.CreatedAt)
+	}
+	assert.Equal(t, map[string]string{"db-password": "hunter2", "port": "5432"}, got)
+}
+
+func TestSource_Fetch_permissionDenied(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(_ *http.Request) (int, string) {
+		return http.StatusForbidden, `{"errors": ["permission denied"]}`
+	})
+
+	_, err := hashicorpsource.New(client, "secret/data/myapp").Fetch(context.Background())
+	require.ErrorIs(t, err, hashicorpsource.ErrPermissionDenied)
+	assert.Contains(t, err.Error(), "secret/data/myapp")
+}
+
+func TestSource_Fetch_notFound(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(_ *http.Request) (int, string) {
+		return http.StatusNotFound, `{"errors": []}`
+	})
+
+	_, err := hashicorpsource.New(client, "secret/data/missing").Fetch(context.Background())
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.NotErrorIs(t, err, hashicorpsource.ErrPermissionDenied)
+}
+
+func TestSource_Fetch_notKVv2(t *testing.T) {
+	t.Parallel()
+
+	client := newClient(t, func(_ *http.Request) (int, string) {
+		return http.StatusOK, `{"data": {"password": "flat"}}`
+	})
+
+	_, err := hashicorpsource.New(client, "kv/myapp").Fetch(context.Background())
+	require.ErrorIs(t, err, hashicorpsource.ErrNotKVv2)
+}
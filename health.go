@@ -0,0 +1,38 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// healthProbeKey is read by [Vault.HealthCheck] to exercise the store. It
+// is not expected to exist.
+const healthProbeKey = "__vault_health_probe__"
+
+// HealthCheck reports whether the vault's backends are reachable, for use
+// in readiness probes. It reads a probe key from the store, treating
+// [ErrNotFound] as success, and calls [HealthChecker.HealthCheck] on each
+// source that implements it. Sources that do not are assumed healthy;
+// they are never fetched. All failures are joined into the returned
+// error, so one unreachable source does not hide another.
+func (v *vault) HealthCheck(ctx context.Context) error {
+	if err := v.checkOpen(); err != nil {
+		return err
+	}
+
+	var errs []error
+	if _, err := v.cache.Get(ctx, healthProbeKey); err != nil && !errors.Is(err, ErrNotFound) {
+		errs = append(errs, fmt.Errorf("vault: health: store: %w", err))
+	}
+
+	for i, src := range v.sources {
+		if m, ok := src.(*mountedSource); ok {
+			src = m.Source
+		}
+		if err := healthCheck(ctx, src); err != nil {
+			errs = append(errs, fmt.Errorf("vault: health: %s: %w", sourceName(i, src), err))
+		}
+	}
+	return errors.Join(errs...)
+}
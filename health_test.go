@@ -0,0 +1,65 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+// pingSource is a Source whose health check returns err. Fetching it
+// fails the test.
+type pingSource struct {
+	t   *testing.T
+	err error
+}
+
+func (p *pingSource) Fetch(_ context.Context) ([]vault.Entry, error) {
+	p.t.Error("health check must not fetch")
+	return nil, nil
+}
+
+func (p *pingSource) HealthCheck(_ context.Context) error { return p.err }
+
+func TestHealthCheck_healthy(t *testing.T) {
+	t.Parallel()
+
+	plain := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		t.Error("health check must not fetch")
+		return nil, nil
+	})
+	v := vault.New(vault.WithSource(plain), vault.WithSource(&pingSource{t: t}))
+
+	require.NoError(t, v.HealthCheck(context.Background()))
+}
+
+func TestHealthCheck_failingStore(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New(vault.WithStore(&failStore{err: vault.ErrBackendUnavailable}))
+
+	err := v.HealthCheck(context.Background())
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+	assert.Contains(t, err.Error(), "store")
+}
+
+func TestHealthCheck_joinsFailures(t *testing.T) {
+	t.Parallel()
+
+	errA, errB := errors.New("a unreachable"), errors.New("b unreachable")
+	v := vault.New(
+		vault.WithStore(&failStore{err: vault.ErrBackendUnavailable}),
+		vault.WithSource(vault.NamedSource("a", &pingSource{t: t, err: errA})),
+		vault.WithSource(vault.NewMultiSource(&pingSource{t: t}, vault.NamedSource("b", &pingSource{t: t, err: errB}))),
+	)
+
+	err := v.HealthCheck(context.Background())
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, errB)
+	assert.Contains(t, err.Error(), "vault: health: a: a unreachable")
+}
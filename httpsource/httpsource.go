@@ -0,0 +1,204 @@
+// Package httpsource implements a [vault.Source] that reads entries from
+// a JSON HTTP endpoint.
+//
+// The endpoint must respond to GET with either an object of string
+// values, {"db-host": "db.internal"}, or an array of objects with "key"
+// and "value" fields, [{"key": "db-host", "value": "db.internal"}].
+package httpsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/bjaus/vault"
+)
+
+// maxErrorBody bounds how much of a non-2xx response body is included in
+// the returned error.
+const maxErrorBody = 512
+
+// defaultMaxBody bounds the size of a response body unless
+// [WithMaxBodySize] says otherwise.
+const defaultMaxBody = 10 << 20
+
+// ErrStatus is returned, wrapped with the status code and the start of
+// the response body, when the endpoint responds with a non-2xx status.
+var ErrStatus = errors.New("httpsource: unexpected status")
+
+// ErrShape is returned, wrapped, when the response body is neither a JSON
+// object of strings nor an array of key/value objects.
+var ErrShape = errors.New("httpsource: unsupported response shape")
+
+// ErrTooLarge is returned, wrapped, when the response body exceeds the
+// limit set with [WithMaxBodySize].
+var ErrTooLarge = errors.New("httpsource: response body too large")
+
+// Source is a [vault.Source] over a JSON endpoint. The endpoint is
+// requested on every Fetch. It implements [vault.Named] using the URL,
+// with any password in it redacted.
+type Source struct {
+	url     string
+	name    string
+	client  *http.Client
+	header  http.Header
+	maxBody int64
+}
+
+// Option configures a [Source].
+type Option func(*Source)
+
+// WithHeader adds a header to every request, e.g. an Authorization token.
+// Repeated calls with the same name add further values.
+func WithHeader(name, value string) Option {
+	return func(s *Source) { s.header.Add(name, value) }
+}
+
+// WithHTTPClient sets the client used for requests, for custom transports
+// or timeouts. The default is [http.DefaultClient].
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Source) { s.client = client }
+}
+
+// WithMaxBodySize bounds how many bytes of a successful response body are
+// read. A larger body fails the fetch with [ErrTooLarge]. The default is
+// 10 MiB; n <= 0 keeps it. Error responses are reported with [ErrStatus]
+// whatever their size.
+func WithMaxBodySize(n int64) Option {
+	return func(s *Source) {
+		if n > 0 {
+			s.maxBody = n
+		}
+	}
+}
+
+// New creates a [Source] for the endpoint at rawURL.
+func New(rawURL string, opts ...Option) *Source {
+	s := &Source{
+		url:     rawURL,
+		name:    redact(rawURL),
+		client:  http.DefaultClient,
+		header:  make(http.Header),
+		maxBody: defaultMaxBody,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Name returns the endpoint URL with any password replaced by "xxxxx".
+func (s *Source) Name() string { return s.name }
+
+// redact hides the password in rawURL, so that it stays out of names,
+// errors and [vault.Entry.Source].
+func redact(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Redacted()
+}
+
+// Fetch requests the endpoint and returns one entry per key, with Source
+// set to [Source.Name]. The request is bound to ctx, so its deadline applies.
+// A token attached with [vault.WithCredentials] is sent as a bearer
+// Authorization header.
+func (s *Source) Fetch(ctx context.Context) ([]vault.Entry, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("httpsource: get %s: %w", s.name, err)
+	}
+	for name, values := range s.header {
+		req.Header[name] = slices.Clone(values)
+	}
+	req.Header.Set("Accept", "application/json")
+	if creds, ok := vault.CredentialsFromContext(ctx); ok && creds.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+creds.Token)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("httpsource: get %s: %w", s.name, err)
+	}
+	defer resp.Body.Close() //nolint:errcheck // nothing is written to the body
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Only the start of an error body is reported, so read no more.
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody+1)) //nolint:errcheck // the status is the error
+		return nil, fmt.Errorf("httpsource: get %s: %w %d: %s", s.name, ErrStatus, resp.StatusCode, truncate(body))
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, s.maxBody+1))
+	if err != nil {
+		return nil, fmt.Errorf("httpsource: get %s: %w", s.name, err)
+	}
+	if int64(len(body)) > s.maxBody {
+		return nil, fmt.Errorf("httpsource: get %s: %w: over %d bytes", s.name, ErrTooLarge, s.maxBody)
+	}
+
+	entries, err := decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("httpsource: get %s: %w", s.name, err)
+	}
+	for i := range entries {
+		entries[i].Source = s.name
+	}
+	return entries, nil
+}
+
+// decode parses either supported response shape. Object keys are returned
+// sorted; array entries keep their order.
+func decode(body []byte) ([]vault.Entry, error) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return nil, ErrShape
+	}
+
+	switch trimmed[0] {
+	case '{':
+		var values map[string]string
+		if err := json.Unmarshal(trimmed, &values); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrShape, err)
+		}
+		entries := make([]vault.Entry, 0, len(values))
+		for _, key := range slices.Sorted(maps.Keys(values)) {
+			entries = append(entries, vault.Entry{Key: key, Value: values[key]})
+		}
+		return entries, nil
+
+	case '[':
+		var items []struct {
+			Key   string `json:"key"`
+			Value string `json:"value"`
+		}
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrShape, err)
+		}
+		entries := make([]vault.Entry, 0, len(items))
+		for _, it := range items {
+			entries = append(entries, vault.Entry{Key: it.Key, Value: it.Value})
+		}
+		return entries, nil
+	}
+
+	return nil, ErrShape
+}
+
+// truncate returns the start of body as a single trimmed string, marking
+// it with "..." if anything was cut.
+func truncate(body []byte) string {
+	s := strings.TrimSpace(string(body))
+	if len(s) <= maxErrorBody {
+		return s
+	}
+	return strings.ToValidUTF8(s[:maxErrorBody], "") + "..."
+}
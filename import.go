@@ -0,0 +1,139 @@
+package vault
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	errImportShape = errors.New("want a mapping of keys to values or a list of entries")
+	errImportKey   = errors.New("entry has no key")
+)
+
+// ImportJSON reads a JSON document from r and stores its entries in v,
+// returning how many were stored. The document is either an object
+// mapping keys to string values or an array of [Entry] objects.
+//
+// Entries without a source get Source "import", and those without a
+// creation time are stamped as by [Vault.Set]. The whole document is
+// decoded before anything is stored, so a malformed document stores
+// nothing.
+func ImportJSON(ctx context.Context, v Vault, r io.Reader) (int, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return 0, fmt.Errorf("vault: import: %w", err)
+	}
+
+	var entries []Entry
+	switch trimmed := bytes.TrimSpace(data); {
+	case bytes.HasPrefix(trimmed, []byte("{")):
+		var flat map[string]string
+		if err := json.Unmarshal(trimmed, &flat); err != nil {
+			return 0, fmt.Errorf("vault: import: %w", err)
+		}
+		entries = flatEntries(flat)
+	case bytes.HasPrefix(trimmed, []byte("[")):
+		if err := json.Unmarshal(trimmed, &entries); err != nil {
+			return 0, fmt.Errorf("vault: import: %w", err)
+		}
+	default:
+		return 0, fmt.Errorf("vault: import: %w", errImportShape)
+	}
+
+	return importEntries(ctx, v, entries)
+}
+
+// ImportYAML is like [ImportJSON] for a YAML document. Entry fields use
+// the same names as in JSON, such as created_at, and durations may be
+// written as strings like "720h".
+func ImportYAML(ctx context.Context, v Vault, r io.Reader) (int, error) {
+	var doc yaml.Node
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil && !errors.Is(err, io.EOF) {
+		return 0, fmt.Errorf("vault: import: %w", err)
+	}
+	if doc.Kind == 0 {
+		return 0, nil // empty document
+	}
+
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) == 1 {
+		root = root.Content[0]
+	}
+
+	var entries []Entry
+	switch root.Kind {
+	case yaml.MappingNode:
+		var flat map[string]string
+		if err := root.Decode(&flat); err != nil {
+			return 0, fmt.Errorf("vault: import: %w", err)
+		}
+		entries = flatEntries(flat)
+	case yaml.SequenceNode:
+		var docs []yamlEntry
+		if err := root.Decode(&docs); err != nil {
+			return 0, fmt.Errorf("vault: import: %w", err)
+		}
+		entries = make([]Entry, len(docs))
+		for i, d := range docs {
+			entries[i] = Entry(d)
+		}
+	default:
+		return 0, fmt.Errorf("vault: import: %w", errImportShape)
+	}
+
+	return importEntries(ctx, v, entries)
+}
+
+// yamlEntry mirrors [Entry] with YAML field names matching its JSON ones.
+type yamlEntry struct {
+	Key         string        `yaml:"key"`
+	Value       string        `yaml:"value"`
+	CreatedAt   time.Time     `yaml:"created_at"`
+	Source      string        `yaml:"source"`
+	ReadOnly    bool          `yaml:"read_only,omitempty"`
+	RotateEvery time.Duration `yaml:"rotate_every,omitempty"`
+	LastRotated time.Time     `yaml:"last_rotated,omitempty"`
+	ExpiresAt   time.Time     `yaml:"expires_at,omitempty"`
+
+	Tags     map[string]string `yaml:"tags,omitempty"`
+	Metadata map[string]string `yaml:"metadata,omitempty"`
+
+	Signature string `yaml:"signature,omitempty"`
+}
+
+// flatEntries converts a key/value mapping to entries, sorted by key.
+func flatEntries(flat map[string]string) []Entry {
+	entries := make([]Entry, 0, len(flat))
+	for _, k := range slices.Sorted(maps.Keys(flat)) {
+		entries = append(entries, Entry{Key: k, Value: flat[k]})
+	}
+	return entries
+}
+
+// importEntries checks entries, then stores each in v.
+func importEntries(ctx context.Context, v Vault, entries []Entry) (int, error) {
+	for i, e := range entries {
+		if e.Key == "" {
+			return 0, fmt.Errorf("vault: import: entry %d: %w", i, errImportKey)
+		}
+	}
+
+	for i, e := range entries {
+		if e.Source == "" {
+			e.Source = "import"
+		}
+		if err := v.Set(ctx, e); err != nil {
+			return i, fmt.Errorf("vault: import: %w", err)
+		}
+	}
+	return len(entries), nil
+}
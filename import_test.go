@@ -0,0 +1,138 @@
+package vault_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestImportJSON_flat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+
+	n, err := vault.ImportJSON(ctx, v, strings.NewReader(`{"db-host": "localhost", "db-port": "5432"}`))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := v.Get(ctx, "db-port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", got.Value)
+	assert.Equal(t, "import", got.Source)
+	assert.False(t, got.CreatedAt.IsZero())
+}
+
+func TestImportJSON_entries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+
+	doc := `[
+		{"key": "token", "value": "abc", "source": "ssm", "created_at": "2024-01-02T15:04:05Z", "read_only": true},
+		{"key": "plain", "value": "x"}
+	]`
+	n, err := vault.ImportJSON(ctx, v, strings.NewReader(doc))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := v.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "ssm", got.Source)
+	assert.True(t, got.ReadOnly)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), got.CreatedAt.UTC())
+
+	got, err = v.Get(ctx, "plain")
+	require.NoError(t, err)
+	assert.Equal(t, "import", got.Source)
+}
+
+func TestImportJSON_malformedStoresNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, doc := range []string{
+		`{"a": "1", "b": `,
+		`{"a": 1}`,
+		`[{"key": "a", "value": "1"}, {"value": "no key"}]`,
+		`"just a string"`,
+	} {
+		v := vault.New()
+		n, err := vault.ImportJSON(ctx, v, strings.NewReader(doc))
+		require.Error(t, err, doc)
+		assert.Zero(t, n, doc)
+
+		entries, err := v.List(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, entries, doc)
+	}
+}
+
+func TestImportYAML_flat(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+
+	n, err := vault.ImportYAML(ctx, v, strings.NewReader("db-host: localhost\ndb-port: 5432\n"))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := v.Get(ctx, "db-port")
+	require.NoError(t, err)
+	assert.Equal(t, "5432", got.Value)
+	assert.Equal(t, "import", got.Source)
+}
+
+func TestImportYAML_entries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+
+	doc := `
+- key: token
+  value: abc
+  source: ssm
+  created_at: 2024-01-02T15:04:05Z
+- key: plain
+  value: x
+`
+	n, err := vault.ImportYAML(ctx, v, strings.NewReader(doc))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := v.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "abc", got.Value)
+	assert.Equal(t, "ssm", got.Source)
+	assert.Equal(t, time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC), got.CreatedAt.UTC())
+}
+
+func TestImportYAML_malformedStoresNothing(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for _, doc := range []string{
+		"a: [unterminated",
+		"- key: a\n  value: 1\n- value: no key\n",
+		"just a string",
+	} {
+		v := vault.New()
+		_, err := vault.ImportYAML(ctx, v, strings.NewReader(doc))
+		require.Error(t, err, doc)
+
+		entries, err := v.List(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, entries, doc)
+	}
+}
@@ -0,0 +1,20 @@
+package keychain
+
+import "testing"
+
+// CountIndexWrites counts writes of the index header for service until
+// the test ends. The header is written once per index update.
+func CountIndexWrites(t *testing.T, service string) *int {
+	t.Helper()
+
+	n := new(int)
+	orig := keyringSet
+	keyringSet = func(svc, key, value string) error {
+		if svc == service && key == indexKey {
+			*n++
+		}
+		return orig(svc, key, value)
+	}
+	t.Cleanup(func() { keyringSet = orig })
+	return n
+}
@@ -3,8 +3,16 @@
 // the Secret Service API, and on Windows the Credential Manager.
 //
 // An index entry is maintained alongside stored values so that [Store.List]
-// works across all platforms. The index is stored under a reserved key
-// within the same keyring service.
+// works across all platforms. The index is stored under reserved keys
+// within the same keyring service: a small header under "__vault_index__"
+// and the key list itself split across "__vault_index__0",
+// "__vault_index__1", and so on, so that large namespaces stay within
+// platform size limits.
+//
+// The index is read from the keyring once per service and then cached in
+// memory, shared by every [Store] in the process that uses the service.
+// Writes go through to the keyring. Call [Store.Reload] if another process
+// may have changed the index.
 package keychain
 
 import (
@@ -12,6 +20,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/bjaus/vault"
@@ -19,16 +31,55 @@ import (
 )
 
 const (
-	defaultService = "vault"
-	indexKey        = "__vault_index__"
+	defaultService   = "vault"
+	defaultChunkSize = 100
+	indexKey         = "__vault_index__"
 )
 
+// keyringSet is [keyring.Set], replaceable in tests to observe writes.
+var keyringSet = keyring.Set
+
+// ErrCorrupt is returned, wrapped along with the decoding error, when a
+// stored value is not a valid entry, for example because another tool
+// wrote it or it was truncated.
+var ErrCorrupt = errors.New("keychain: corrupt entry")
+
+// ErrNoEnumerator is returned by [Store.Rebuild] when no enumerator was
+// set with [WithRebuildIndex].
+var ErrNoEnumerator = errors.New("keychain: no key enumerator")
+
+// ErrReservedKey is returned, wrapped, by [Store.Set] and [Store.Delete]
+// for keys beginning with "__vault_index__", which hold the key index.
+var ErrReservedKey = errors.New("keychain: reserved key")
+
 // Store is a [vault.Store] backed by the system keychain. It implements
 // [vault.Namespaced] — calling [Store.WithNamespace] returns a store
 // scoped to a different keyring service name.
 type Store struct {
-	service string
-	mu      sync.Mutex // serializes index updates
+	service     string
+	chunkSize   int
+	skipCorrupt bool
+	logger      *slog.Logger
+	index       *indexCache
+	enumerate   func() ([]string, error)
+}
+
+// indexCache is the in-memory copy of one service's key index.
+type indexCache struct {
+	mu     sync.Mutex // serializes index reads and updates
+	keys   []string
+	loaded bool
+}
+
+// indexCaches maps each keyring service name to its *indexCache, so that
+// stores sharing a service, such as those returned by
+// [Store.WithNamespace], never see different versions of the index.
+var indexCaches sync.Map
+
+func cacheFor(service string) *indexCache {
+	v, _ := indexCaches.LoadOrStore(service, &indexCache{})
+	c, _ := v.(*indexCache) //nolint:errcheck // only *indexCache is stored
+	return c
 }
 
 // Option configures a keychain [Store].
@@ -39,72 +90,229 @@ func WithService(name string) Option {
 	return func(s *Store) { s.service = name }
 }
 
+// WithIndexChunkSize sets how many keys are stored in each chunk of the
+// key index. The default is 100. Lower it if index writes fail with
+// [keyring.ErrSetDataTooBig] on platforms with small item limits.
+func WithIndexChunkSize(n int) Option {
+	return func(s *Store) {
+		if n > 0 {
+			s.chunkSize = n
+		}
+	}
+}
+
+// WithSkipCorrupt makes [Store.List] skip entries that cannot be decoded,
+// logging each one, instead of failing the whole call. [Store.Get] still
+// reports them with [ErrCorrupt].
+func WithSkipCorrupt(skip bool) Option {
+	return func(s *Store) { s.skipCorrupt = skip }
+}
+
+// WithLogger sets the logger used to report skipped corrupt entries and
+// failed index reads. By default nothing is logged.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *Store) {
+		if logger != nil {
+			s.logger = logger
+		}
+	}
+}
+
+// WithRebuildIndex sets a function that lists the keys stored under the
+// store's service by other means than the index, for example with a
+// platform tool such as security(1) on macOS or secret-tool(1) on Linux.
+// go-keyring cannot enumerate items on any platform, so there is no
+// default. With an enumerator set, an index that is missing or empty is
+// rebuilt from it the first time it is read, so [Store.List] finds
+// entries written by other tools, and [Store.Rebuild] is available. The
+// enumerator applies only to this store's service, not to stores returned
+// by [Store.WithNamespace].
+func WithRebuildIndex(enumerator func() ([]string, error)) Option {
+	return func(s *Store) { s.enumerate = enumerator }
+}
+
 // New creates a keychain-backed store.
 func New(opts ...Option) *Store {
-	s := &Store{service: defaultService}
+	s := &Store{
+		service:   defaultService,
+		chunkSize: defaultChunkSize,
+		logger:    slog.New(slog.DiscardHandler),
+	}
 	for _, opt := range opts {
 		opt(s)
 	}
+	s.index = cacheFor(s.service)
 	return s
 }
 
 // WithNamespace returns a [vault.Store] scoped to the given namespace.
-// The namespace is appended to the service name (e.g. "vault/prod").
+// The namespace is appended to the service name (e.g. "vault/prod"). It
+// panics if ns is not valid according to [vault.ValidateNamespace].
 func (s *Store) WithNamespace(ns string) vault.Store {
-	return &Store{service: s.service + "/" + ns}
+	if err := vault.ValidateNamespace(ns); err != nil {
+		panic(err)
+	}
+	service := s.service + "/" + ns
+	return &Store{
+		service:     service,
+		chunkSize:   s.chunkSize,
+		skipCorrupt: s.skipCorrupt,
+		logger:      s.logger,
+		index:       cacheFor(service),
+	}
 }
 
 // Get retrieves an entry by key from the keychain.
-func (s *Store) Get(_ context.Context, key string) (vault.Entry, error) {
-	data, err := keyring.Get(s.service, key)
-	if err != nil {
+func (s *Store) Get(ctx context.Context, key string) (vault.Entry, error) {
+	data, err := withContext(ctx, "get", key, func() (string, error) {
+		data, err := keyring.Get(s.service, key)
 		if errors.Is(err, keyring.ErrNotFound) {
-			return vault.Entry{}, vault.ErrNotFound
+			return "", &vault.StoreError{Op: "get", Key: key, Err: vault.ErrNotFound}
 		}
-		return vault.Entry{}, fmt.Errorf("keychain: get %q: %w", key, err)
+		if err != nil {
+			return "", storeErr("get", key, backendErr(err))
+		}
+		return data, nil
+	})
+	if err != nil {
+		return vault.Entry{}, err
 	}
 
 	var entry vault.Entry
 	if err := json.Unmarshal([]byte(data), &entry); err != nil {
-		return vault.Entry{}, fmt.Errorf("keychain: unmarshal %q: %w", key, err)
+		return vault.Entry{}, storeErr("decode", key, fmt.Errorf("%w: %w", ErrCorrupt, err))
 	}
 
 	return entry, nil
 }
 
-// Set stores an entry in the keychain and updates the key index.
-func (s *Store) Set(_ context.Context, entry vault.Entry) error {
-	data, err := json.Marshal(entry)
+// GetMany retrieves several entries. The keyring has no batch API, so
+// each key is read in turn; missing keys are absent from the result.
+func (s *Store) GetMany(ctx context.Context, keys []string) (map[string]vault.Entry, error) {
+	found := make(map[string]vault.Entry, len(keys))
+	for _, key := range keys {
+		e, err := s.Get(ctx, key)
+		if errors.Is(err, vault.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found[key] = e
+	}
+	return found, nil
+}
+
+// Exists reports whether key is present by consulting the key index, so
+// the stored value is never read from the keychain.
+func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
+	keys, err := s.lockedIndex(ctx, "exists", key)
+	if err != nil {
+		return false, err
+	}
+	return !reserved(key) && slices.Contains(keys, key), nil
+}
+
+// Keys returns the keys in the index, sorted, without reading any values
+// from the keychain.
+func (s *Store) Keys(ctx context.Context) ([]string, error) {
+	keys, err := s.lockedIndex(ctx, "keys", "")
 	if err != nil {
-		return fmt.Errorf("keychain: marshal %q: %w", entry.Key, err)
+		return nil, err
 	}
+	keys = slices.DeleteFunc(keys, reserved)
+	slices.Sort(keys)
+	return keys, nil
+}
 
-	if err := keyring.Set(s.service, entry.Key, string(data)); err != nil {
-		return fmt.Errorf("keychain: set %q: %w", entry.Key, err)
+// Set stores an entry in the keychain and updates the key index. If ctx
+// is cancelled first, Set returns its error while the write, once started,
+// completes in the background so the value and index stay consistent.
+func (s *Store) Set(ctx context.Context, entry vault.Entry) error {
+	if reserved(entry.Key) {
+		return &vault.StoreError{Op: "set", Key: entry.Key, Err: ErrReservedKey}
 	}
 
-	return s.addToIndex(entry.Key)
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return storeErr("marshal", entry.Key, err)
+	}
+
+	_, err = withContext(ctx, "set", entry.Key, func() (struct{}, error) {
+		if serr := keyringSet(s.service, entry.Key, string(data)); serr != nil {
+			return struct{}{}, storeErr("set", entry.Key, backendErr(serr))
+		}
+		return struct{}{}, s.addToIndex(entry.Key)
+	})
+	return err
 }
 
 // Delete removes an entry from the keychain and updates the key index.
-func (s *Store) Delete(_ context.Context, key string) error {
-	if err := keyring.Delete(s.service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
-		return fmt.Errorf("keychain: delete %q: %w", key, err)
+// Cancellation behaves as for [Store.Set].
+func (s *Store) Delete(ctx context.Context, key string) error {
+	if reserved(key) {
+		return &vault.StoreError{Op: "delete", Key: key, Err: ErrReservedKey}
 	}
-	return s.removeFromIndex(key)
+
+	_, err := withContext(ctx, "delete", key, func() (struct{}, error) {
+		if err := keyring.Delete(s.service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return struct{}{}, storeErr("delete", key, backendErr(err))
+		}
+		return struct{}{}, s.removeFromIndex(key)
+	})
+	return err
+}
+
+// DeleteMany removes several entries from the keychain, then updates the
+// key index once. Missing keys are not an error. Cancellation behaves as
+// for [Store.Set].
+func (s *Store) DeleteMany(ctx context.Context, keys []string) error {
+	for _, key := range keys {
+		if reserved(key) {
+			return &vault.StoreError{Op: "delete", Key: key, Err: ErrReservedKey}
+		}
+	}
+
+	_, err := withContext(ctx, "delete many", "", func() (struct{}, error) {
+		deleted := make([]string, 0, len(keys))
+		var derr error
+		for _, key := range keys {
+			if err := keyring.Delete(s.service, key); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+				derr = storeErr("delete", key, backendErr(err))
+				break
+			}
+			deleted = append(deleted, key)
+		}
+		// Drop whatever was deleted from the index even if a later key
+		// failed, so the index does not point at missing values.
+		return struct{}{}, errors.Join(derr, s.removeFromIndex(deleted...))
+	})
+	return err
 }
 
 // List returns all entries stored in the keychain by reading the key
-// index and fetching each entry individually.
+// index and fetching each entry individually. If ctx is cancelled, no
+// further entries are read.
 func (s *Store) List(ctx context.Context) ([]vault.Entry, error) {
-	keys := s.readIndex()
+	keys, err := s.lockedIndex(ctx, "list", "")
+	if err != nil {
+		return nil, err
+	}
 	entries := make([]vault.Entry, 0, len(keys))
 
 	for _, key := range keys {
+		if reserved(key) {
+			continue // never expose the index itself
+		}
 		e, err := s.Get(ctx, key)
 		if errors.Is(err, vault.ErrNotFound) {
 			continue // index is stale, skip
 		}
+		if s.skipCorrupt && errors.Is(err, ErrCorrupt) {
+			s.logger.WarnContext(ctx, "keychain: skipping corrupt entry",
+				"service", s.service, "key", key, "error", err)
+			continue
+		}
 		if err != nil {
 			return nil, err
 		}
@@ -114,55 +322,287 @@ func (s *Store) List(ctx context.Context) ([]vault.Entry, error) {
 	return entries, nil
 }
 
-func (s *Store) addToIndex(key string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// Reload discards the cached key index and reads it again from the
+// keyring, picking up changes made by other processes.
+func (s *Store) Reload(ctx context.Context) error {
+	_, err := withContext(ctx, "reload", "", func() (struct{}, error) {
+		s.index.mu.Lock()
+		defer s.index.mu.Unlock()
+		s.index.loaded = false
+		s.cachedIndex()
+		return struct{}{}, nil
+	})
+	return err
+}
 
-	keys := s.readIndex()
-	for _, k := range keys {
-		if k == key {
-			return nil
+// Rebuild replaces the key index with the keys reported by the
+// enumerator set with [WithRebuildIndex], skipping the index's own keys.
+// It returns [ErrNoEnumerator] if there is none.
+func (s *Store) Rebuild(ctx context.Context) error {
+	if s.enumerate == nil {
+		return ErrNoEnumerator
+	}
+
+	_, err := withContext(ctx, "rebuild", "", func() (struct{}, error) {
+		keys, err := s.enumerated()
+		if err != nil {
+			return struct{}{}, err
 		}
+
+		s.index.mu.Lock()
+		defer s.index.mu.Unlock()
+		return struct{}{}, s.updateIndex(keys)
+	})
+	return err
+}
+
+// enumerated returns the keys reported by the enumerator, sorted and
+// without duplicates or reserved keys.
+func (s *Store) enumerated() ([]string, error) {
+	keys, err := s.enumerate()
+	if err != nil {
+		return nil, storeErr("enumerate", "", err)
 	}
+	keys = slices.DeleteFunc(slices.Clone(keys), reserved)
+	slices.Sort(keys)
+	return slices.Compact(keys), nil
+}
 
-	return s.writeIndex(append(keys, key))
+// lockedIndex returns a copy of the key index, taken under the index lock
+// on behalf of op on key.
+func (s *Store) lockedIndex(ctx context.Context, op, key string) ([]string, error) {
+	return withContext(ctx, op, key, func() ([]string, error) {
+		s.index.mu.Lock()
+		defer s.index.mu.Unlock()
+		return slices.Clone(s.cachedIndex()), nil
+	})
 }
 
-func (s *Store) removeFromIndex(key string) error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+// cachedIndex returns the key index, reading it from the keyring if it is
+// not cached, or rebuilding it if it is empty and an enumerator is set.
+// An index that could not be read completely is returned but not cached.
+// The caller must hold s.index.mu and must not modify the result.
+func (s *Store) cachedIndex() []string {
+	if s.index.loaded {
+		return s.index.keys
+	}
+	keys, ok := s.readIndex()
+	if ok && len(keys) == 0 && s.enumerate != nil {
+		keys = s.rebuiltIndex()
+	}
+	if ok {
+		s.index.keys, s.index.loaded = keys, true
+	}
+	return keys
+}
+
+// updateIndex writes keys as the new index and caches them. If the write
+// fails, the cache is dropped, since the keyring may hold either version.
+// The caller must hold s.index.mu.
+func (s *Store) updateIndex(keys []string) error {
+	if err := s.writeIndex(keys); err != nil {
+		s.index.loaded = false
+		return err
+	}
+	s.index.keys, s.index.loaded = keys, true
+	return nil
+}
+
+func (s *Store) addToIndex(key string) error {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
 
-	keys := s.readIndex()
+	keys := s.cachedIndex()
+	if slices.Contains(keys, key) {
+		return nil
+	}
+
+	return s.updateIndex(append(slices.Clip(keys), key))
+}
+
+func (s *Store) removeFromIndex(remove ...string) error {
+	if len(remove) == 0 {
+		return nil
+	}
+
+	drop := make(map[string]bool, len(remove))
+	for _, k := range remove {
+		drop[k] = true
+	}
+
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	keys := s.cachedIndex()
 	filtered := make([]string, 0, len(keys))
 	for _, k := range keys {
-		if k != key {
+		if !drop[k] {
 			filtered = append(filtered, k)
 		}
 	}
 
-	return s.writeIndex(filtered)
+	return s.updateIndex(filtered)
 }
 
-func (s *Store) readIndex() []string {
-	data, err := keyring.Get(s.service, indexKey)
+// rebuiltIndex enumerates the keys for an empty index and writes them as
+// the new index. Failures are logged and leave the index empty.
+func (s *Store) rebuiltIndex() []string {
+	keys, err := s.enumerated()
 	if err != nil {
+		s.logger.Warn("keychain: index rebuild failed", "service", s.service, "error", err)
 		return nil
 	}
+	if len(keys) > 0 {
+		if err := s.writeIndex(keys); err != nil {
+			s.logger.Warn("keychain: index rebuild write failed", "service", s.service, "error", err)
+		}
+	}
+	return keys
+}
+
+// indexHeader is stored under indexKey and records how many chunks the
+// key list is split across.
+type indexHeader struct {
+	Chunks int `json:"chunks"`
+}
+
+// readIndex reassembles the key list from its chunks in the keyring.
+// Indexes written before chunking, a bare JSON array under indexKey, are
+// read as-is. Read failures are logged and reported as !ok along with
+// whatever keys could be read.
+func (s *Store) readIndex() ([]string, bool) {
+	data, err := keyring.Get(s.service, indexKey)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return nil, true
+	}
+	if err != nil {
+		s.logger.Warn("keychain: index read failed", "service", s.service, "error", err)
+		return nil, false
+	}
+
+	var legacy []string
+	if json.Unmarshal([]byte(data), &legacy) == nil {
+		return legacy, true
+	}
+
+	var header indexHeader
+	_ = json.Unmarshal([]byte(data), &header) //nolint:errcheck // best-effort index read
 
 	var keys []string
-	_ = json.Unmarshal([]byte(data), &keys) //nolint:errcheck // best-effort index read
-	return keys
+	ok := true
+	for i := range header.Chunks {
+		chunk, err := keyring.Get(s.service, chunkKey(i))
+		if err != nil {
+			s.logger.Warn("keychain: index chunk read failed", "service", s.service, "chunk", i, "error", err)
+			ok = false
+			continue
+		}
+		var part []string
+		_ = json.Unmarshal([]byte(chunk), &part) //nolint:errcheck // best-effort index read
+		keys = append(keys, part...)
+	}
+	return keys, ok
 }
 
+// writeIndex stores keys as chunks followed by the header, then removes
+// chunks left over from a previously larger index.
 func (s *Store) writeIndex(keys []string) error {
-	data, err := json.Marshal(keys)
+	previous := s.readChunkCount()
+
+	chunks := 0
+	for start := 0; start < len(keys); start += s.chunkSize {
+		end := min(start+s.chunkSize, len(keys))
+		if err := s.writeIndexItem(chunkKey(chunks), keys[start:end]); err != nil {
+			return err
+		}
+		chunks++
+	}
+
+	if err := s.writeIndexItem(indexKey, indexHeader{Chunks: chunks}); err != nil {
+		return err
+	}
+
+	for i := chunks; i < previous; i++ {
+		if err := keyring.Delete(s.service, chunkKey(i)); err != nil && !errors.Is(err, keyring.ErrNotFound) {
+			return storeErr("index write", "", backendErr(err))
+		}
+	}
+
+	return nil
+}
+
+func (s *Store) writeIndexItem(key string, v any) error {
+	data, err := json.Marshal(v)
 	if err != nil {
-		return fmt.Errorf("keychain: index marshal: %w", err)
+		return storeErr("index marshal", "", err)
 	}
 
-	if err := keyring.Set(s.service, indexKey, string(data)); err != nil {
-		return fmt.Errorf("keychain: index write: %w", err)
+	if err := keyringSet(s.service, key, string(data)); err != nil {
+		return storeErr("index write", "", backendErr(err))
 	}
 
 	return nil
 }
+
+func (s *Store) readChunkCount() int {
+	data, err := keyring.Get(s.service, indexKey)
+	if err != nil {
+		return 0
+	}
+
+	var header indexHeader
+	_ = json.Unmarshal([]byte(data), &header) //nolint:errcheck // legacy or corrupt headers have no chunks
+	return header.Chunks
+}
+
+func chunkKey(i int) string {
+	return indexKey + strconv.Itoa(i)
+}
+
+// reserved reports whether key is the index header or one of its chunks.
+func reserved(key string) bool {
+	return strings.HasPrefix(key, indexKey)
+}
+
+// withContext runs fn, which calls the keyring, on its own goroutine and
+// returns its result. If ctx is done first, ctx's error is returned,
+// annotated with op and key. The keyring API cannot be interrupted, so an
+// abandoned call still runs to completion.
+func withContext[T any](ctx context.Context, op, key string, fn func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, storeErr(op, key, err)
+	}
+
+	type result struct {
+		v   T
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		v, err := fn()
+		done <- result{v, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.v, r.err
+	case <-ctx.Done():
+		return zero, storeErr(op, key, ctx.Err())
+	}
+}
+
+// storeErr reports a failure of op on key as a [vault.StoreError].
+func storeErr(op, key string, err error) error {
+	return fmt.Errorf("keychain: %w", &vault.StoreError{Op: op, Key: key, Err: err})
+}
+
+// backendErr marks keyring failures as [vault.ErrBackendUnavailable].
+// Errors about the data itself, such as an oversized value, are returned
+// unchanged.
+func backendErr(err error) error {
+	if errors.Is(err, keyring.ErrSetDataTooBig) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", vault.ErrBackendUnavailable, err)
+}
@@ -1,9 +1,15 @@
 package keychain_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -129,3 +135,429 @@ func TestStore_ImplementsInterfaces(t *testing.T) {
 	_, ok := store.(vault.Namespaced)
 	assert.True(t, ok, "keychain.Store should implement vault.Namespaced")
 }
+
+func TestStore_PersistsReadOnly(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-readonly"))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v", ReadOnly: true}))
+
+	got, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, got.ReadOnly)
+}
+
+func TestStore_PersistsRotation(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-rotation"))
+	ctx := context.Background()
+	rotated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", RotateEvery: time.Hour, LastRotated: rotated}))
+
+	got, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, time.Hour, got.RotateEvery)
+	assert.True(t, rotated.Equal(got.LastRotated))
+}
+
+func TestStore_BackendUnavailable(t *testing.T) {
+	errDBus := errors.New("dbus: connection refused")
+	keyring.MockInitWithError(errDBus)
+	t.Cleanup(keyring.MockInit)
+
+	s := keychain.New(keychain.WithService("test-unavailable"))
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+	require.ErrorIs(t, err, errDBus)
+	require.NotErrorIs(t, err, vault.ErrNotFound)
+
+	err = s.Set(ctx, vault.Entry{Key: "k", Value: "v"})
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+
+	err = s.Delete(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+}
+
+func TestStore_StoreError(t *testing.T) {
+	errDBus := errors.New("dbus: connection refused")
+	keyring.MockInitWithError(errDBus)
+	t.Cleanup(keyring.MockInit)
+
+	s := keychain.New(keychain.WithService("test-store-error"))
+
+	err := s.Set(context.Background(), vault.Entry{Key: "db-password", Value: "v"})
+	var serr *vault.StoreError
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "set", serr.Op)
+	assert.Equal(t, "db-password", serr.Key)
+	require.ErrorIs(t, serr.Err, errDBus)
+	assert.EqualError(t, err, `keychain: set "db-password": vault: backend unavailable: dbus: connection refused`)
+
+	keyring.MockInit()
+	_, err = s.Get(context.Background(), "missing")
+	require.ErrorAs(t, err, &serr)
+	assert.Equal(t, "get", serr.Op)
+	assert.Equal(t, "missing", serr.Key)
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestStore_DataTooBigIsNotUnavailable(t *testing.T) {
+	keyring.MockInitWithError(keyring.ErrSetDataTooBig)
+	t.Cleanup(keyring.MockInit)
+
+	s := keychain.New(keychain.WithService("test-too-big"))
+
+	err := s.Set(context.Background(), vault.Entry{Key: "k", Value: "v"})
+	require.ErrorIs(t, err, keyring.ErrSetDataTooBig)
+	require.NotErrorIs(t, err, vault.ErrBackendUnavailable)
+}
+
+func TestStore_ChunkedIndex(t *testing.T) {
+	const service = "test-chunked"
+	s := keychain.New(keychain.WithService(service), keychain.WithIndexChunkSize(3))
+	ctx := context.Background()
+
+	for i := range 10 {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: fmt.Sprintf("k%02d", i), Value: "v"}))
+	}
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 10)
+
+	_, err = keyring.Get(service, "__vault_index__3")
+	require.NoError(t, err, "10 keys in chunks of 3 should use four chunks")
+
+	for i := range 8 {
+		require.NoError(t, s.Delete(ctx, fmt.Sprintf("k%02d", i)))
+	}
+
+	entries, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	_, err = keyring.Get(service, "__vault_index__1")
+	require.ErrorIs(t, err, keyring.ErrNotFound, "leftover chunks should be removed")
+}
+
+func TestStore_LegacyIndex(t *testing.T) {
+	const service = "test-legacy-index"
+	s := keychain.New(keychain.WithService(service))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, keyring.Set(service, "__vault_index__", `["a"]`))
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// The next write migrates the index to the chunked format.
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+	entries, err = s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStore_GetMany(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-getmany"))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+	got, err := s.GetMany(ctx, []string{"a", "b", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "2", got["b"].Value)
+}
+
+func TestStore_Exists(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-exists"))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	ok, err := s.Exists(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = s.Exists(ctx, "missing")
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = s.WithNamespace("other").(vault.Exister).Exists(ctx, "k")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestStore_Keys(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-keys"))
+	ctx := context.Background()
+
+	for _, k := range []string{"c", "a", "b"} {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: k}))
+	}
+	require.NoError(t, s.WithNamespace("other").Set(ctx, vault.Entry{Key: "x"}))
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+}
+
+func TestStore_cancelledContext(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-cancel"))
+	require.NoError(t, s.Set(context.Background(), vault.Entry{Key: "k", Value: "v"}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.List(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	_, err = s.Keys(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+
+	require.ErrorIs(t, s.Set(ctx, vault.Entry{Key: "other"}), context.Canceled)
+	require.ErrorIs(t, s.Delete(ctx, "k"), context.Canceled)
+
+	// Nothing was changed by the cancelled calls.
+	keys, err := s.Keys(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k"}, keys)
+}
+
+func TestStore_Get_corrupt(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-corrupt-get"))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, keyring.Set("test-corrupt-get", "k", `{"key":"k","val`))
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, keychain.ErrCorrupt)
+	assert.NotErrorIs(t, err, vault.ErrNotFound)
+
+	var syntaxErr *json.SyntaxError
+	assert.ErrorAs(t, err, &syntaxErr)
+}
+
+func TestStore_List_corrupt(t *testing.T) {
+	ctx := context.Background()
+
+	seed := func(service string) {
+		s := keychain.New(keychain.WithService(service))
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: "good", Value: "v"}))
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: "bad", Value: "v"}))
+		require.NoError(t, keyring.Set(service, "bad", "not json"))
+	}
+
+	t.Run("fails by default", func(t *testing.T) {
+		seed("test-corrupt-list")
+
+		_, err := keychain.New(keychain.WithService("test-corrupt-list")).List(ctx)
+		require.ErrorIs(t, err, keychain.ErrCorrupt)
+	})
+
+	t.Run("skips and logs", func(t *testing.T) {
+		seed("test-corrupt-skip")
+
+		var logs bytes.Buffer
+		s := keychain.New(
+			keychain.WithService("test-corrupt-skip"),
+			keychain.WithSkipCorrupt(true),
+			keychain.WithLogger(slog.New(slog.NewTextHandler(&logs, nil))),
+		)
+
+		entries, err := s.List(ctx)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "good", entries[0].Key)
+		assert.Contains(t, logs.String(), "key=bad")
+	})
+
+	t.Run("namespace inherits option", func(t *testing.T) {
+		seed("test-corrupt-ns/prod")
+
+		s := keychain.New(keychain.WithService("test-corrupt-ns"), keychain.WithSkipCorrupt(true))
+
+		entries, err := s.WithNamespace("prod").List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+}
+
+func TestStore_ReservedKey(t *testing.T) {
+	const service = "test-reserved"
+	s := keychain.New(keychain.WithService(service))
+	ctx := context.Background()
+
+	for _, key := range []string{"__vault_index__", "__vault_index__0"} {
+		require.ErrorIs(t, s.Set(ctx, vault.Entry{Key: key, Value: "v"}), keychain.ErrReservedKey, key)
+		require.ErrorIs(t, s.Delete(ctx, key), keychain.ErrReservedKey, key)
+	}
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+
+	// Simulate an index that somehow lists itself.
+	require.NoError(t, keyring.Set(service, "__vault_index__", `["a","__vault_index__"]`))
+	require.NoError(t, s.Reload(ctx))
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "a", entries[0].Key)
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, keys)
+}
+
+func TestStore_WithNamespace_invalid(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-ns-invalid"))
+
+	for _, ns := range []string{"", "a/", "/a", "a\tb"} {
+		assert.Panics(t, func() { s.WithNamespace(ns) }, "%q", ns)
+	}
+	assert.NotPanics(t, func() { s.WithNamespace("team/prod") })
+}
+
+func TestStore_DeleteMany(t *testing.T) {
+	const service = "test-delete-many"
+	s := keychain.New(keychain.WithService(service), keychain.WithIndexChunkSize(2))
+	ctx := context.Background()
+
+	for i := range 6 {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: fmt.Sprintf("k%d", i), Value: "v"}))
+	}
+
+	writes := keychain.CountIndexWrites(t, service)
+	require.NoError(t, s.DeleteMany(ctx, []string{"k0", "k2", "k4", "missing"}))
+	assert.Equal(t, 1, *writes, "the index should be rewritten exactly once")
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"k1", "k3", "k5"}, keys)
+
+	_, err = s.Get(ctx, "k2")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	require.ErrorIs(t, s.DeleteMany(ctx, []string{"k1", "__vault_index__"}), keychain.ErrReservedKey)
+	_, err = s.Get(ctx, "k1")
+	require.NoError(t, err, "nothing is deleted when a reserved key is requested")
+}
+
+func TestStore_IndexCache(t *testing.T) {
+	const service = "test-index-cache"
+	s := keychain.New(keychain.WithService(service))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+
+	// A second store for the same service shares the cached index.
+	other := keychain.New(keychain.WithService(service))
+	require.NoError(t, other.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	// Another process rewrites the index behind our back.
+	require.NoError(t, keyring.Set(service, "c", `{"key":"c","value":"3"}`))
+	require.NoError(t, keyring.Set(service, "__vault_index__", `["a","b","c"]`))
+
+	keys, err = s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys, "the cache is not re-read on its own")
+
+	require.NoError(t, s.Reload(ctx))
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func BenchmarkStore_Set(b *testing.B) {
+	ctx := context.Background()
+
+	run := func(b *testing.B, service string, reload bool) {
+		b.Helper()
+
+		s := keychain.New(keychain.WithService(service))
+		for i := range 200 {
+			require.NoError(b, s.Set(ctx, vault.Entry{Key: fmt.Sprintf("seed%d", i), Value: "v"}))
+		}
+
+		b.ResetTimer()
+		for i := range b.N {
+			if reload {
+				// Re-reading the index before every write is what each
+				// Set cost before the index was cached.
+				require.NoError(b, s.Reload(ctx))
+			}
+			require.NoError(b, s.Set(ctx, vault.Entry{Key: fmt.Sprintf("k%d", i%100), Value: "v"}))
+		}
+	}
+
+	b.Run("cached", func(b *testing.B) { run(b, "bench-cached", false) })
+	b.Run("uncached", func(b *testing.B) { run(b, "bench-uncached", true) })
+}
+
+func TestStore_Rebuild(t *testing.T) {
+	const service = "test-rebuild"
+	ctx := context.Background()
+
+	// Written directly, as another tool would, so the index knows nothing
+	// about them.
+	for _, key := range []string{"a", "b"} {
+		data, err := json.Marshal(vault.Entry{Key: key, Value: "v-" + key})
+		require.NoError(t, err)
+		require.NoError(t, keyring.Set(service, key, string(data)))
+	}
+
+	entries, err := keychain.New(keychain.WithService(service)).List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	require.ErrorIs(t, keychain.New(keychain.WithService(service)).Rebuild(ctx), keychain.ErrNoEnumerator)
+
+	enumerate := func() ([]string, error) { return []string{"b", "a", "b", "__vault_index__"}, nil }
+	s := keychain.New(keychain.WithService(service), keychain.WithRebuildIndex(enumerate))
+	require.NoError(t, s.Rebuild(ctx))
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	entries, err = keychain.New(keychain.WithService(service)).List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStore_RebuildIndex_onMissingIndex(t *testing.T) {
+	const service = "test-rebuild-missing"
+	ctx := context.Background()
+
+	data, err := json.Marshal(vault.Entry{Key: "k", Value: "v"})
+	require.NoError(t, err)
+	require.NoError(t, keyring.Set(service, "k", string(data)))
+
+	enumerate := func() ([]string, error) { return []string{"k"}, nil }
+	s := keychain.New(keychain.WithService(service), keychain.WithRebuildIndex(enumerate))
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "v", entries[0].Value)
+
+	errEnum := errors.New("enumerate failed")
+	failing := keychain.New(keychain.WithService(service), keychain.WithRebuildIndex(func() ([]string, error) {
+		return nil, errEnum
+	}))
+	require.ErrorIs(t, failing.Rebuild(ctx), errEnum)
+}
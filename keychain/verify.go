@@ -0,0 +1,82 @@
+package keychain
+
+import (
+	"context"
+	"errors"
+
+	"github.com/bjaus/vault"
+)
+
+// Report describes inconsistencies between the key index and the items
+// actually stored in the keychain.
+//
+// Items that exist in the keychain but are missing from the index cannot
+// be detected, because go-keyring offers no way to enumerate a service.
+type Report struct {
+	// Stale lists indexed keys that no longer resolve to an item.
+	Stale []string
+
+	// Duplicates lists keys that appear in the index more than once.
+	Duplicates []string
+}
+
+// Consistent reports whether no problems were found.
+func (r Report) Consistent() bool {
+	return len(r.Stale) == 0 && len(r.Duplicates) == 0
+}
+
+// Verify cross-checks the key index against the keychain, reporting index
+// entries that no longer resolve and keys indexed more than once. It does
+// not modify anything; use [Store.Repair] to fix what it finds.
+func (s *Store) Verify(ctx context.Context) (Report, error) {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	report, _, err := s.verify(ctx)
+	return report, err
+}
+
+// Repair rewrites the key index without stale or duplicate entries and
+// returns what was found, as [Store.Verify] would.
+func (s *Store) Repair(ctx context.Context) (Report, error) {
+	s.index.mu.Lock()
+	defer s.index.mu.Unlock()
+
+	report, valid, err := s.verify(ctx)
+	if err != nil || report.Consistent() {
+		return report, err
+	}
+
+	return report, s.updateIndex(valid)
+}
+
+// verify returns the report along with the index as it should be. The
+// index is read from the keyring rather than the cache, and the cache is
+// refreshed with what was read. The caller must hold s.index.mu.
+func (s *Store) verify(ctx context.Context) (Report, []string, error) {
+	var report Report
+	s.index.loaded = false
+	keys := s.cachedIndex()
+	valid := make([]string, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+
+	for _, key := range keys {
+		if seen[key] {
+			report.Duplicates = append(report.Duplicates, key)
+			continue
+		}
+		seen[key] = true
+
+		_, err := s.Get(ctx, key)
+		if errors.Is(err, vault.ErrNotFound) {
+			report.Stale = append(report.Stale, key)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrCorrupt) { // corrupt values are still indexed correctly
+			return Report{}, nil, err
+		}
+		valid = append(valid, key)
+	}
+
+	return report, valid, nil
+}
@@ -0,0 +1,54 @@
+package keychain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/keychain"
+)
+
+func TestStore_VerifyRepair(t *testing.T) {
+	const service = "test-verify"
+	s := keychain.New(keychain.WithService(service))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+	// Corrupt the index: a key that was never stored and a duplicate.
+	require.NoError(t, keyring.Set(service, "__vault_index__", `["a","ghost","b","a"]`))
+
+	report, err := s.Verify(ctx)
+	require.NoError(t, err)
+	assert.False(t, report.Consistent())
+	assert.Equal(t, []string{"ghost"}, report.Stale)
+	assert.Equal(t, []string{"a"}, report.Duplicates)
+
+	repaired, err := s.Repair(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, report, repaired)
+
+	report, err = s.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Consistent())
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestStore_Verify_consistent(t *testing.T) {
+	s := keychain.New(keychain.WithService("test-verify-ok"))
+	ctx := context.Background()
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+
+	report, err := s.Verify(ctx)
+	require.NoError(t, err)
+	assert.True(t, report.Consistent())
+}
@@ -0,0 +1,165 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+var errFullFetch = errors.New("full fetch")
+
+// keySource is a [vault.KeyFetcher] that records the keys it is asked for
+// and fails if fetched in full. FetchKey returns err, if set.
+type keySource struct {
+	entries map[string]string
+	err     error
+
+	mu        sync.Mutex
+	requested []string
+}
+
+func (s *keySource) Fetch(context.Context) ([]vault.Entry, error) {
+	return nil, errFullFetch
+}
+
+func (s *keySource) FetchKey(_ context.Context, key string) (vault.Entry, bool, error) {
+	s.mu.Lock()
+	s.requested = append(s.requested, key)
+	s.mu.Unlock()
+
+	if s.err != nil {
+		return vault.Entry{}, false, s.err
+	}
+	value, ok := s.entries[key]
+	if !ok {
+		return vault.Entry{}, false, nil
+	}
+	return vault.Entry{Key: key, Value: value}, true, nil
+}
+
+func (s *keySource) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.requested...)
+}
+
+func TestGet_keyFetcherFetchesOnlyMissingKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := &keySource{entries: map[string]string{"a": "1", "b": "2"}}
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithSource(vault.NamedSource("keys", src)))
+
+	e, err := v.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", e.Value)
+	assert.Equal(t, []string{"a"}, src.keys())
+
+	_, err = store.Get(ctx, "b")
+	require.ErrorIs(t, err, vault.ErrNotFound, "other keys are not fetched")
+
+	_, err = v.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.Equal(t, []string{"a", "missing"}, src.keys())
+}
+
+func TestGet_keyFetcherFallsBackWithMixedSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keys := &keySource{entries: map[string]string{"a": "1"}}
+	full := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "b", Value: "2"}}, nil
+	})
+	v := vault.New(vault.WithSource(keys), vault.WithSource(full))
+
+	_, err := v.Get(ctx, "b")
+	require.ErrorIs(t, err, errFullFetch, "a source without FetchKey forces a full refresh")
+	assert.Empty(t, keys.keys())
+}
+
+func TestGetFresh_usesKeyFetcher(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	keys := &keySource{entries: map[string]string{"a": "1"}}
+	full := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "a", Value: "0"}, {Key: "b", Value: "2"}}, nil
+	})
+	v := vault.New(vault.WithSource(full), vault.WithSource(keys))
+
+	e, err := v.GetFresh(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", e.Value)
+	assert.Equal(t, []string{"a"}, keys.keys())
+}
+
+func TestGetFresh_namespaceContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+	tenant := vault.WithNamespaceContext(ctx, "tenant")
+
+	e, err := v.GetFresh(tenant, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+
+	_, err = store.WithNamespace("tenant").Get(ctx, "k")
+	require.NoError(t, err, "the entry is stored in the context namespace")
+	_, err = store.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestGet_keyFetcherNamespaceContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := &keySource{entries: map[string]string{"k": "v"}}
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+
+	for _, ns := range []string{"a", "b"} {
+		e, err := v.Get(vault.WithNamespaceContext(ctx, ns), "k")
+		require.NoError(t, err, ns)
+		assert.Equal(t, "v", e.Value)
+		_, err = store.WithNamespace(ns).Get(ctx, "k")
+		require.NoError(t, err, ns)
+	}
+	assert.Equal(t, []string{"k", "k"}, src.keys(), "each namespace fetches the key for itself")
+}
+
+func TestGet_keyFetcherFailFast(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errDown := errors.New("source down")
+	src := &keySource{err: errDown}
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithTTL(time.Hour),
+		vault.WithFailFastOnRefreshError(),
+	)
+
+	for range 3 {
+		_, err := v.Get(ctx, "missing")
+		require.ErrorIs(t, err, errDown)
+	}
+	assert.Equal(t, []string{"missing"}, src.keys(), "later Gets return the cached error")
+
+	stats := v.Stats(ctx)
+	assert.Equal(t, uint64(1), stats.Refreshes)
+	assert.Equal(t, uint64(1), stats.RefreshErrors)
+}
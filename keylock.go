@@ -0,0 +1,37 @@
+package vault
+
+import (
+	"hash/fnv"
+	"slices"
+	"sync"
+)
+
+// keyLocks serializes operations on the same key. Keys hash onto a fixed
+// set of mutexes, so unrelated keys occasionally share a stripe; that
+// only costs some unnecessary waiting, never correctness. Namespaces are
+// not part of the hash, so the same key in two namespaces also shares a
+// stripe.
+type keyLocks [64]sync.Mutex
+
+// lock locks the stripes for keys and returns the matching unlock.
+// Stripes are always taken in ascending order, so two callers locking
+// overlapping sets of keys cannot deadlock.
+func (l *keyLocks) lock(keys ...string) func() {
+	stripes := make([]uint32, len(keys))
+	for i, key := range keys {
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(key)) //nolint:errcheck // hash writes never fail
+		stripes[i] = h.Sum32() % uint32(len(l))
+	}
+	slices.Sort(stripes)
+	stripes = slices.Compact(stripes)
+
+	for _, i := range stripes {
+		l[i].Lock()
+	}
+	return func() {
+		for _, i := range stripes {
+			l[i].Unlock()
+		}
+	}
+}
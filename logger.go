@@ -0,0 +1,28 @@
+package vault
+
+import "log/slog"
+
+// Logger receives diagnostic messages from the vault. Each call takes a
+// message followed by alternating key/value pairs, as in [slog.Logger],
+// which satisfies this interface directly. Use [WithLogger] to attach one;
+// by default nothing is logged.
+type Logger interface {
+	Debug(msg string, keysAndValues ...any)
+	Warn(msg string, keysAndValues ...any)
+	Error(msg string, keysAndValues ...any)
+}
+
+// SlogLogger adapts l to [Logger]. A nil l discards all messages.
+func SlogLogger(l *slog.Logger) Logger {
+	if l == nil {
+		return nopLogger{}
+	}
+	return l
+}
+
+// nopLogger is the default [Logger]; it discards everything.
+type nopLogger struct{}
+
+func (nopLogger) Debug(string, ...any) {}
+func (nopLogger) Warn(string, ...any)  {}
+func (nopLogger) Error(string, ...any) {}
@@ -0,0 +1,95 @@
+package vault_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+// captureLogger records every message as "level msg".
+type captureLogger struct {
+	mu    sync.Mutex
+	lines []string
+}
+
+func (c *captureLogger) log(level, msg string, kv ...any) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines = append(c.lines, fmt.Sprint(level, " ", msg, " ", kv))
+}
+
+func (c *captureLogger) Debug(msg string, kv ...any) { c.log("DEBUG", msg, kv...) }
+func (c *captureLogger) Warn(msg string, kv ...any)  { c.log("WARN", msg, kv...) }
+func (c *captureLogger) Error(msg string, kv ...any) { c.log("ERROR", msg, kv...) }
+
+func (c *captureLogger) contains(prefix string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, l := range c.lines {
+		if strings.HasPrefix(l, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestLogger_refresh(t *testing.T) {
+	t.Parallel()
+
+	logger := &captureLogger{}
+	src := vault.NamedSource("env", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k"}}, nil
+	}))
+	v := vault.New(vault.WithSource(src), vault.WithLogger(logger))
+
+	_, err := v.Get(context.Background(), "k")
+	require.NoError(t, err)
+
+	assert.True(t, logger.contains("DEBUG vault: auto-refresh"))
+	assert.True(t, logger.contains("DEBUG vault: source fetched [source env"))
+	assert.True(t, logger.contains("DEBUG vault: refresh completed"))
+}
+
+func TestLogger_failures(t *testing.T) {
+	t.Parallel()
+
+	logger := &captureLogger{}
+	errFetch := errors.New("upstream down")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errFetch })
+	v := vault.New(vault.WithSource(src), vault.WithLogger(logger))
+
+	require.ErrorIs(t, v.Refresh(context.Background()), errFetch)
+	assert.True(t, logger.contains("WARN vault: source fetch failed"))
+	assert.True(t, logger.contains("WARN vault: refresh failed"))
+
+	errStore := errors.New("disk on fire")
+	v = vault.New(vault.WithStore(&failStore{err: errStore}), vault.WithLogger(logger))
+	_, err := v.Get(context.Background(), "k")
+	require.ErrorIs(t, err, errStore)
+	assert.True(t, logger.contains("ERROR vault: store get failed"))
+}
+
+func TestSlogLogger(t *testing.T) {
+	t.Parallel()
+
+	var buf bytes.Buffer
+	logger := vault.SlogLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+	v := vault.New(vault.WithLogger(logger), vault.WithSource(vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, nil
+	})))
+
+	require.NoError(t, v.Refresh(context.Background()))
+	assert.Contains(t, buf.String(), "vault: refresh completed")
+
+	assert.NotPanics(t, func() { vault.SlogLogger(nil).Warn("ignored") })
+}
@@ -0,0 +1,73 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/clocktest"
+)
+
+func TestGetMaxAge(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	var fetches atomic.Int32
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		fetches.Add(1)
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+	v := vault.New(vault.WithSource(src), vault.WithClock(clock), vault.WithTTL(time.Hour))
+
+	_, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	require.Equal(t, int32(1), fetches.Load())
+
+	clock.Advance(10 * time.Minute)
+
+	e, err := v.GetMaxAge(ctx, "k", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+	assert.Equal(t, int32(1), fetches.Load(), "fresh enough for a day-old consumer")
+
+	e, err = v.GetMaxAge(ctx, "k", 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+	assert.Equal(t, int32(2), fetches.Load(), "too old for a five-minute consumer")
+	assert.Equal(t, clock.Now(), e.CreatedAt)
+
+	clock.Advance(2 * time.Hour)
+
+	_, err = v.GetMaxAge(ctx, "k", 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load(), "maxAge overrides the vault's TTL")
+
+	_, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), fetches.Load())
+}
+
+func TestGetMaxAge_expiresAtStillApplies(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "k",
+		Value:     "v",
+		CreatedAt: clock.Now(),
+		ExpiresAt: clock.Now().Add(time.Minute),
+	}))
+	v := vault.New(vault.WithStore(store), vault.WithClock(clock))
+
+	clock.Advance(2 * time.Minute)
+	_, err := v.GetMaxAge(ctx, "k", time.Hour)
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
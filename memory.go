@@ -2,16 +2,40 @@ package vault
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"sync"
+	"time"
 )
 
+// persistDelay is how long a persisted [Memory] waits after a mutation
+// before writing to disk, so bursts of writes are coalesced.
+const persistDelay = 100 * time.Millisecond
+
 type memoryState struct {
 	mu      sync.RWMutex
 	entries map[string]Entry
+
+	// Set only for stores created by NewMemoryPersisted. writeMu
+	// serializes writes to path, so that a background save cannot
+	// replace a newer one made by Flush.
+	path    string
+	saveMu  sync.Mutex
+	pending *time.Timer
+	writeMu sync.Mutex
 }
 
 // Memory is an in-memory [Store]. It is safe for concurrent use and
 // implements [Namespaced]. Useful for testing and as the default store.
+// Entries are copied on the way in and out, so callers cannot change
+// stored tags or metadata through the maps they pass or receive.
 type Memory struct {
 	state  *memoryState
 	prefix string
@@ -26,9 +50,26 @@ func NewMemory() *Memory {
 	}
 }
 
+// NewMemoryPersisted creates an in-memory store that survives restarts.
+// Entries are loaded from path if it exists, and every mutation schedules
+// an atomic write of the whole store back to path shortly afterwards.
+// Errors from these background writes are not reported; call
+// [Memory.Flush] to persist synchronously. [Memory.Close], which
+// [Vault.Close] calls, flushes too, so that no write is lost on exit.
+func NewMemoryPersisted(path string) (*Memory, error) {
+	m := NewMemory()
+	if err := m.LoadFrom(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	m.state.path = path
+	return m, nil
+}
+
 // WithNamespace returns a [Store] scoped to the given namespace. The
-// returned store shares the same backing data as the original.
+// returned store shares the same backing data as the original. It panics
+// if ns is not valid according to [ValidateNamespace].
 func (m *Memory) WithNamespace(ns string) Store {
+	mustValidateNamespace(ns)
 	return &Memory{
 		state:  m.state,
 		prefix: ns + "/",
@@ -42,17 +83,60 @@ func (m *Memory) Get(_ context.Context, key string) (Entry, error) {
 
 	e, ok := m.state.entries[m.prefix+key]
 	if !ok {
-		return Entry{}, ErrNotFound
+		return Entry{}, &StoreError{Op: "get", Key: key, Err: ErrNotFound}
+	}
+	return e.clone(), nil
+}
+
+// GetMany retrieves several entries under a single lock. Missing keys are
+// absent from the result.
+func (m *Memory) GetMany(_ context.Context, keys []string) (map[string]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	found := make(map[string]Entry, len(keys))
+	for _, key := range keys {
+		if e, ok := m.state.entries[m.prefix+key]; ok {
+			found[key] = e.clone()
+		}
 	}
-	return e, nil
+	return found, nil
 }
 
-// Set stores an entry.
+// Exists reports whether key is present.
+func (m *Memory) Exists(_ context.Context, key string) (bool, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	_, ok := m.state.entries[m.prefix+key]
+	return ok, nil
+}
+
+// Set stores a copy of entry, so later changes to its maps do not affect
+// the store.
 func (m *Memory) Set(_ context.Context, entry Entry) error {
 	m.state.mu.Lock()
 	defer m.state.mu.Unlock()
 
-	m.state.entries[m.prefix+entry.Key] = entry
+	m.state.entries[m.prefix+entry.Key] = entry.clone()
+	m.state.schedulePersist()
+	return nil
+}
+
+// SetMany stores several entries under a single lock, so readers see
+// either none or all of them. Nothing is stored if ctx is already done.
+func (m *Memory) SetMany(ctx context.Context, entries []Entry) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	for _, e := range entries {
+		m.state.entries[m.prefix+e.Key] = e.clone()
+	}
+	m.state.schedulePersist()
 	return nil
 }
 
@@ -62,10 +146,42 @@ func (m *Memory) Delete(_ context.Context, key string) error {
 	defer m.state.mu.Unlock()
 
 	delete(m.state.entries, m.prefix+key)
+	m.state.schedulePersist()
 	return nil
 }
 
-// List returns all entries in the store (within the current namespace).
+// DeleteMany removes several entries under a single lock.
+func (m *Memory) DeleteMany(_ context.Context, keys []string) error {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	for _, key := range keys {
+		delete(m.state.entries, m.prefix+key)
+	}
+	m.state.schedulePersist()
+	return nil
+}
+
+// Rename moves the entry at oldKey to newKey under a single lock.
+func (m *Memory) Rename(_ context.Context, oldKey, newKey string) error {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	e, ok := m.state.entries[m.prefix+oldKey]
+	if !ok {
+		return &StoreError{Op: "rename", Key: oldKey, Err: ErrNotFound}
+	}
+	e.Key = newKey
+	delete(m.state.entries, m.prefix+oldKey)
+	m.state.entries[m.prefix+newKey] = e
+	m.state.schedulePersist()
+	return nil
+}
+
+// List returns all entries in the store (within the current namespace),
+// sorted by key. They are read under a single lock, so they form a
+// consistent snapshot; use [Memory.ListStream] to avoid copying them all
+// at once.
 func (m *Memory) List(_ context.Context) ([]Entry, error) {
 	m.state.mu.RLock()
 	defer m.state.mu.RUnlock()
@@ -73,9 +189,251 @@ func (m *Memory) List(_ context.Context) ([]Entry, error) {
 	entries := make([]Entry, 0, len(m.state.entries))
 	for k, e := range m.state.entries {
 		if m.prefix == "" || len(k) > len(m.prefix) && k[:len(m.prefix)] == m.prefix {
-			entries = append(entries, e)
+			entries = append(entries, e.clone())
+		}
+	}
+	slices.SortFunc(entries, func(a, b Entry) int { return strings.Compare(a.Key, b.Key) })
+	return entries, nil
+}
+
+// ListStream yields the entries in scope sorted by key. The keys are
+// copied up front, but each entry is read under its own brief lock and
+// none is held while yielding, so the loop body may use the store.
+// Entries deleted during iteration are skipped, and entries added are not
+// seen. Iteration stops with ctx's error if ctx is done.
+func (m *Memory) ListStream(ctx context.Context) (iter.Seq2[Entry, error], error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.state.mu.RLock()
+	keys := make([]string, 0, len(m.state.entries))
+	for k := range m.state.entries {
+		if m.prefix == "" || len(k) > len(m.prefix) && k[:len(m.prefix)] == m.prefix {
+			keys = append(keys, k)
+		}
+	}
+	m.state.mu.RUnlock()
+	slices.Sort(keys)
+
+	return func(yield func(Entry, error) bool) {
+		for _, k := range keys {
+			if err := ctx.Err(); err != nil {
+				yield(Entry{}, err)
+				return
+			}
+
+			m.state.mu.RLock()
+			e, ok := m.state.entries[k]
+			m.state.mu.RUnlock()
+			if !ok {
+				continue
+			}
+			if !yield(e.clone(), nil) {
+				return
+			}
+		}
+	}, nil
+}
+
+// ListByTag returns the entries in scope tagged key=value, scanning under
+// a single read lock without copying the rest.
+func (m *Memory) ListByTag(_ context.Context, key, value string) ([]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	var entries []Entry
+	for k, e := range m.state.entries {
+		if !e.hasTag(key, value) {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(k, m.prefix); ok && rest != "" {
+			entries = append(entries, e.clone())
+		}
+	}
+
+	return entries, nil
+}
+
+// ListPrefix returns the entries in scope whose key, with the namespace
+// prefix removed, starts with prefix.
+func (m *Memory) ListPrefix(_ context.Context, prefix string) ([]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	var entries []Entry
+	for k, e := range m.state.entries {
+		if key, ok := strings.CutPrefix(k, m.prefix); ok && key != "" && strings.HasPrefix(key, prefix) {
+			entries = append(entries, e.clone())
 		}
 	}
 
 	return entries, nil
 }
+
+// KeysPrefix returns the keys in scope that start with prefix, sorted,
+// with the namespace prefix removed.
+func (m *Memory) KeysPrefix(_ context.Context, prefix string) ([]string, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	var keys []string
+	for k := range m.state.entries {
+		if key, ok := strings.CutPrefix(k, m.prefix); ok && key != "" && strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys, nil
+}
+
+// Keys returns the keys in scope, sorted, with the namespace prefix
+// removed.
+func (m *Memory) Keys(_ context.Context) ([]string, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	keys := make([]string, 0, len(m.state.entries))
+	for k := range m.state.entries {
+		if key, ok := strings.CutPrefix(k, m.prefix); ok && key != "" {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	return keys, nil
+}
+
+// Snapshot copies the entries in scope under a single read lock, keyed
+// with the namespace prefix removed. Later writes do not affect the
+// returned map.
+func (m *Memory) Snapshot(_ context.Context) (map[string]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	snap := make(map[string]Entry)
+	for k, e := range m.state.entries {
+		if key, ok := strings.CutPrefix(k, m.prefix); ok && key != "" {
+			snap[key] = e.clone()
+		}
+	}
+
+	return snap, nil
+}
+
+// SaveTo atomically writes the entire backing store to path as JSON,
+// including every namespace that shares it. The file is created with
+// 0600 permissions.
+func (m *Memory) SaveTo(path string) error {
+	m.state.mu.RLock()
+	data, err := json.Marshal(m.state.entries)
+	m.state.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("vault: memory: %w", &StoreError{Op: "save", Err: err})
+	}
+
+	if err := writeFileAtomic(path, data); err != nil {
+		return fmt.Errorf("vault: memory: %w", &StoreError{Op: "save", Err: err})
+	}
+	return nil
+}
+
+// Flush writes a store created by [NewMemoryPersisted] to its path now,
+// cancelling any pending background write, and reports the error. It does
+// nothing for other stores.
+func (m *Memory) Flush() error {
+	s := m.state
+	if s.path == "" {
+		return nil
+	}
+
+	s.saveMu.Lock()
+	if s.pending != nil {
+		s.pending.Stop()
+		s.pending = nil
+	}
+	s.saveMu.Unlock()
+
+	return s.persist()
+}
+
+// Close flushes the store with [Memory.Flush]. The store remains usable,
+// and later writes are persisted as before.
+func (m *Memory) Close() error {
+	return m.Flush()
+}
+
+// LoadFrom replaces the contents of the backing store, across all
+// namespaces, with the entries saved at path by [Memory.SaveTo].
+func (m *Memory) LoadFrom(path string) error {
+	data, err := os.ReadFile(path) //nolint:gosec // path is caller-provided by design
+	if err != nil {
+		return fmt.Errorf("vault: memory: %w", &StoreError{Op: "load", Err: err})
+	}
+
+	entries := make(map[string]Entry)
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return fmt.Errorf("vault: memory: %w", &StoreError{Op: "load", Err: err})
+	}
+
+	m.state.mu.Lock()
+	m.state.entries = entries
+	m.state.mu.Unlock()
+
+	return nil
+}
+
+// schedulePersist arranges for the store to be written to its path once
+// the debounce delay passes. The caller must hold s.mu.
+func (s *memoryState) schedulePersist() {
+	if s.path == "" {
+		return
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	if s.pending != nil {
+		return
+	}
+	s.pending = time.AfterFunc(persistDelay, func() {
+		s.saveMu.Lock()
+		s.pending = nil
+		s.saveMu.Unlock()
+
+		_ = s.persist() //nolint:errcheck // best-effort background save
+	})
+}
+
+// persist writes the whole backing store to s.path.
+func (s *memoryState) persist() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return (&Memory{state: s}).SaveTo(s.path)
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames
+// it into place, so readers never observe a partially written file. The
+// temporary file, and so the result, has 0600 permissions.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close() //nolint:errcheck // already failing
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
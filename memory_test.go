@@ -2,6 +2,8 @@ package vault_test
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -148,3 +150,266 @@ func TestMemory_ImplementsNamespaced(t *testing.T) {
 	_, ok := store.(vault.Namespaced)
 	assert.True(t, ok, "Memory should implement Namespaced")
 }
+
+func TestMemory_SaveLoadRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	m := vault.NewMemory()
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "root", Value: "r"}))
+	require.NoError(t, m.WithNamespace("prod").Set(ctx, vault.Entry{Key: "db", Value: "prod-db"}))
+	require.NoError(t, m.WithNamespace("qa").Set(ctx, vault.Entry{Key: "db", Value: "qa-db"}))
+	require.NoError(t, m.SaveTo(path))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	loaded := vault.NewMemory()
+	require.NoError(t, loaded.LoadFrom(path))
+
+	got, err := loaded.Get(ctx, "root")
+	require.NoError(t, err)
+	assert.Equal(t, "r", got.Value)
+
+	got, err = loaded.WithNamespace("prod").Get(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-db", got.Value)
+
+	qa, err := loaded.WithNamespace("qa").List(ctx)
+	require.NoError(t, err)
+	require.Len(t, qa, 1)
+	assert.Equal(t, "qa-db", qa[0].Value)
+}
+
+func TestMemory_LoadFrom_missingFile(t *testing.T) {
+	t.Parallel()
+
+	err := vault.NewMemory().LoadFrom(filepath.Join(t.TempDir(), "missing.json"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestMemoryPersisted_survivesRestart(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	m, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+
+	prod := m.WithNamespace("prod")
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	require.NoError(t, prod.Delete(ctx, "a"))
+
+	require.Eventually(t, func() bool {
+		reopened, err := vault.NewMemoryPersisted(path)
+		if err != nil {
+			return false
+		}
+		entries, err := reopened.WithNamespace("prod").List(ctx)
+		return err == nil && len(entries) == 1 && entries[0].Key == "b"
+	}, 2*time.Second, 10*time.Millisecond)
+
+	reopened, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+
+	_, err = reopened.Get(ctx, "b")
+	require.ErrorIs(t, err, vault.ErrNotFound, "namespaced entry must not leak into the root scope")
+}
+
+func TestMemoryPersisted_closeFlushes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "vault.json")
+
+	m, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+	v := vault.New(vault.WithStore(m))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	require.NoError(t, v.Close())
+
+	reopened, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+	e, err := reopened.Get(ctx, "k")
+	require.NoError(t, err, "closing the vault persists pending writes")
+	assert.Equal(t, "v", e.Value)
+}
+
+func TestMemoryPersisted_flushError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "sub", "vault.json")
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0o700))
+
+	m, err := vault.NewMemoryPersisted(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "sub")))
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	require.Error(t, m.Flush(), "the save error is reported")
+	require.NoError(t, vault.NewMemory().Flush(), "a store without a path has nothing to flush")
+}
+
+func TestMemory_GetMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	ns := m.WithNamespace("ns")
+
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "c", Value: "3"}))
+
+	bg, ok := ns.(vault.BatchGetter)
+	require.True(t, ok)
+
+	got, err := bg.GetMany(ctx, []string{"a", "b", "c"})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Equal(t, "1", got["a"].Value)
+	assert.NotContains(t, got, "c")
+}
+
+func TestMemory_SetMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	ns := m.WithNamespace("ns")
+
+	bs, ok := ns.(vault.BatchSetter)
+	require.True(t, ok)
+	require.NoError(t, bs.SetMany(ctx, []vault.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}))
+
+	keys, err := m.WithNamespace("ns").(vault.KeyLister).Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.ErrorIs(t, bs.SetMany(cancelled, []vault.Entry{{Key: "c"}}), context.Canceled)
+	_, err = ns.Get(ctx, "c")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestMemory_Keys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	ns := m.WithNamespace("prod")
+
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "root"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "b"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "a"}))
+
+	keys, err := ns.(vault.KeyLister).Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	keys, err = m.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"prod/a", "prod/b", "root"}, keys)
+}
+
+func TestMemory_Snapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	ns := m.WithNamespace("prod")
+
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "root", Value: "r"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "k", Value: "before"}))
+
+	snap, err := ns.(vault.Snapshotter).Snapshot(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "k", Value: "after"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "new", Value: "n"}))
+	require.NoError(t, ns.Delete(ctx, "k"))
+
+	require.Len(t, snap, 1)
+	assert.Equal(t, "before", snap["k"].Value)
+}
+
+func TestMemory_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	ns := m.WithNamespace("ns")
+
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "a"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "b"}))
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "a"}))
+
+	bd, ok := ns.(vault.BatchDeleter)
+	require.True(t, ok)
+	require.NoError(t, bd.DeleteMany(ctx, []string{"a", "b", "missing"}))
+
+	entries, err := ns.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+
+	_, err = m.Get(ctx, "a")
+	require.NoError(t, err, "other namespaces are untouched")
+}
+
+func TestMemory_ListStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	for _, key := range []string{"c", "a", "b"} {
+		require.NoError(t, m.Set(ctx, vault.Entry{Key: key, Value: key}))
+	}
+	require.NoError(t, m.WithNamespace("other").Set(ctx, vault.Entry{Key: "x"}))
+
+	ns := m.WithNamespace("ns")
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "b"}))
+	require.NoError(t, ns.Set(ctx, vault.Entry{Key: "a"}))
+
+	s, ok := ns.(vault.Streamer)
+	require.True(t, ok)
+	seq, err := s.ListStream(ctx)
+	require.NoError(t, err)
+
+	var keys []string
+	for e, err := range seq {
+		require.NoError(t, err)
+		keys = append(keys, e.Key)
+		require.NoError(t, ns.Delete(ctx, "b"), "no lock is held while yielding")
+	}
+	assert.Equal(t, []string{"a"}, keys, "entries deleted during iteration are skipped")
+}
+
+func TestMemory_ListStream_earlyBreak(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+	for _, key := range []string{"a", "b", "c"} {
+		require.NoError(t, m.Set(ctx, vault.Entry{Key: key}))
+	}
+
+	seq, err := m.ListStream(ctx)
+	require.NoError(t, err)
+	var keys []string
+	for e, err := range seq {
+		require.NoError(t, err)
+		keys = append(keys, e.Key)
+		if len(keys) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
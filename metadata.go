@@ -0,0 +1,28 @@
+package vault
+
+import "maps"
+
+// Meta returns the [Entry.Metadata] value stored under key, and whether
+// there is one.
+func (e Entry) Meta(key string) (string, bool) {
+	v, ok := e.Metadata[key]
+	return v, ok
+}
+
+// WithMeta returns a copy of e with the [Entry.Metadata] value for key set
+// to value. The metadata map is copied, so e itself is left unchanged.
+func (e Entry) WithMeta(key, value string) Entry {
+	md := make(map[string]string, len(e.Metadata)+1)
+	maps.Copy(md, e.Metadata)
+	md[key] = value
+	e.Metadata = md
+	return e
+}
+
+// clone returns a copy of e that shares no maps with it, so that a store
+// can hand out and keep entries without callers reaching its state.
+func (e Entry) clone() Entry {
+	e.Tags = maps.Clone(e.Tags)
+	e.Metadata = maps.Clone(e.Metadata)
+	return e
+}
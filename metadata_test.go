@@ -0,0 +1,79 @@
+package vault_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestEntry_Meta(t *testing.T) {
+	t.Parallel()
+
+	var e vault.Entry
+	_, ok := e.Meta("owner")
+	assert.False(t, ok)
+
+	withOwner := e.WithMeta("owner", "ops@example.com")
+	got, ok := withOwner.Meta("owner")
+	require.True(t, ok)
+	assert.Equal(t, "ops@example.com", got)
+	assert.Nil(t, e.Metadata)
+
+	withPolicy := withOwner.WithMeta("policy", "90d")
+	assert.Len(t, withPolicy.Metadata, 2)
+	assert.Len(t, withOwner.Metadata, 1, "WithMeta must not modify the receiver's map")
+}
+
+func TestMemory_copiesMetadata(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemory()
+
+	md := map[string]string{"owner": "ops"}
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Metadata: md}))
+	md["owner"] = "changed after set"
+
+	e, err := m.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "ops", e.Metadata["owner"])
+
+	e.Metadata["owner"] = "changed after get"
+	entries, err := m.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "ops", entries[0].Metadata["owner"])
+
+	entries[0].Metadata["owner"] = "changed after list"
+	e, err = m.Get(ctx, "k")
+	require.NoError(t, err)
+	owner, _ := e.Meta("owner")
+	assert.Equal(t, "ops", owner)
+}
+
+func TestMetadata_roundTripsThroughJSON(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.New()
+	require.NoError(t, src.Set(ctx, vault.Entry{Key: "k", Value: "v"}.WithMeta("owner", "ops")))
+
+	var buf bytes.Buffer
+	require.NoError(t, vault.ExportJSON(ctx, src, &buf))
+	assert.Contains(t, buf.String(), `"metadata"`)
+
+	dst := vault.New()
+	_, err := vault.ImportJSON(ctx, dst, &buf)
+	require.NoError(t, err)
+
+	e, err := dst.Get(ctx, "k")
+	require.NoError(t, err)
+	owner, ok := e.Meta("owner")
+	require.True(t, ok)
+	assert.Equal(t, "ops", owner)
+}
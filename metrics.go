@@ -0,0 +1,27 @@
+package vault
+
+import "time"
+
+// Metrics receives counters and timings from the vault, for example to
+// track the cache hit ratio. Implementations must be safe for concurrent
+// use. Use [WithMetrics] to attach one; the metrics/prom package provides
+// a Prometheus implementation.
+type Metrics interface {
+	// IncHit is called when a lookup is served from a fresh stored entry.
+	IncHit()
+
+	// IncMiss is called when a lookup finds no entry or an expired one,
+	// whether or not a refresh follows.
+	IncMiss()
+
+	// ObserveRefresh is called when a refresh ends, with how long it took
+	// and the error it returned or nil on success.
+	ObserveRefresh(d time.Duration, err error)
+}
+
+// nopMetrics is the default [Metrics]; it records nothing.
+type nopMetrics struct{}
+
+func (nopMetrics) IncHit()                             {}
+func (nopMetrics) IncMiss()                            {}
+func (nopMetrics) ObserveRefresh(time.Duration, error) {}
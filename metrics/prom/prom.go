@@ -0,0 +1,64 @@
+// Package prom implements [vault.Metrics] with Prometheus collectors.
+//
+// The following metrics are registered:
+//
+//   - vault_cache_hits_total: lookups served from a fresh stored entry
+//   - vault_cache_misses_total: lookups that found no entry or an expired one
+//   - vault_refresh_duration_seconds: refresh latency, labelled by result
+//     ("success" or "error")
+package prom
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Metrics is a [vault.Metrics] backed by Prometheus collectors.
+type Metrics struct {
+	hits    prometheus.Counter
+	misses  prometheus.Counter
+	refresh *prometheus.HistogramVec
+}
+
+// New creates [Metrics] and registers its collectors with reg.
+func New(reg prometheus.Registerer) (*Metrics, error) {
+	m := &Metrics{
+		hits: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "vault_cache_hits_total",
+			Help: "Lookups served from a fresh stored entry.",
+		}),
+		misses: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "vault_cache_misses_total",
+			Help: "Lookups that found no entry or an expired one.",
+		}),
+		refresh: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "vault_refresh_duration_seconds",
+			Help:    "Time taken to refresh from sources.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"result"}),
+	}
+
+	for _, c := range []prometheus.Collector{m.hits, m.misses, m.refresh} {
+		if err := reg.Register(c); err != nil {
+			return nil, fmt.Errorf("prom: register: %w", err)
+		}
+	}
+	return m, nil
+}
+
+// IncHit counts a cache hit.
+func (m *Metrics) IncHit() { m.hits.Inc() }
+
+// IncMiss counts a cache miss.
+func (m *Metrics) IncMiss() { m.misses.Inc() }
+
+// ObserveRefresh records the duration of a refresh under its result.
+func (m *Metrics) ObserveRefresh(d time.Duration, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	m.refresh.WithLabelValues(result).Observe(d.Seconds())
+}
@@ -0,0 +1,62 @@
+package prom_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/metrics/prom"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewPedanticRegistry()
+	m, err := prom.New(reg)
+	require.NoError(t, err)
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k"}}, nil
+	})
+	v := vault.New(vault.WithSource(src), vault.WithMetrics(m))
+
+	ctx := context.Background()
+	_, err = v.Get(ctx, "k") // miss, then refresh
+	require.NoError(t, err)
+	_, err = v.Get(ctx, "k") // hit
+	require.NoError(t, err)
+
+	expected := `
+# HELP vault_cache_hits_total Lookups served from a fresh stored entry.
+# TYPE vault_cache_hits_total counter
+vault_cache_hits_total 1
+# HELP vault_cache_misses_total Lookups that found no entry or an expired one.
+# TYPE vault_cache_misses_total counter
+vault_cache_misses_total 1
+`
+	require.NoError(t, testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"vault_cache_hits_total", "vault_cache_misses_total"))
+
+	m.ObserveRefresh(0, errors.New("boom"))
+	n, err := testutil.GatherAndCount(reg, "vault_refresh_duration_seconds")
+	require.NoError(t, err)
+	assert.Equal(t, 2, n, "one series per result")
+}
+
+func TestNew_duplicateRegistration(t *testing.T) {
+	t.Parallel()
+
+	reg := prometheus.NewRegistry()
+	_, err := prom.New(reg)
+	require.NoError(t, err)
+
+	_, err = prom.New(reg)
+	require.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+type countingMetrics struct {
+	hits, misses, refreshes, refreshErrors atomic.Int32
+}
+
+func (m *countingMetrics) IncHit()  { m.hits.Add(1) }
+func (m *countingMetrics) IncMiss() { m.misses.Add(1) }
+
+func (m *countingMetrics) ObserveRefresh(_ time.Duration, err error) {
+	m.refreshes.Add(1)
+	if err != nil {
+		m.refreshErrors.Add(1)
+	}
+}
+
+func TestMetrics_hitAndMiss(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := &countingMetrics{}
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "a"}, {Key: "b"}}, nil
+	})
+	v := vault.New(vault.WithSource(src), vault.WithMetrics(m))
+
+	_, err := v.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int32(0), m.hits.Load())
+	assert.Equal(t, int32(1), m.misses.Load())
+	assert.Equal(t, int32(1), m.refreshes.Load())
+
+	_, err = v.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, int32(1), m.hits.Load())
+
+	_, err = v.GetMany(ctx, []string{"a", "b", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, int32(3), m.hits.Load())
+	assert.Equal(t, int32(2), m.misses.Load())
+	assert.Zero(t, m.refreshErrors.Load())
+}
@@ -0,0 +1,66 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+)
+
+// MigrateOption configures [Migrate].
+type MigrateOption func(*migrateConfig)
+
+type migrateConfig struct {
+	overwrite bool
+	filter    func(key string) bool
+}
+
+// WithOverwrite controls whether [Migrate] replaces entries that already
+// exist in the destination. The default is true; with false, such keys
+// are skipped and not counted.
+func WithOverwrite(overwrite bool) MigrateOption {
+	return func(c *migrateConfig) { c.overwrite = overwrite }
+}
+
+// WithKeyFilter restricts [Migrate] to keys for which fn returns true.
+func WithKeyFilter(fn func(key string) bool) MigrateOption {
+	return func(c *migrateConfig) { c.filter = fn }
+}
+
+// Migrate copies every entry listed by src into dst and returns how many
+// were written. Entries are copied as-is, so [Entry.CreatedAt],
+// [Entry.Source] and other metadata are preserved. Migration stops at the
+// first error; entries written before it remain in dst.
+func Migrate(ctx context.Context, src, dst Store, opts ...MigrateOption) (int, error) {
+	cfg := migrateConfig{overwrite: true}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	entries, err := src.List(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("vault: migrate: list: %w", err)
+	}
+
+	migrated := 0
+	for _, e := range entries {
+		if cfg.filter != nil && !cfg.filter(e.Key) {
+			continue
+		}
+
+		if !cfg.overwrite {
+			found, err := exists(ctx, dst, e.Key)
+			if err != nil {
+				return migrated, fmt.Errorf("vault: migrate %q: %w", e.Key, err)
+			}
+			if found {
+				continue
+			}
+		}
+
+		if err := dst.Set(ctx, e); err != nil {
+			return migrated, fmt.Errorf("vault: migrate %q: %w", e.Key, err)
+		}
+		migrated++
+	}
+
+	return migrated, nil
+}
@@ -0,0 +1,101 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestMigrate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	created := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	src := vault.NewMemory()
+	require.NoError(t, src.Set(ctx, vault.Entry{Key: "a", Value: "1", Source: "ssm", CreatedAt: created}))
+	require.NoError(t, src.Set(ctx, vault.Entry{Key: "b", Value: "2", Source: "env", CreatedAt: created}))
+
+	dst := vault.NewMemory()
+	n, err := vault.Migrate(ctx, src, dst)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err := dst.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Value)
+	assert.Equal(t, "ssm", got.Source)
+	assert.True(t, created.Equal(got.CreatedAt))
+}
+
+func TestMigrate_skipExisting(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.NewMemory()
+	require.NoError(t, src.Set(ctx, vault.Entry{Key: "a", Value: "new"}))
+	require.NoError(t, src.Set(ctx, vault.Entry{Key: "b", Value: "new"}))
+
+	dst := vault.NewMemory()
+	require.NoError(t, dst.Set(ctx, vault.Entry{Key: "a", Value: "old"}))
+
+	n, err := vault.Migrate(ctx, src, dst, vault.WithOverwrite(false))
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	got, err := dst.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "old", got.Value)
+
+	n, err = vault.Migrate(ctx, src, dst)
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	got, err = dst.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Value)
+}
+
+func TestMigrate_keyFilter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.NewMemory()
+	for _, k := range []string{"db-host", "db-port", "api-token"} {
+		require.NoError(t, src.Set(ctx, vault.Entry{Key: k}))
+	}
+
+	dst := vault.NewMemory()
+	n, err := vault.Migrate(ctx, src, dst, vault.WithKeyFilter(func(key string) bool {
+		return strings.HasPrefix(key, "db-")
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, 2, n)
+
+	keys, err := dst.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-host", "db-port"}, keys)
+}
+
+func TestMigrate_listError(t *testing.T) {
+	t.Parallel()
+
+	errList := errors.New("unreachable")
+	_, err := vault.Migrate(context.Background(), &failListStore{Store: vault.NewMemory(), err: errList}, vault.NewMemory())
+	require.ErrorIs(t, err, errList)
+}
+
+// failListStore wraps a store so that List always fails.
+type failListStore struct {
+	vault.Store
+	err error
+}
+
+func (f *failListStore) List(_ context.Context) ([]vault.Entry, error) { return nil, f.err }
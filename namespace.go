@@ -0,0 +1,93 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInvalidNamespace is returned, wrapped, by [ValidateNamespace].
+var ErrInvalidNamespace = errors.New("vault: invalid namespace")
+
+// ValidateNamespace reports whether ns can be passed to
+// [Namespaced.WithNamespace]. A namespace is non-empty, valid UTF-8
+// text without control characters. Slashes may separate segments, as
+// in "team/prod", but may not appear at either end or next to each
+// other.
+//
+// Validation does not keep namespaces apart on its own. Stores that join
+// the namespace and key with a slash, such as [Memory], hold key "b/k"
+// of namespace "a" and key "k" of namespace "a/b" in the same place, so
+// nested namespaces should not be combined with keys containing slashes.
+//
+// The stores in this module panic with the returned error when given an
+// invalid namespace.
+func ValidateNamespace(ns string) error {
+	var reason string
+	switch {
+	case ns == "":
+		reason = "empty"
+	case !utf8.ValidString(ns):
+		reason = "not valid UTF-8"
+	case strings.ContainsFunc(ns, unicode.IsControl):
+		reason = "contains a control character"
+	case strings.HasPrefix(ns, "/") || strings.HasSuffix(ns, "/"):
+		reason = "leading or trailing slash"
+	case strings.Contains(ns, "//"):
+		reason = "empty segment"
+	default:
+		return nil
+	}
+	return fmt.Errorf("%w %q: %s", ErrInvalidNamespace, ns, reason)
+}
+
+// namespaceKey is the context key for [WithNamespaceContext].
+type namespaceKey struct{}
+
+// WithNamespaceContext returns a copy of ctx that scopes vault operations
+// to namespace ns, overriding the namespace the vault was created with.
+// It lets one [Vault] serve many tenants without a vault per request.
+//
+// [Vault.Get], and the methods built on it, [Vault.Set], [Vault.Delete]
+// and [Vault.List] honor it when the store implements [Namespaced], and
+// return an error wrapping [ErrInvalidNamespace] if ns is not valid.
+// Refreshes always write to the vault's own namespace, except the
+// single-key ones of [Vault.GetFresh], and of [Vault.Get] misses when
+// every source implements [KeyFetcher], which write to ns.
+func WithNamespaceContext(ctx context.Context, ns string) context.Context {
+	return context.WithValue(ctx, namespaceKey{}, ns)
+}
+
+// scoped returns the store that operations under ctx should use: the
+// vault's store, or the namespace set by [WithNamespaceContext]. It fails
+// with [ErrClosed] once the vault is closed.
+func (v *vault) scoped(ctx context.Context) (Store, error) {
+	if err := v.checkOpen(); err != nil {
+		return nil, err
+	}
+	ns, ok := ctx.Value(namespaceKey{}).(string)
+	if !ok {
+		return v.store, nil
+	}
+	if _, ok := v.config.store.(Namespaced); !ok {
+		return v.store, nil
+	}
+	if err := ValidateNamespace(ns); err != nil {
+		return nil, err
+	}
+	store, err := v.mount(ns)
+	if err != nil || !v.readOnly {
+		return store, err
+	}
+	return ReadOnly(store), nil
+}
+
+// mustValidateNamespace panics if ns is not a valid namespace.
+func mustValidateNamespace(ns string) {
+	if err := ValidateNamespace(ns); err != nil {
+		panic(err)
+	}
+}
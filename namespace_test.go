@@ -0,0 +1,200 @@
+package vault_test
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestValidateNamespace(t *testing.T) {
+	t.Parallel()
+
+	for _, ns := range []string{"prod", "team/prod", "a-b_c.d", "café", "with space"} {
+		assert.NoError(t, vault.ValidateNamespace(ns), ns)
+	}
+
+	for _, ns := range []string{"", "/prod", "prod/", "/", "a//b", "pr\x00od", "line\nbreak", "\xff"} {
+		assert.ErrorIs(t, vault.ValidateNamespace(ns), vault.ErrInvalidNamespace, "%q", ns)
+	}
+}
+
+func TestMemory_WithNamespace_invalid(t *testing.T) {
+	t.Parallel()
+
+	m := vault.NewMemory()
+	require.PanicsWithError(t, `vault: invalid namespace "prod/": leading or trailing slash`, func() {
+		m.WithNamespace("prod/")
+	})
+	require.Panics(t, func() {
+		vault.New(vault.WithStore(m), vault.WithNamespace("/"))
+	})
+	require.NotPanics(t, func() {
+		m.WithNamespace("team/prod")
+	})
+}
+
+func TestWithNamespaceContext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithNamespace("default"))
+
+	var wg sync.WaitGroup
+	for _, tenant := range []string{"acme", "globex"} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tctx := vault.WithNamespaceContext(ctx, tenant)
+			for i := range 50 {
+				key := fmt.Sprintf("k%d", i)
+				assert.NoError(t, v.Set(tctx, vault.Entry{Key: key, Value: tenant}))
+
+				got, err := v.Get(tctx, key)
+				if assert.NoError(t, err) {
+					assert.Equal(t, tenant, got.Value)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, tenant := range []string{"acme", "globex"} {
+		entries, err := v.List(vault.WithNamespaceContext(ctx, tenant))
+		require.NoError(t, err)
+		require.Len(t, entries, 50)
+		for _, e := range entries {
+			assert.Equal(t, tenant, e.Value)
+		}
+	}
+
+	entries, err := v.List(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the vault's own namespace is untouched")
+
+	acme := vault.WithNamespaceContext(ctx, "acme")
+	require.NoError(t, v.Delete(acme, "k0"))
+	_, err = store.WithNamespace("acme").Get(ctx, "k0")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	_, err = store.WithNamespace("globex").Get(ctx, "k0")
+	require.NoError(t, err)
+}
+
+func TestWithNamespaceContext_readsIsolated(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithNamespace("default"))
+	require.NoError(t, v.Set(ctx, vault.Entry{
+		Key:         "secret",
+		Value:       "default-only",
+		CreatedAt:   time.Now().Add(-48 * time.Hour),
+		RotateEvery: time.Hour,
+	}))
+	tenant := vault.WithNamespaceContext(ctx, "acme")
+
+	t.Run("GetMany", func(t *testing.T) {
+		t.Parallel()
+		found, err := v.GetMany(tenant, []string{"secret"})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	})
+	t.Run("Exists", func(t *testing.T) {
+		t.Parallel()
+		ok, err := v.Exists(tenant, "secret")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+	t.Run("ListFresh", func(t *testing.T) {
+		t.Parallel()
+		entries, err := v.ListFresh(tenant)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+	t.Run("Stale", func(t *testing.T) {
+		t.Parallel()
+		entries, err := v.Stale(tenant, time.Hour)
+		require.NoError(t, err)
+		assert.Empty(t, entries)
+	})
+	t.Run("Keys", func(t *testing.T) {
+		t.Parallel()
+		keys, err := v.Keys(tenant)
+		require.NoError(t, err)
+		assert.Empty(t, keys)
+	})
+	t.Run("Snapshot", func(t *testing.T) {
+		t.Parallel()
+		snap, err := v.Snapshot(tenant)
+		require.NoError(t, err)
+		assert.Empty(t, snap)
+	})
+	t.Run("DueForRotation", func(t *testing.T) {
+		t.Parallel()
+		due, err := v.DueForRotation(tenant)
+		require.NoError(t, err)
+		assert.Empty(t, due)
+
+		due, err = v.DueForRotation(ctx)
+		require.NoError(t, err)
+		assert.Len(t, due, 1, "the vault's own namespace still has it")
+	})
+}
+
+func TestWithNamespaceContext_invalid(t *testing.T) {
+	t.Parallel()
+
+	ctx := vault.WithNamespaceContext(context.Background(), "bad/")
+	_, err := vault.New().Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrInvalidNamespace)
+}
+
+func TestNewE_strictNamespace(t *testing.T) {
+	t.Parallel()
+
+	_, err := vault.NewE(
+		vault.WithStore(listOnlyStore{vault.NewMemory()}),
+		vault.WithNamespace("prod"),
+		vault.WithStrictNamespace(),
+	)
+	require.ErrorIs(t, err, vault.ErrNotNamespaced)
+	assert.Contains(t, err.Error(), "listOnlyStore")
+
+	assert.Panics(t, func() {
+		vault.New(vault.WithStore(listOnlyStore{vault.NewMemory()}), vault.WithNamespace("prod"), vault.WithStrictNamespace())
+	})
+
+	v, err := vault.NewE(vault.WithNamespace("prod"), vault.WithStrictNamespace())
+	require.NoError(t, err)
+	assert.True(t, v.Describe().NamespaceActive)
+}
+
+func TestNewE_lenientNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v, err := vault.NewE(vault.WithStore(listOnlyStore{vault.NewMemory()}), vault.WithNamespace("prod"))
+	require.NoError(t, err)
+	assert.False(t, v.Describe().NamespaceActive)
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+}
+
+func TestNewE_invalidNamespace(t *testing.T) {
+	t.Parallel()
+
+	_, err := vault.NewE(vault.WithNamespace("/bad"))
+	require.ErrorIs(t, err, vault.ErrInvalidNamespace)
+}
@@ -0,0 +1,90 @@
+package vault
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// maxNegativeEntries bounds the negative cache, so that misses of many
+// distinct keys cannot grow it without limit.
+const maxNegativeEntries = 10000
+
+// negativeCache remembers keys that were still missing after an
+// auto-refresh, for [WithNegativeCacheTTL].
+type negativeCache struct {
+	mu    sync.Mutex
+	until map[targetKey]time.Time
+
+	// sweepAt is the size at which the next insert drops expired
+	// entries. It doubles with the live size so that sweeps stay cheap.
+	sweepAt int
+}
+
+// negativeKey identifies key within the namespace ctx is scoped to.
+func negativeKey(ctx context.Context, key string) targetKey {
+	ns, _ := ctx.Value(namespaceKey{}).(string) //nolint:errcheck // absent means the vault's own namespace
+	return targetKey{namespace: ns, key: key}
+}
+
+// knownMissing reports whether key was found missing by a refresh less
+// than the negative cache TTL ago.
+func (v *vault) knownMissing(ctx context.Context, key string) bool {
+	if v.negativeTTL <= 0 {
+		return false
+	}
+
+	v.negative.mu.Lock()
+	defer v.negative.mu.Unlock()
+
+	tk := negativeKey(ctx, key)
+	until, ok := v.negative.until[tk]
+	if !ok {
+		return false
+	}
+	if !v.now().Before(until) {
+		delete(v.negative.until, tk)
+		return false
+	}
+	return true
+}
+
+// rememberMissing records that keys were missing after a refresh.
+func (v *vault) rememberMissing(ctx context.Context, keys ...string) {
+	if v.negativeTTL <= 0 || len(keys) == 0 {
+		return
+	}
+
+	v.negative.mu.Lock()
+	defer v.negative.mu.Unlock()
+
+	if v.negative.until == nil {
+		v.negative.until = make(map[targetKey]time.Time)
+	}
+	now := v.now()
+	if len(v.negative.until)+len(keys) > v.negative.sweepAt {
+		v.negative.sweep(now, len(keys))
+	}
+	until := now.Add(v.negativeTTL)
+	for _, key := range keys {
+		v.negative.until[negativeKey(ctx, key)] = until
+	}
+}
+
+// sweep drops the entries that expired by now and then, if room is still
+// needed for n more within [maxNegativeEntries], arbitrary others. The
+// caller holds c.mu.
+func (c *negativeCache) sweep(now time.Time, n int) {
+	for tk, until := range c.until {
+		if !now.Before(until) {
+			delete(c.until, tk)
+		}
+	}
+	for tk := range c.until {
+		if len(c.until)+n <= maxNegativeEntries {
+			break
+		}
+		delete(c.until, tk)
+	}
+	c.sweepAt = min(max(2*len(c.until), 64), maxNegativeEntries)
+}
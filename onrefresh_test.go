@@ -0,0 +1,112 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestWithOnRefresh_reportsChanges(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var entries atomic.Pointer[[]vault.Entry]
+	set := func(es ...vault.Entry) { entries.Store(&es) }
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return *entries.Load(), nil
+	})
+
+	var calls [][]vault.Change
+	v := vault.New(vault.WithSource(src), vault.WithOnRefresh(func(changed []vault.Change) {
+		calls = append(calls, changed)
+	}))
+
+	set(vault.Entry{Key: "b", Value: "1"}, vault.Entry{Key: "a", Value: "1"})
+	require.NoError(t, v.Refresh(ctx))
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0], 2)
+	assert.Equal(t, "a", calls[0][0].Key)
+	assert.Equal(t, vault.DiffAdd, calls[0][0].Op)
+	assert.Equal(t, "b", calls[0][1].Key)
+	assert.Equal(t, vault.DiffAdd, calls[0][1].Op)
+
+	set(vault.Entry{Key: "a", Value: "1"}, vault.Entry{Key: "b", Value: "2"})
+	require.NoError(t, v.Refresh(ctx))
+	require.Len(t, calls, 2)
+	require.Len(t, calls[1], 1)
+	c := calls[1][0]
+	assert.Equal(t, vault.DiffUpdate, c.Op)
+	assert.Equal(t, "b", c.Key)
+	assert.Equal(t, "1", c.Old.Value)
+	assert.Equal(t, "2", c.New.Value)
+
+	require.NoError(t, v.Refresh(ctx))
+	assert.Len(t, calls, 2, "the callback is not called when nothing changed")
+}
+
+func TestWithOnRefresh_notUnderLock(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "refreshed"}}, nil
+	})
+
+	var v vault.Vault
+	done := make(chan error, 1)
+	v = vault.New(vault.WithSource(src), vault.WithOnRefresh(func([]vault.Change) {
+		done <- v.Set(ctx, vault.Entry{Key: "k", Value: "set"})
+	}))
+
+	refreshed := make(chan error, 1)
+	go func() { refreshed <- v.Refresh(ctx) }()
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("Set from the callback blocked on the refresh")
+	}
+	require.NoError(t, <-refreshed)
+
+	e, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "set", e.Value)
+}
+
+func TestWithOnRefresh_refreshReportAndGetFresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var value atomic.Pointer[string]
+	set := func(s string) { value.Store(&s) }
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: *value.Load()}}, nil
+	})
+
+	var calls [][]vault.Change
+	v := vault.New(vault.WithSource(src), vault.WithOnRefresh(func(changed []vault.Change) {
+		calls = append(calls, changed)
+	}))
+
+	set("1")
+	_, err := v.RefreshReport(ctx)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0], 1)
+	assert.Equal(t, vault.DiffAdd, calls[0][0].Op)
+
+	set("2")
+	_, err = v.GetFresh(ctx, "k")
+	require.NoError(t, err)
+	require.Len(t, calls, 2)
+	require.Len(t, calls[1], 1)
+	assert.Equal(t, vault.DiffUpdate, calls[1][0].Op)
+	assert.Equal(t, "2", calls[1][0].New.Value)
+}
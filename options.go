@@ -6,10 +6,51 @@ import "time"
 type Option func(*config)
 
 type config struct {
-	store     Store
-	sources   []Source
-	namespace string
-	ttl       time.Duration
+	store          Store
+	sources        []Source
+	namespace      string
+	ttl            time.Duration
+	failFast       bool
+	skipReadOnly   bool
+	maxFetches     int
+	selector       func(sourceName, key string) bool
+	base64         bool
+	observer       Observer
+	sourceTTLs     map[string]time.Duration
+	restamp        bool
+	maxConcurrency int
+	interval       time.Duration
+	onRefreshError func(error)
+	onRefresh      func(changed []Change)
+	broker         Broker
+	logger         Logger
+	metrics        Metrics
+	merge          MergeFunc
+	readOnly       bool
+	retries        int
+	retryBackoff   time.Duration
+	retryIf        func(error) bool
+	sourceTimeout  time.Duration
+	maxValueSize   int
+	skipOversized  bool
+	clock          Clock
+
+	staleWhileRevalidate bool
+	serveStaleOnError    bool
+	negativeTTL          time.Duration
+	overwriteOnRename    bool
+	ttlJitter            float64
+	strictNamespace      bool
+	breakerThreshold     int
+	breakerCooldown      time.Duration
+}
+
+// decorate wraps s with the store-boundary behavior the options ask for.
+func (c *config) decorate(s Store) Store {
+	if c.base64 {
+		s = base64Store{s}
+	}
+	return s
 }
 
 // WithStore sets the backing store for the vault.
@@ -31,6 +72,14 @@ func WithNamespace(ns string) Option {
 	return func(c *config) { c.namespace = ns }
 }
 
+// WithStrictNamespace makes a namespace set with [WithNamespace] on a
+// store that does not implement [Namespaced] a configuration error rather
+// than silently ignored: [NewE] returns an error wrapping
+// [ErrNotNamespaced] and [New] panics with it.
+func WithStrictNamespace() Option {
+	return func(c *config) { c.strictNamespace = true }
+}
+
 // WithTTL sets the time-to-live for cached entries. When set, entries
 // older than the TTL are considered expired and trigger an automatic
 // refresh from sources on the next [Vault.Get]. A zero TTL means
@@ -38,3 +87,298 @@ func WithNamespace(ns string) Option {
 func WithTTL(d time.Duration) Option {
 	return func(c *config) { c.ttl = d }
 }
+
+// WithTTLJitter spreads out expiry so that vaults started together do not
+// all refresh at the same instant. Each entry's TTL, global or
+// per-source, is shortened by a fraction of itself chosen from
+// [0, fraction), so with a one-hour TTL and a fraction of 0.1 entries
+// expire between 54 and 60 minutes after they were created. The amount is
+// derived from a hash of the key that is seeded randomly per vault: it is
+// the same for every read within one vault but differs between vaults. An
+// [Entry.ExpiresAt] is never jittered. fraction is clamped to [0, 1].
+func WithTTLJitter(fraction float64) Option {
+	return func(c *config) { c.ttlJitter = min(max(fraction, 0), 1) }
+}
+
+// WithFailFastOnRefreshError makes [Vault.Get] stop retrying a failing
+// refresh. After a refresh fails, a miss within the backoff window (one
+// TTL period, or until the next successful refresh when no TTL is set)
+// returns the last refresh error immediately. An expired entry still
+// present in the store is served stale instead of the error.
+func WithFailFastOnRefreshError() Option {
+	return func(c *config) { c.failFast = true }
+}
+
+// WithStaleWhileRevalidate makes [Vault.Get] return an expired entry
+// immediately instead of waiting for an auto-refresh, which instead runs
+// in the background, subject to the usual limits, so that later reads see
+// the fresh value. Only one background refresh runs at a time, and its
+// failures go to the handler set by [WithRefreshErrorHandler]. A missing
+// entry is still refreshed synchronously. An expired entry is only served
+// stale while a refresh could replace it; when the vault has no sources,
+// or a refresh since the entry expired did not supply it, the entry is
+// reported missing as without this option.
+func WithStaleWhileRevalidate() Option {
+	return func(c *config) { c.staleWhileRevalidate = true }
+}
+
+// WithServeStaleOnError makes [Vault.Get] return an expired entry, logged
+// at warn level, when the auto-refresh it triggers fails, rather than the
+// refresh error. [Vault.GetWithMeta] reports such an entry as stale. The
+// error is still returned for a key with no stored value at all, and by
+// [Vault.GetMany] when any requested key is missing.
+func WithServeStaleOnError() Option {
+	return func(c *config) { c.serveStaleOnError = true }
+}
+
+// WithNegativeCacheTTL makes the vault remember, for d, each key that was
+// still missing after an auto-refresh. Within that time [Vault.Get] and
+// [Vault.GetMany] report the key as not found without refreshing again,
+// even once the TTL window allows it. Keys that exist are unaffected, and
+// a key that appears in the store, through [Vault.Set] or an explicit
+// [Vault.Refresh], is returned as usual. Expired keys are pruned as new
+// ones are added, and at most 10,000 are remembered at once. Zero
+// disables negative caching.
+func WithNegativeCacheTTL(d time.Duration) Option {
+	return func(c *config) { c.negativeTTL = d }
+}
+
+// WithOverwriteOnRename lets [Vault.Rename] replace an entry that already
+// exists under the new key. By default it fails with [ErrKeyExists].
+func WithOverwriteOnRename() Option {
+	return func(c *config) { c.overwriteOnRename = true }
+}
+
+// WithSkipReadOnly makes writes to entries marked [Entry.ReadOnly] a
+// silent no-op. By default, [Vault.Set] and [Vault.Refresh] return
+// [ErrReadOnly] when they would overwrite such an entry, and
+// [Vault.Delete], [Vault.DeleteMany] and [Vault.Rename] when they would
+// remove one.
+func WithSkipReadOnly() Option {
+	return func(c *config) { c.skipReadOnly = true }
+}
+
+// WithFetchSemaphore bounds the number of [Source.Fetch] calls that may
+// run at once across every refresh path of the vault. Callers waiting for
+// a slot give up when their context is cancelled. Values less than one
+// leave fetches unbounded.
+func WithFetchSemaphore(n int) Option {
+	return func(c *config) { c.maxFetches = n }
+}
+
+// WithSourceSelector partitions the keyspace across sources. During
+// [Vault.Refresh], each fetched entry is kept only if fn reports that the
+// source that produced it may supply its key. Sources are identified by
+// their [Named] name, or "source N" by position when unnamed.
+func WithSourceSelector(fn func(sourceName, key string) bool) Option {
+	return func(c *config) { c.selector = fn }
+}
+
+// WithReadOnly wraps the configured store with [ReadOnly], so that
+// [Vault.Set], [Vault.Delete] and [Vault.Rotate] fail with [ErrReadOnly].
+// Refreshes, automatic or explicit, still populate the store from sources,
+// since that caches what the sources hold rather than changing it.
+func WithReadOnly() Option {
+	return func(c *config) { c.readOnly = true }
+}
+
+// WithMergeFunc sets how [Vault.Refresh] resolves a key produced by more
+// than one source for the same store. fn is called in source order with
+// the entry merged so far and the next one, and the entry it returns is
+// written, including its [Entry.Source]. Without this option the later
+// source wins.
+func WithMergeFunc(fn MergeFunc) Option {
+	return func(c *config) { c.merge = fn }
+}
+
+// WithSourcePriority makes earlier sources win when several produce the
+// same key, so the stored entry, and its [Entry.Source], come from the
+// first source that has it. It is shorthand for WithMergeFunc(FirstWins).
+func WithSourcePriority() Option {
+	return WithMergeFunc(FirstWins)
+}
+
+// WithBase64Values base64-encodes [Entry.Value] before it reaches the
+// store and decodes it on the way back, so values containing newlines,
+// NUL bytes, or other binary data survive backends that only handle
+// simple strings. Encoding is transparent to callers of the vault.
+// Existing unencoded values are still read back as stored.
+func WithBase64Values() Option {
+	return func(c *config) { c.base64 = true }
+}
+
+// WithObserver attaches an [Observer] that is notified when refreshes
+// start and finish.
+func WithObserver(o Observer) Option {
+	return func(c *config) { c.observer = o }
+}
+
+// WithSourceTTL sets the time-to-live for entries whose [Entry.Source] is
+// sourceName, overriding [WithTTL] for them. This lets fast-changing
+// sources expire sooner than slow ones. Auto-refresh runs at most once per
+// the shortest configured TTL.
+func WithSourceTTL(sourceName string, ttl time.Duration) Option {
+	return func(c *config) {
+		if c.sourceTTLs == nil {
+			c.sourceTTLs = make(map[string]time.Duration)
+		}
+		c.sourceTTLs[sourceName] = ttl
+	}
+}
+
+// WithRestampSeededEntries resets, on first use, the [Entry.CreatedAt] of
+// stored entries that did not come from a source (those whose
+// [Entry.Source] is empty or "manual") to the current time. Without it,
+// seeded data with old timestamps is treated as expired as soon as a TTL
+// is configured and, with no source to refresh from, becomes unreachable.
+func WithRestampSeededEntries() Option {
+	return func(c *config) { c.restamp = true }
+}
+
+// WithSourceNamespace adds a source whose entries are always written to
+// namespace ns of the configured store, regardless of the vault's own
+// namespace. This lets shared configuration live alongside scoped
+// configuration. The store must implement [Namespaced]; otherwise
+// [Vault.Refresh] fails with [ErrNotNamespaced].
+func WithSourceNamespace(src Source, ns string) Option {
+	return func(c *config) {
+		c.sources = append(c.sources, &mountedSource{Source: src, namespace: ns})
+	}
+}
+
+// mountedSource is a source added with [WithSourceNamespace].
+type mountedSource struct {
+	Source
+	namespace string
+}
+
+// WithRetry retries a failing [Source.Fetch] until it has been attempted
+// attempts times in all. The wait before the first retry is backoff, and
+// doubles after each further failure up to 30 seconds, or backoff if that
+// is longer, with up to 50% random jitter added so that many vaults do
+// not retry in lockstep. A negative backoff is treated as zero. Waiting
+// stops early if the context is cancelled. When every attempt fails, the
+// last error is returned. By default any error not caused by the context
+// is retried; see [WithRetryIf].
+func WithRetry(attempts int, backoff time.Duration) Option {
+	return func(c *config) {
+		c.retries = attempts
+		c.retryBackoff = max(backoff, 0)
+	}
+}
+
+// WithRetryIf overrides which fetch errors [WithRetry] retries. fn reports
+// whether err is worth another attempt.
+func WithRetryIf(fn func(err error) bool) Option {
+	return func(c *config) { c.retryIf = fn }
+}
+
+// WithCircuitBreaker stops fetching a source that keeps failing. After
+// threshold consecutive failed fetches, counting each fetch once however
+// many times [WithRetry] attempted it, the source is skipped for cooldown:
+// refreshes fail for it with a [*SourceError] wrapping [ErrCircuitOpen]
+// without calling it, which [Vault.RefreshReport] reports per source.
+// After the cooldown the source is tried again; a success resets the
+// count and a failure skips it for another cooldown. Fetches abandoned
+// because their context ended do not count. A threshold less than one
+// disables the breaker.
+func WithCircuitBreaker(threshold int, cooldown time.Duration) Option {
+	return func(c *config) {
+		c.breakerThreshold = threshold
+		c.breakerCooldown = cooldown
+	}
+}
+
+// WithSourceTimeout bounds each [Source.Fetch] call, and each retry, to d.
+// A source that has not returned in time fails with an error wrapping
+// [context.DeadlineExceeded] and naming it, which the refresh handles as
+// any other fetch failure. The source's context is cancelled, but a
+// source that ignores its context is abandoned rather than waited for.
+// An abandoned fetch keeps its [WithFetchSemaphore] slot until it does
+// return, so sources that hang can still use up the semaphore. Timed-out
+// fetches are retried by default when [WithRetry] is also set.
+func WithSourceTimeout(d time.Duration) Option {
+	return func(c *config) { c.sourceTimeout = d }
+}
+
+// WithMaxValueSize rejects entries whose value is longer than n bytes,
+// for stores such as some OS keychains that cap secret size. [Vault.Set]
+// returns [ErrValueTooLarge] for such an entry, and so does
+// [Vault.Refresh] unless [WithSkipOversized] is set. Values of n less
+// than one disable the limit.
+func WithMaxValueSize(n int) Option {
+	return func(c *config) { c.maxValueSize = n }
+}
+
+// WithSkipOversized makes [Vault.Refresh] skip source entries that exceed
+// [WithMaxValueSize], logging a warning for each, instead of failing.
+func WithSkipOversized() Option {
+	return func(c *config) { c.skipOversized = true }
+}
+
+// WithMaxConcurrency caps how many sources [Vault.Refresh] fetches at
+// once. By default all sources are fetched in parallel. Unlike
+// [WithFetchSemaphore], the cap applies to each refresh separately.
+func WithMaxConcurrency(n int) Option {
+	return func(c *config) { c.maxConcurrency = n }
+}
+
+// WithRefreshInterval sets how often the background loop launched by
+// [Vault.Start] calls [Vault.Refresh]. Without it, Start does nothing.
+func WithRefreshInterval(d time.Duration) Option {
+	return func(c *config) { c.interval = d }
+}
+
+// WithRefreshErrorHandler sets a function that receives the error from
+// each failed background refresh. It is called from the background
+// goroutine and should return quickly.
+func WithRefreshErrorHandler(fn func(error)) Option {
+	return func(c *config) { c.onRefreshError = fn }
+}
+
+// WithOnRefresh sets a function that receives the keys each successful
+// refresh, explicit, automatic or in the background, added or changed,
+// including the single-key refreshes of [Vault.GetFresh] and of misses
+// when every source implements [KeyFetcher]. Each stored value is read
+// before it is overwritten and compared by value, as [Diff] does; keys no
+// source produces are not reported, since refreshes never remove them.
+// Changes are sorted by namespace, then key, and fn is not called when
+// nothing changed. fn runs on the goroutine that refreshed, after the
+// refresh has released its locks, so it may read and write the vault.
+func WithOnRefresh(fn func(changed []Change)) Option {
+	return func(c *config) { c.onRefresh = fn }
+}
+
+// WithBroker connects the vault to other vaults that cache the same
+// configuration in their own stores. [Vault.Set], [Vault.Delete],
+// [Vault.DeleteMany] and [Vault.Rename] publish the keys they change to
+// b, as do refreshes for keys whose values they update. A vault with
+// sources drops the keys that other vaults publish from its store, so
+// that its next read misses and refreshes from the sources. A vault
+// without sources only publishes: its store holds the only copy of its
+// entries, which an invalidation would delete for good, bypassing
+// [WithReadOnly]. Vaults that share a store have nothing to invalidate
+// and should not share a broker. [Vault.Close] unsubscribes the vault.
+func WithBroker(b Broker) Option {
+	return func(c *config) { c.broker = b }
+}
+
+// WithLogger sets the [Logger] the vault reports to. Auto-refreshes and
+// source fetches are logged at debug level, failed fetches and refreshes
+// at warn level, and store failures at error level.
+func WithLogger(l Logger) Option {
+	return func(c *config) { c.logger = l }
+}
+
+// WithClock sets the [Clock] used for entry timestamps, expiry, rotation
+// schedules and the auto-refresh window. Durations reported to
+// [WithLogger] and [WithMetrics] are still measured with the real clock.
+func WithClock(clock Clock) Option {
+	return func(c *config) { c.clock = clock }
+}
+
+// WithMetrics sets the [Metrics] the vault reports cache hits, misses and
+// refresh timings to.
+func WithMetrics(m Metrics) Option {
+	return func(c *config) { c.metrics = m }
+}
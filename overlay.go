@@ -0,0 +1,114 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// tombstoneSource is the [Entry.Source] of the entries [Overlay] writes to
+// hide deleted keys.
+const tombstoneSource = "vault:tombstone"
+
+// Overlay layers local overrides over a base store that is never written,
+// for example a memory or file store of development values over the
+// production keychain.
+//
+// Get reads overlay first and falls back to base. Set writes only to
+// overlay. Delete writes a tombstone, an entry with no value whose
+// [Entry.Source] is "vault:tombstone", to overlay, so that the key reads as
+// missing even if base has it; a later Set replaces the tombstone. List
+// merges both stores with overlay winning, leaving out tombstoned keys.
+// Unlike [Chain], nothing read from base is copied into overlay. Close
+// closes both stores, where they implement [io.Closer].
+//
+// If both stores implement [Namespaced], so does the returned store.
+func Overlay(base, overlay Store) Store {
+	o := overlayStore{base: base, overlay: overlay}
+	bns, ok := base.(Namespaced)
+	if !ok {
+		return o
+	}
+	ons, ok := overlay.(Namespaced)
+	if !ok {
+		return o
+	}
+	return namespacedOverlayStore{overlayStore: o, base: bns, overlay: ons}
+}
+
+type overlayStore struct {
+	base    Store
+	overlay Store
+}
+
+type namespacedOverlayStore struct {
+	overlayStore
+	base    Namespaced
+	overlay Namespaced
+}
+
+func (o namespacedOverlayStore) WithNamespace(namespace string) Store {
+	return Overlay(o.base.WithNamespace(namespace), o.overlay.WithNamespace(namespace))
+}
+
+func (o overlayStore) Get(ctx context.Context, key string) (Entry, error) {
+	e, err := o.overlay.Get(ctx, key)
+	switch {
+	case err == nil && e.Source == tombstoneSource:
+		return Entry{}, fmt.Errorf("vault: overlay: %w", &StoreError{Op: "get", Key: key, Err: ErrNotFound})
+	case err == nil:
+		return e, nil
+	case !errors.Is(err, ErrNotFound):
+		return Entry{}, fmt.Errorf("vault: overlay: %w", err)
+	}
+
+	e, err = o.base.Get(ctx, key)
+	if err != nil {
+		return Entry{}, fmt.Errorf("vault: overlay: base: %w", err)
+	}
+	return e, nil
+}
+
+func (o overlayStore) Set(ctx context.Context, entry Entry) error {
+	if err := o.overlay.Set(ctx, entry); err != nil {
+		return fmt.Errorf("vault: overlay: %w", err)
+	}
+	return nil
+}
+
+func (o overlayStore) Delete(ctx context.Context, key string) error {
+	if err := o.overlay.Set(ctx, Entry{Key: key, Source: tombstoneSource}); err != nil {
+		return fmt.Errorf("vault: overlay: %w", err)
+	}
+	return nil
+}
+
+func (o overlayStore) Close() error {
+	var errs []error
+	if err := closeStore(o.overlay); err != nil {
+		errs = append(errs, fmt.Errorf("vault: overlay: %w", err))
+	}
+	if err := closeStore(o.base); err != nil {
+		errs = append(errs, fmt.Errorf("vault: overlay: base: %w", err))
+	}
+	return errors.Join(errs...)
+}
+
+func (o overlayStore) List(ctx context.Context) ([]Entry, error) {
+	overrides, err := o.overlay.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault: overlay: %w", err)
+	}
+	base, err := o.base.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("vault: overlay: base: %w", err)
+	}
+
+	var entries []Entry
+	for _, e := range mergeEntries([][]Entry{overrides, base}, FirstWins) {
+		if e.Source != tombstoneSource {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
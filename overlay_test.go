@@ -0,0 +1,126 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestOverlay_overridesBase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base, local := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "db-host", Value: "prod.db"}))
+	o := vault.Overlay(base, local)
+
+	require.NoError(t, o.Set(ctx, vault.Entry{Key: "db-host", Value: "localhost"}))
+
+	e, err := o.Get(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "localhost", e.Value)
+
+	e, err = base.Get(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "prod.db", e.Value, "the base store is never written")
+}
+
+func TestOverlay_passesThroughToBase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base, local := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "api-url", Value: "https://api"}))
+	o := vault.Overlay(base, local)
+
+	e, err := o.Get(ctx, "api-url")
+	require.NoError(t, err)
+	assert.Equal(t, "https://api", e.Value)
+
+	_, err = local.Get(ctx, "api-url")
+	require.ErrorIs(t, err, vault.ErrNotFound, "reads are not copied into the overlay")
+
+	_, err = o.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestOverlay_tombstoneHidesBase(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base, local := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+	o := vault.Overlay(base, local)
+
+	require.NoError(t, o.Delete(ctx, "a"))
+
+	_, err := o.Get(ctx, "a")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	_, err = base.Get(ctx, "a")
+	require.NoError(t, err, "the base entry is hidden, not deleted")
+
+	entries, err := o.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "b", entries[0].Key)
+
+	require.NoError(t, o.Set(ctx, vault.Entry{Key: "a", Value: "local"}))
+	e, err := o.Get(ctx, "a")
+	require.NoError(t, err)
+	assert.Equal(t, "local", e.Value)
+}
+
+func TestOverlay_listPrefersOverlay(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base, local := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "k", Value: "base"}))
+	require.NoError(t, base.Set(ctx, vault.Entry{Key: "only-base", Value: "b"}))
+	require.NoError(t, local.Set(ctx, vault.Entry{Key: "k", Value: "local"}))
+	require.NoError(t, local.Set(ctx, vault.Entry{Key: "only-local", Value: "l"}))
+
+	entries, err := vault.Overlay(base, local).List(ctx)
+	require.NoError(t, err)
+
+	got := make(map[string]string)
+	for _, e := range entries {
+		got[e.Key] = e.Value
+	}
+	assert.Equal(t, map[string]string{"k": "local", "only-base": "b", "only-local": "l"}, got)
+}
+
+func TestOverlay_errorsSurface(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	boom := errors.New("boom")
+	o := vault.Overlay(vault.NewMemory(), &failStore{err: boom})
+
+	_, err := o.Get(ctx, "k")
+	require.ErrorIs(t, err, boom)
+}
+
+func TestOverlay_namespaced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	base, local := vault.NewMemory(), vault.NewMemory()
+	require.NoError(t, base.WithNamespace("prod").Set(ctx, vault.Entry{Key: "k", Value: "prod"}))
+
+	o, ok := vault.Overlay(base, local).(vault.Namespaced)
+	require.True(t, ok)
+	prod := o.WithNamespace("prod")
+
+	require.NoError(t, prod.Delete(ctx, "k"))
+	_, err := prod.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	_, err = local.WithNamespace("prod").Get(ctx, "k")
+	require.NoError(t, err, "the tombstone lives in the overlay's namespace")
+}
@@ -0,0 +1,71 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+)
+
+// ReadOnly wraps inner so that Set and Delete fail with [ErrReadOnly]
+// while reads pass through. Close closes inner, if it implements
+// [io.Closer]. If inner implements [Namespaced], so does the returned
+// store, and its namespaces are read-only too.
+func ReadOnly(inner Store) Store {
+	s := readOnlyStore{Store: inner}
+	if ns, ok := inner.(Namespaced); ok {
+		return namespacedReadOnlyStore{readOnlyStore: s, ns: ns}
+	}
+	return s
+}
+
+type readOnlyStore struct {
+	Store
+}
+
+type namespacedReadOnlyStore struct {
+	readOnlyStore
+	ns Namespaced
+}
+
+func (s namespacedReadOnlyStore) WithNamespace(namespace string) Store {
+	return ReadOnly(s.ns.WithNamespace(namespace))
+}
+
+func (s readOnlyStore) GetMany(ctx context.Context, keys []string) (map[string]Entry, error) {
+	return getMany(ctx, s.Store, keys)
+}
+
+func (s readOnlyStore) Exists(ctx context.Context, key string) (bool, error) {
+	return exists(ctx, s.Store, key)
+}
+
+func (s readOnlyStore) Keys(ctx context.Context) ([]string, error) {
+	return listKeys(ctx, s.Store)
+}
+
+func (s readOnlyStore) Snapshot(ctx context.Context) (map[string]Entry, error) {
+	return snapshot(ctx, s.Store)
+}
+
+func (s readOnlyStore) ListByTag(ctx context.Context, key, value string) ([]Entry, error) {
+	return listByTag(ctx, s.Store, key, value)
+}
+
+func (s readOnlyStore) ListPrefix(ctx context.Context, prefix string) ([]Entry, error) {
+	return listPrefix(ctx, s.Store, prefix)
+}
+
+func (s readOnlyStore) KeysPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return keysPrefix(ctx, s.Store, prefix)
+}
+
+func (s readOnlyStore) Close() error {
+	return closeStore(s.Store)
+}
+
+func (s readOnlyStore) Set(_ context.Context, entry Entry) error {
+	return fmt.Errorf("vault: set %q: %w", entry.Key, ErrReadOnly)
+}
+
+func (s readOnlyStore) Delete(_ context.Context, key string) error {
+	return fmt.Errorf("vault: delete %q: %w", key, ErrReadOnly)
+}
@@ -0,0 +1,62 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	require.NoError(t, inner.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	store := vault.ReadOnly(inner)
+
+	require.ErrorIs(t, store.Set(ctx, vault.Entry{Key: "k", Value: "changed"}), vault.ErrReadOnly)
+	require.ErrorIs(t, store.Delete(ctx, "k"), vault.ErrReadOnly)
+
+	got, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	ns, ok := store.(vault.Namespaced)
+	require.True(t, ok)
+	require.ErrorIs(t, ns.WithNamespace("prod").Set(ctx, vault.Entry{Key: "k"}), vault.ErrReadOnly)
+}
+
+func TestWithReadOnly(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "from-source"}}, nil
+	})
+	v := vault.New(vault.WithSource(src), vault.WithReadOnly())
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err, "auto-refresh still populates the store")
+	assert.Equal(t, "from-source", got.Value)
+
+	require.ErrorIs(t, v.Set(ctx, vault.Entry{Key: "k", Value: "changed"}), vault.ErrReadOnly)
+	require.ErrorIs(t, v.Delete(ctx, "k"), vault.ErrReadOnly)
+	_, err = v.Rotate(ctx, "k", func(context.Context, vault.Entry) (string, error) { return "new", nil })
+	require.ErrorIs(t, err, vault.ErrReadOnly)
+
+	tenant := vault.WithNamespaceContext(ctx, "tenant")
+	require.ErrorIs(t, v.Set(tenant, vault.Entry{Key: "k"}), vault.ErrReadOnly)
+
+	got, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "from-source", got.Value)
+}
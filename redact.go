@@ -0,0 +1,37 @@
+package vault
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// redacted replaces [Entry.Value] in formatted output.
+const redacted = "****"
+
+// Reveal returns the raw value of the entry. [Entry.String] and
+// formatting with the fmt package never include it.
+func (e Entry) Reveal() string { return e.Value }
+
+// String describes the entry with its value redacted, e.g.
+// {key=db-password value=**** source=ssm created_at=2024-01-02T15:04:05Z}.
+func (e Entry) String() string {
+	return fmt.Sprintf("{key=%s value=%s source=%s created_at=%s}",
+		e.Key, redacted, e.Source, e.CreatedAt.Format(time.RFC3339))
+}
+
+// Format implements [fmt.Formatter] so that every verb, including %+v and
+// %#v, prints the entry with its value redacted. Use [Entry.Reveal] to
+// access the value.
+func (e Entry) Format(f fmt.State, verb rune) {
+	s := e.String()
+	switch {
+	case verb == 'v' && f.Flag('#'):
+		s = fmt.Sprintf("vault.Entry{Key:%q, Value:%q, Source:%q, CreatedAt:%q}",
+			e.Key, redacted, e.Source, e.CreatedAt.Format(time.RFC3339))
+	case verb == 'q':
+		s = strconv.Quote(s)
+	}
+	_, _ = io.WriteString(f, s) //nolint:errcheck // the fmt package reports write errors itself
+}
@@ -0,0 +1,67 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestEntry_formatRedactsValue(t *testing.T) {
+	t.Parallel()
+
+	const secret = "sk-live-abc123"
+	e := vault.Entry{
+		Key:       "api-token",
+		Value:     secret,
+		Source:    "ssm",
+		CreatedAt: time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC),
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s", "%q", "%d", "%x"} {
+		t.Run(format, func(t *testing.T) {
+			t.Parallel()
+
+			out := fmt.Sprintf(format, e)
+			assert.NotContains(t, out, secret)
+			assert.Contains(t, out, "api-token")
+			assert.Contains(t, out, "****")
+		})
+	}
+
+	assert.Equal(t, "{key=api-token value=**** source=ssm created_at=2024-01-02T15:04:05Z}", e.String())
+	assert.Equal(t, secret, e.Reveal())
+}
+
+func TestEntry_formatNestedAndWrapped(t *testing.T) {
+	t.Parallel()
+
+	const secret = "hunter2"
+	e := vault.Entry{Key: "db-password", Value: secret}
+
+	assert.NotContains(t, fmt.Sprintf("%v", []vault.Entry{e}), secret)
+	assert.NotContains(t, fmt.Sprintf("%+v", map[string]vault.Entry{"k": e}), secret)
+	assert.NotContains(t, fmt.Sprintf("%v", &e), secret)
+
+	err := fmt.Errorf("save %v: %w", e, errors.New("boom"))
+	assert.NotContains(t, err.Error(), secret)
+}
+
+func TestEntry_valueStillStored(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "raw"}))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "raw", got.Value)
+	assert.Equal(t, "raw", got.Reveal())
+}
@@ -0,0 +1,244 @@
+// Package redisstore implements a [vault.Store] backed by Redis, so that
+// several processes or hosts can share one cache.
+//
+// Each entry is stored as JSON under "vault:<namespace>::<key>", or
+// "vault::<key>" in the root store, which has no namespace. The keys of a
+// namespace are tracked in a Redis set under "vault:<namespace>:%index",
+// so listing never scans the keyspace. Colons and percent signs in
+// namespaces are percent-encoded, so the double colon always ends the
+// namespace and no key, whatever it contains, shares a Redis key with
+// another namespace's entry or index. Commands are pipelined rather than
+// run in transactions so that the store also works with Redis Cluster.
+package redisstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/bjaus/vault"
+)
+
+const (
+	defaultKeyPrefix = "vault"
+	indexSuffix      = ":%index"
+	keySeparator     = "::"
+)
+
+// namespaceEscaper percent-encodes the characters that delimit the parts
+// of a Redis key.
+var namespaceEscaper = strings.NewReplacer("%", "%25", ":", "%3A")
+
+// Store is a [vault.Store] backed by Redis. It implements
+// [vault.Namespaced]; namespaces nest by appending ":<namespace>" to the
+// key prefix.
+type Store struct {
+	client redis.UniversalClient
+	prefix string // the key prefix, then each escaped namespace
+}
+
+// Option configures a Redis [Store].
+type Option func(*Store)
+
+// WithKeyPrefix overrides the prefix of every Redis key written by the
+// store ("vault" by default), for example to keep several applications
+// apart on a shared server.
+func WithKeyPrefix(prefix string) Option {
+	return func(s *Store) { s.prefix = prefix }
+}
+
+// New creates a store that uses client for all commands.
+func New(client redis.UniversalClient, opts ...Option) *Store {
+	s := &Store{client: client, prefix: defaultKeyPrefix}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithNamespace returns a [vault.Store] scoped to ns. It panics if ns is
+// not valid according to [vault.ValidateNamespace].
+func (s *Store) WithNamespace(ns string) vault.Store {
+	if err := vault.ValidateNamespace(ns); err != nil {
+		panic(err)
+	}
+	return &Store{client: s.client, prefix: s.prefix + ":" + namespaceEscaper.Replace(ns)}
+}
+
+// Get retrieves an entry by key.
+func (s *Store) Get(ctx context.Context, key string) (vault.Entry, error) {
+	data, err := s.client.Get(ctx, s.dataKey(key)).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return vault.Entry{}, vault.ErrNotFound
+	}
+	if err != nil {
+		return vault.Entry{}, fmt.Errorf("redisstore: get %q: %w", key, backendErr(err))
+	}
+	return decode(key, data)
+}
+
+// GetMany retrieves several entries with one pipelined round trip.
+// Missing keys are absent from the result.
+func (s *Store) GetMany(ctx context.Context, keys []string) (map[string]vault.Entry, error) {
+	cmds, err := s.getPipelined(ctx, keys)
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: get many: %w", backendErr(err))
+	}
+
+	found := make(map[string]vault.Entry, len(keys))
+	for i, cmd := range cmds {
+		data, err := cmd.Bytes()
+		if errors.Is(err, redis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("redisstore: get %q: %w", keys[i], backendErr(err))
+		}
+		e, err := decode(keys[i], data)
+		if err != nil {
+			return nil, err
+		}
+		found[keys[i]] = e
+	}
+	return found, nil
+}
+
+// Exists reports whether key is present without transferring its value.
+func (s *Store) Exists(ctx context.Context, key string) (bool, error) {
+	n, err := s.client.Exists(ctx, s.dataKey(key)).Result()
+	if err != nil {
+		return false, fmt.Errorf("redisstore: exists %q: %w", key, backendErr(err))
+	}
+	return n > 0, nil
+}
+
+// Keys returns the keys in the namespace index, sorted.
+func (s *Store) Keys(ctx context.Context) ([]string, error) {
+	keys, err := s.client.SMembers(ctx, s.indexKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: keys: %w", backendErr(err))
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Set stores an entry and adds its key to the namespace index.
+func (s *Store) Set(ctx context.Context, entry vault.Entry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("redisstore: marshal %q: %w", entry.Key, err)
+	}
+
+	_, err = s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Set(ctx, s.dataKey(entry.Key), data, 0)
+		p.SAdd(ctx, s.indexKey(), entry.Key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: set %q: %w", entry.Key, backendErr(err))
+	}
+	return nil
+}
+
+// Delete removes an entry and its key from the namespace index.
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		p.Del(ctx, s.dataKey(key))
+		p.SRem(ctx, s.indexKey(), key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: delete %q: %w", key, backendErr(err))
+	}
+	return nil
+}
+
+// DeleteMany removes several entries and their index members in a single
+// pipeline. Each entry gets its own DEL, since under Redis Cluster the
+// keys may hash to different slots.
+func (s *Store) DeleteMany(ctx context.Context, keys []string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	members := make([]any, len(keys))
+	for i, key := range keys {
+		members[i] = key
+	}
+
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for _, key := range keys {
+			p.Del(ctx, s.dataKey(key))
+		}
+		p.SRem(ctx, s.indexKey(), members...)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("redisstore: delete %d keys: %w", len(keys), backendErr(err))
+	}
+	return nil
+}
+
+// List returns every entry in the namespace index. Keys whose values have
+// disappeared, for example after an external DEL, are skipped.
+func (s *Store) List(ctx context.Context) ([]vault.Entry, error) {
+	keys, err := s.client.SMembers(ctx, s.indexKey()).Result()
+	if err != nil {
+		return nil, fmt.Errorf("redisstore: list: %w", backendErr(err))
+	}
+
+	found, err := s.GetMany(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]vault.Entry, 0, len(found))
+	for _, key := range keys {
+		if e, ok := found[key]; ok {
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+// getPipelined issues one GET per key in a single pipeline. Missing keys
+// are reported per command with [redis.Nil].
+func (s *Store) getPipelined(ctx context.Context, keys []string) ([]*redis.StringCmd, error) {
+	cmds := make([]*redis.StringCmd, len(keys))
+	_, err := s.client.Pipelined(ctx, func(p redis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = p.Get(ctx, s.dataKey(key))
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+	return cmds, nil
+}
+
+func (s *Store) dataKey(key string) string { return s.prefix + keySeparator + key }
+
+func (s *Store) indexKey() string { return s.prefix + indexSuffix }
+
+func decode(key string, data []byte) (vault.Entry, error) {
+	var e vault.Entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return vault.Entry{}, fmt.Errorf("redisstore: unmarshal %q: %w", key, err)
+	}
+	return e, nil
+}
+
+// backendErr marks Redis failures as [vault.ErrBackendUnavailable],
+// keeping context cancellation recognizable.
+func backendErr(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return fmt.Errorf("%w: %w", vault.ErrBackendUnavailable, err)
+}
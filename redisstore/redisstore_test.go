@@ -0,0 +1,212 @@
+package redisstore_test
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/redisstore"
+)
+
+func newStore(t *testing.T, opts ...redisstore.Option) (*redisstore.Store, *miniredis.Miniredis) {
+	t.Helper()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() }) //nolint:errcheck // test teardown
+	return redisstore.New(client, opts...), mr
+}
+
+func TestStore_GetSetDelete(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newStore(t)
+	ctx := context.Background()
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v", Source: "manual"}))
+
+	got, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+	assert.Equal(t, "manual", got.Source)
+
+	raw, err := mr.Get("vault::k")
+	require.NoError(t, err)
+	var stored vault.Entry
+	require.NoError(t, json.Unmarshal([]byte(raw), &stored))
+	assert.Equal(t, "v", stored.Value)
+
+	ok, err := s.Exists(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	require.NoError(t, s.Delete(ctx, "k"))
+	_, err = s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	assert.False(t, mr.Exists("vault:%index"), "the index must be emptied")
+}
+
+func TestStore_ListAndKeys(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newStore(t)
+	ctx := context.Background()
+
+	for _, k := range []string{"c", "a", "b"} {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: k, Value: k}))
+	}
+	mr.Del("vault::b") // value removed behind the store's back
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+
+	found, err := s.GetMany(ctx, []string{"a", "b", "missing"})
+	require.NoError(t, err)
+	assert.Len(t, found, 1)
+	assert.Equal(t, "a", found["a"].Value)
+}
+
+func TestStore_namespaces(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newStore(t, redisstore.WithKeyPrefix("app"))
+	ctx := context.Background()
+
+	prod := vault.New(vault.WithStore(s), vault.WithNamespace("prod"))
+	qa := vault.New(vault.WithStore(s), vault.WithNamespace("qa"))
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "db", Value: "prod-db"}))
+	require.NoError(t, qa.Set(ctx, vault.Entry{Key: "db", Value: "qa-db"}))
+
+	got, err := prod.Get(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-db", got.Value)
+
+	entries, err := qa.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "qa-db", entries[0].Value)
+
+	assert.True(t, mr.Exists("app:prod::db"))
+	assert.True(t, mr.Exists("app:qa::db"))
+}
+
+func TestStore_keysDoNotCollide(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	ctx := context.Background()
+
+	stores := map[string]vault.Store{
+		"root":   s,
+		"ns":     s.WithNamespace("ns"),
+		"ns:a":   s.WithNamespace("ns:a"),
+		"ns/a":   s.WithNamespace("ns").(vault.Namespaced).WithNamespace("a"),
+		"ns%3Aa": s.WithNamespace("ns%3Aa"),
+	}
+	keys := []string{"ns:k", "k", "a:k", "a::k", "%index", "__vault_index__"}
+	for name, store := range stores {
+		for _, key := range keys {
+			require.NoError(t, store.Set(ctx, vault.Entry{Key: key, Value: name}))
+		}
+	}
+
+	for name, store := range stores {
+		for _, key := range keys {
+			got, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			assert.Equal(t, name, got.Value, "%s %q", name, key)
+		}
+		listed, err := store.List(ctx)
+		require.NoError(t, err)
+		assert.Len(t, listed, len(keys), name)
+	}
+}
+
+func TestStore_backendErrors(t *testing.T) {
+	t.Parallel()
+
+	s, mr := newStore(t)
+	mr.Close()
+
+	ctx := context.Background()
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+	require.ErrorIs(t, s.Set(ctx, vault.Entry{Key: "k"}), vault.ErrBackendUnavailable)
+	_, err = s.List(ctx)
+	require.ErrorIs(t, err, vault.ErrBackendUnavailable)
+}
+
+func TestStore_cancelledContext(t *testing.T) {
+	t.Parallel()
+
+	s, _ := newStore(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := s.Get(ctx, "k")
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, s.Set(ctx, vault.Entry{Key: "k"}), context.Canceled)
+}
+
+// delRecorder records the number of keys in every pipelined DEL.
+type delRecorder struct {
+	mu   sync.Mutex
+	dels []int
+}
+
+func (*delRecorder) DialHook(next redis.DialHook) redis.DialHook { return next }
+
+func (*delRecorder) ProcessHook(next redis.ProcessHook) redis.ProcessHook { return next }
+
+func (r *delRecorder) ProcessPipelineHook(next redis.ProcessPipelineHook) redis.ProcessPipelineHook {
+	return func(ctx context.Context, cmds []redis.Cmder) error {
+		r.mu.Lock()
+		for _, cmd := range cmds {
+			if cmd.Name() == "del" {
+				r.dels = append(r.dels, len(cmd.Args())-1)
+			}
+		}
+		r.mu.Unlock()
+		return next(ctx, cmds)
+	}
+}
+
+func TestStore_DeleteMany(t *testing.T) {
+	t.Parallel()
+
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr(), MaxRetries: -1})
+	t.Cleanup(func() { _ = client.Close() }) //nolint:errcheck // test teardown
+	rec := &delRecorder{}
+	client.AddHook(rec)
+	s := redisstore.New(client)
+	ctx := context.Background()
+
+	for _, k := range []string{"a", "b", "c"} {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: k, Value: k}))
+	}
+	require.NoError(t, s.DeleteMany(ctx, []string{"a", "c", "missing"}))
+
+	keys, err := s.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, keys)
+	assert.False(t, mr.Exists("vault::a"))
+	assert.Equal(t, []int{1, 1, 1}, rec.dels, "one DEL per key, so that no command spans cluster slots")
+}
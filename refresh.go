@@ -0,0 +1,558 @@
+package vault
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/sync/singleflight"
+)
+
+// autoRefreshTimeout bounds the refreshes that misses start on behalf of
+// every caller waiting on them, since no single caller's context can.
+const autoRefreshTimeout = time.Minute
+
+// Refresh fetches entries from all configured sources and writes them
+// to the store. This always executes regardless of TTL.
+//
+// Sources are fetched concurrently, up to the limit set by
+// [WithMaxConcurrency]. If any source fails, the other fetches are
+// cancelled, nothing is written, and the first error is returned.
+// Otherwise entries are written in source order, so when two sources
+// produce the same key the later source wins, unless [WithMergeFunc] or
+// [WithSourcePriority] says otherwise.
+func (v *vault) Refresh(ctx context.Context) error {
+	if err := v.checkOpen(); err != nil {
+		return err
+	}
+
+	v.refreshStarted()
+	start := time.Now()
+	err := v.refresh(ctx)
+	v.metrics.ObserveRefresh(time.Since(start), err)
+	v.refreshFinished(err)
+	return err
+}
+
+// autoRefresh refreshes on behalf of a cache miss. Concurrent misses share
+// a single in-flight refresh, and a caller that arrives after another
+// refresh has already completed skips fetching again. expiredAt is passed
+// through to [vault.shouldAutoRefresh].
+func (v *vault) autoRefresh(ctx context.Context, expiredAt time.Time) error {
+	ch := v.inflight.DoChan("refresh", func() (any, error) {
+		if !v.shouldAutoRefresh(expiredAt) {
+			return nil, nil
+		}
+		rctx, cancel := sharedContext(ctx)
+		defer cancel()
+		return nil, v.Refresh(rctx)
+	})
+	return await(ctx, ch)
+}
+
+// sharedContext returns a context for work shared by several callers:
+// it keeps ctx's values, but not its cancellation, and times out after
+// [autoRefreshTimeout].
+func sharedContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), autoRefreshTimeout)
+}
+
+// await waits for the shared work started with [singleflight.Group.DoChan]
+// to finish, or for ctx to end.
+func await(ctx context.Context, ch <-chan singleflight.Result) error {
+	select {
+	case r := <-ch:
+		return r.Err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// revalidate starts an auto-refresh in the background for
+// [WithStaleWhileRevalidate]. At most one runs at a time; calls made while
+// one is running do nothing. The refresh outlives ctx but keeps its values.
+func (v *vault) revalidate(ctx context.Context, expiredAt time.Time) {
+	if !v.shouldAutoRefresh(expiredAt) || !v.revalidating.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer v.revalidating.Store(false)
+		if err := v.autoRefresh(context.WithoutCancel(ctx), expiredAt); err != nil && v.onRefreshError != nil {
+			v.onRefreshError(err)
+		}
+	}()
+}
+
+func (v *vault) refresh(ctx context.Context) error {
+	now, start := v.now(), time.Now()
+
+	batches, err := v.fetchAll(ctx)
+	if err != nil {
+		return v.refreshFailed(now, err)
+	}
+	changes, err := v.apply(ctx, now, v.prepare(batches))
+	if err != nil {
+		return v.refreshFailed(now, err)
+	}
+	v.refreshSucceeded(now)
+
+	v.logger.Debug("vault: refresh completed", "sources", len(batches), "duration", time.Since(start))
+	if len(changes) > 0 {
+		v.refreshed(ctx, changes)
+	}
+	return nil
+}
+
+// refreshed reports the changes a refresh made to [WithOnRefresh] and, as
+// invalidations, to [WithBroker]. Only updates are published: a key that
+// was added is one this vault lacked, often because an invalidation had
+// just dropped it, and publishing it would bounce invalidations between
+// vaults.
+func (v *vault) refreshed(ctx context.Context, changes []Change) {
+	var keys []string
+	for i, c := range changes {
+		if c.Op == DiffUpdate {
+			keys = append(keys, c.Key)
+		}
+		if i+1 == len(changes) || changes[i+1].Namespace != c.Namespace {
+			v.invalidate(ctx, c.Namespace, keys...)
+			keys = nil
+		}
+	}
+	if v.onRefresh != nil {
+		v.onRefresh(changes)
+	}
+}
+
+// apply writes batches returned by [vault.prepare] to their stores,
+// stamped with now. When [WithOnRefresh] or [WithBroker] is set, it also
+// returns how the writes changed the stores, read from them before
+// overwriting.
+func (v *vault) apply(ctx context.Context, now time.Time, prepared []batch) ([]Change, error) {
+	unlock := v.writes.lock(batchKeys(prepared)...)
+	defer unlock()
+
+	// Group the writes by target store so each can be applied in one
+	// batch. Targets are kept in the order they are first seen.
+	var targets []batch
+	index := make(map[string]int)
+	for _, b := range prepared {
+		for _, e := range b.entries {
+			if serr := v.checkSize(e); serr != nil {
+				if !v.skipOversized {
+					return nil, fmt.Errorf("vault: refresh: set %q: %w", e.Key, serr)
+				}
+				v.logger.Warn("vault: skipping oversized entry", "source", b.name, "key", e.Key, "error", serr)
+				continue
+			}
+
+			skip, werr := v.checkWritable(ctx, b.target, e.Key)
+			if werr != nil {
+				return nil, fmt.Errorf("vault: refresh: set %q: %w", e.Key, werr)
+			}
+			if skip {
+				continue
+			}
+
+			i, ok := index[b.namespace]
+			if !ok {
+				i = len(targets)
+				index[b.namespace] = i
+				targets = append(targets, batch{target: b.target, namespace: b.namespace})
+			}
+			e.CreatedAt = now
+			targets[i].entries = append(targets[i].entries, e)
+		}
+	}
+
+	var changes []Change
+	for _, t := range targets {
+		if v.onRefresh != nil || v.broker != nil {
+			tc, err := changed(ctx, t)
+			if err != nil {
+				return nil, fmt.Errorf("vault: refresh: %w", err)
+			}
+			changes = append(changes, tc...)
+		}
+		if serr := v.write(ctx, t.target, t.entries); serr != nil {
+			return nil, fmt.Errorf("vault: refresh: %w", serr)
+		}
+	}
+	slices.SortFunc(changes, func(a, b Change) int {
+		return cmp.Or(cmp.Compare(a.Namespace, b.Namespace), cmp.Compare(a.Key, b.Key))
+	})
+	return changes, nil
+}
+
+// changed compares the entries about to be written to t.target with what
+// it holds now, by value as [Diff] does, and returns a [DiffAdd] or
+// [DiffUpdate] change for each that differs. When a key is written more
+// than once, the last entry is the one compared.
+func changed(ctx context.Context, t batch) ([]Change, error) {
+	latest := make(map[string]Entry, len(t.entries))
+	keys := make([]string, 0, len(t.entries))
+	for _, e := range t.entries {
+		if _, ok := latest[e.Key]; !ok {
+			keys = append(keys, e.Key)
+		}
+		latest[e.Key] = e
+	}
+
+	current, err := getMany(ctx, t.target, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []Change
+	for _, key := range keys {
+		e := latest[key]
+		old, ok := current[key]
+		switch {
+		case !ok:
+			changes = append(changes, Change{Namespace: t.namespace, Key: key, New: e, Op: DiffAdd})
+		case old.Value != e.Value:
+			changes = append(changes, Change{Namespace: t.namespace, Key: key, Old: old, New: e, Op: DiffUpdate})
+		}
+	}
+	return changes, nil
+}
+
+// write stores the entries of a refresh in target, atomically when it
+// implements [BatchSetter], and publishes an event for each once all are
+// written.
+func (v *vault) write(ctx context.Context, target Store, entries []Entry) error {
+	if err := setMany(ctx, target, entries); err != nil {
+		v.logger.Error("vault: store set failed", "entries", len(entries), "error", err)
+		return err
+	}
+	for _, e := range entries {
+		v.watch.publish(Event{Key: e.Key, Entry: e, Op: OpRefresh})
+	}
+	return nil
+}
+
+// batch holds what one source produced during a refresh, and where its
+// entries should be written. namespace is the mount namespace of target,
+// or empty for the vault's own store.
+type batch struct {
+	name      string
+	src       Source
+	target    Store
+	namespace string
+	entries   []Entry
+}
+
+// batchKeys returns the keys of every entry in batches.
+func batchKeys(batches []batch) []string {
+	var keys []string
+	for _, b := range batches {
+		for _, e := range b.entries {
+			keys = append(keys, e.Key)
+		}
+	}
+	return keys
+}
+
+// targetKey identifies a key within the store a batch writes to.
+type targetKey struct {
+	namespace string
+	key       string
+}
+
+// prepare drops the entries the source selector rejects. With a merge
+// function configured, an entry for a key that an earlier source already
+// produced for the same store is merged into that earlier entry rather
+// than written again. The entries returned by sources are not modified.
+func (v *vault) prepare(batches []batch) []batch {
+	out := make([]batch, len(batches))
+	seen := make(map[targetKey][2]int) // batch index, entry index
+	for i, b := range batches {
+		out[i] = b
+		out[i].entries = make([]Entry, 0, len(b.entries))
+		for _, e := range b.entries {
+			if v.selector != nil && !v.selector(b.name, e.Key) {
+				continue
+			}
+			if v.merge != nil {
+				tk := targetKey{namespace: b.namespace, key: e.Key}
+				if at, ok := seen[tk]; ok {
+					prev := &out[at[0]].entries[at[1]]
+					*prev = v.merge(*prev, e)
+					continue
+				}
+				seen[tk] = [2]int{i, len(out[i].entries)}
+			}
+			out[i].entries = append(out[i].entries, e)
+		}
+	}
+	return out
+}
+
+// fetchAll fetches every source concurrently without writing anything.
+// Batches are returned in source order.
+func (v *vault) fetchAll(ctx context.Context) ([]batch, error) {
+	batches := make([]batch, len(v.sources))
+	for i, src := range v.sources {
+		b, err := v.newBatch(i, src)
+		if err != nil {
+			return nil, fmt.Errorf("vault: refresh: %w", err)
+		}
+		batches[i] = b
+	}
+	if err := v.fetchInto(ctx, batches); err != nil {
+		return nil, err
+	}
+	return batches, nil
+}
+
+// fetchInto fetches the entries of every batch concurrently. If any fetch
+// fails, the others are cancelled and the first error is returned.
+func (v *vault) fetchInto(ctx context.Context, batches []batch) error {
+	g, gctx := errgroup.WithContext(ctx)
+	g.SetLimit(v.fetchLimit(len(batches)))
+	for i := range batches {
+		g.Go(func() error {
+			if _, err := v.fetchBatch(gctx, i, &batches[i]); err != nil {
+				return fmt.Errorf("vault: refresh: %w", err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// newBatch returns the batch for the i-th source, resolving the store a
+// [WithSourceNamespace] source writes to. Failures are [SourceError]s.
+func (v *vault) newBatch(i int, src Source) (batch, error) {
+	b := batch{name: sourceName(i, src), src: src, target: v.cache}
+	if m, ok := src.(*mountedSource); ok {
+		b.name, b.src, b.namespace = sourceName(i, m.Source), m.Source, m.namespace
+		mounted, err := v.mount(m.namespace)
+		if err != nil {
+			return b, &SourceError{Index: i, Name: b.name, Err: err}
+		}
+		b.target = mounted
+	}
+	return b, nil
+}
+
+// fetchBatch fetches the entries of b, which belongs to the i-th source,
+// and reports how long that took. Failures are [SourceError]s.
+func (v *vault) fetchBatch(ctx context.Context, i int, b *batch) (time.Duration, error) {
+	if err := v.circuitOpen(i); err != nil {
+		v.logger.Debug("vault: skipping source", "source", b.name, "error", err)
+		return 0, &SourceError{Index: i, Name: b.name, Err: err}
+	}
+
+	start := time.Now()
+	entries, err := v.fetch(ctx, b.src)
+	elapsed := time.Since(start)
+	v.recordFetch(ctx, i, err)
+	if err != nil {
+		v.logger.Warn("vault: source fetch failed", "source", b.name, "duration", elapsed, "error", err)
+		return elapsed, &SourceError{Index: i, Name: b.name, Err: err}
+	}
+	v.logger.Debug("vault: source fetched", "source", b.name, "entries", len(entries), "duration", elapsed)
+	b.entries = entries
+	return elapsed, nil
+}
+
+// fetchLimit is how many of n sources a refresh fetches at once.
+func (v *vault) fetchLimit(n int) int {
+	if v.maxConcurrency > 0 {
+		return v.maxConcurrency
+	}
+	return max(n, 1)
+}
+
+// maxRetryBackoff caps the doubling wait between [WithRetry] attempts,
+// unless the configured backoff is longer to begin with.
+const maxRetryBackoff = 30 * time.Second
+
+// fetch calls src.Fetch, retrying as configured by [WithRetry]. Every
+// path that talks to a source goes through here so that retries and the
+// fetch semaphore apply globally.
+func (v *vault) fetch(ctx context.Context, src Source) ([]Entry, error) {
+	wait := v.retryBackoff
+	for attempt := 1; ; attempt++ {
+		entries, err := v.fetchOnce(ctx, src)
+		if err == nil || attempt >= v.retries || !v.retryable(ctx, err) {
+			return entries, err
+		}
+
+		v.logger.Debug("vault: retrying source fetch", "attempt", attempt, "error", err)
+		timer := time.NewTimer(wait + rand.N(wait/2+1)) //nolint:gosec // jitter needs no cryptographic randomness
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		}
+		if limit := max(v.retryBackoff, maxRetryBackoff); wait < limit/2 {
+			wait *= 2
+		} else {
+			wait = limit
+		}
+	}
+}
+
+// retryable reports whether a failed fetch should be attempted again.
+func (v *vault) retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if v.retryIf != nil {
+		return v.retryIf(err)
+	}
+	if errors.Is(err, errSourceTimeout) {
+		return true
+	}
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// fetchOnce calls src.Fetch, first acquiring a slot from the fetch
+// semaphore when one is configured. The slot is not held between retries,
+// but is held until src.Fetch returns, even by a fetch abandoned after
+// [WithSourceTimeout].
+func (v *vault) fetchOnce(ctx context.Context, src Source) ([]Entry, error) {
+	release := func() {}
+	if v.fetchSem != nil {
+		select {
+		case v.fetchSem <- struct{}{}:
+			release = func() { <-v.fetchSem }
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	if v.sourceTimeout <= 0 {
+		defer release()
+		return src.Fetch(ctx)
+	}
+	return v.fetchWithTimeout(ctx, src, release)
+}
+
+// errSourceTimeout marks fetches cut short by [WithSourceTimeout].
+var errSourceTimeout = errors.New("source timed out")
+
+// fetchWithTimeout runs src.Fetch on its own goroutine with a context
+// limited by [WithSourceTimeout], and gives up when that context ends even
+// if the source has not returned. release is called once it has.
+func (v *vault) fetchWithTimeout(ctx context.Context, src Source, release func()) ([]Entry, error) {
+	fctx, cancel := context.WithTimeout(ctx, v.sourceTimeout)
+	defer cancel()
+
+	type result struct {
+		entries []Entry
+		err     error
+	}
+	done := make(chan result, 1)
+	go func() {
+		defer release()
+		entries, err := src.Fetch(fctx)
+		done <- result{entries, err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil && ctx.Err() == nil && errors.Is(fctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w after %s: %w", errSourceTimeout, v.sourceTimeout, context.DeadlineExceeded)
+		}
+		return r.entries, r.err
+	case <-fctx.Done():
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w after %s: %w", errSourceTimeout, v.sourceTimeout, context.DeadlineExceeded)
+	}
+}
+
+// mount returns the configured store scoped to namespace ns, independent
+// of the vault's own namespace.
+func (v *vault) mount(ns string) (Store, error) {
+	n, ok := v.config.store.(Namespaced)
+	if !ok {
+		return nil, ErrNotNamespaced
+	}
+	return v.decorate(n.WithNamespace(ns)), nil
+}
+
+func (v *vault) refreshSucceeded(at time.Time) {
+	v.mu.Lock()
+	v.lastRefresh = at
+	v.lastRefreshErr = nil
+	v.invalidated = false
+	v.mu.Unlock()
+}
+
+// keyRefreshSucceeded clears the last refresh error after a single-key
+// refresh. Unlike refreshSucceeded it leaves the auto-refresh window
+// alone, since the other keys were not fetched.
+func (v *vault) keyRefreshSucceeded() {
+	v.mu.Lock()
+	v.lastRefreshErr = nil
+	v.mu.Unlock()
+}
+
+func (v *vault) refreshFailed(at time.Time, err error) error {
+	v.logger.Warn("vault: refresh failed", "error", err)
+
+	v.mu.Lock()
+	v.lastFailure = at
+	v.lastRefreshErr = err
+	v.mu.Unlock()
+	return err
+}
+
+// failingRefresh returns the last refresh error when fail-fast is enabled
+// and that failure is still within its backoff window, which is one
+// refresh TTL period. Without a TTL the error is returned until a refresh
+// succeeds.
+func (v *vault) failingRefresh() error {
+	if !v.failFast {
+		return nil
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.lastRefreshErr == nil {
+		return nil
+	}
+	if v.refreshTTL > 0 && v.since(v.lastFailure) > v.refreshTTL {
+		return nil
+	}
+	return v.lastRefreshErr
+}
+
+// shouldAutoRefresh reports whether a cache miss may trigger a refresh.
+// expiredAt is the [Entry.ExpiresAt] of the entry that missed, if any: an
+// entry that expired after the last refresh warrants a new one even while
+// the TTL window is still open.
+func (v *vault) shouldAutoRefresh(expiredAt time.Time) bool {
+	if len(v.sources) == 0 {
+		return false
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if v.lastRefresh.IsZero() || v.invalidated {
+		return true
+	}
+
+	if !expiredAt.IsZero() && v.lastRefresh.Before(expiredAt) {
+		return true
+	}
+
+	if v.refreshTTL > 0 {
+		return v.since(v.lastRefresh) > v.refreshTTL
+	}
+
+	return false
+}
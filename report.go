@@ -0,0 +1,116 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// RefreshResult describes a refresh made by [Vault.RefreshReport].
+type RefreshResult struct {
+	// Sources holds one result per configured source, in the order the
+	// sources were added.
+	Sources []SourceResult
+}
+
+// Failed returns the results of the sources that failed.
+func (r RefreshResult) Failed() []SourceResult {
+	var failed []SourceResult
+	for _, s := range r.Sources {
+		if s.Err != nil {
+			failed = append(failed, s)
+		}
+	}
+	return failed
+}
+
+// SourceResult describes how one source fared during a refresh.
+type SourceResult struct {
+	// Name is the [Named] name of the source, or "source N" when unnamed.
+	Name string
+
+	// Entries is how many entries the source returned, before
+	// [WithSourceSelector] and [WithMergeFunc] are applied.
+	Entries int
+
+	// Duration is how long the fetch took, including retries.
+	Duration time.Duration
+
+	// Err is why the source failed, as a [*SourceError], or nil.
+	Err error
+}
+
+// RefreshReport is like [Vault.Refresh] but does not stop at the first
+// failing source. Every source is fetched, the entries of those that
+// succeed are written, and the outcome for each is reported in the
+// result. The error is non-nil only if every source failed, when it joins
+// their errors, or if writing to the store failed. A refresh in which any
+// source succeeded counts as successful for auto-refresh purposes.
+func (v *vault) RefreshReport(ctx context.Context) (RefreshResult, error) {
+	if err := v.checkOpen(); err != nil {
+		return RefreshResult{}, err
+	}
+
+	v.refreshStarted()
+	start := time.Now()
+	result, err := v.refreshReport(ctx)
+	v.metrics.ObserveRefresh(time.Since(start), err)
+	v.refreshFinished(err)
+	return result, err
+}
+
+func (v *vault) refreshReport(ctx context.Context) (RefreshResult, error) {
+	now, start := v.now(), time.Now()
+
+	batches := make([]batch, len(v.sources))
+	result := RefreshResult{Sources: make([]SourceResult, len(v.sources))}
+	for i, src := range v.sources {
+		batches[i], result.Sources[i].Err = v.newBatch(i, src)
+		result.Sources[i].Name = batches[i].name
+	}
+
+	var g errgroup.Group
+	g.SetLimit(v.fetchLimit(len(batches)))
+	for i := range batches {
+		if result.Sources[i].Err != nil {
+			continue
+		}
+		g.Go(func() error {
+			r := &result.Sources[i]
+			r.Duration, r.Err = v.fetchBatch(ctx, i, &batches[i])
+			r.Entries = len(batches[i].entries)
+			return nil
+		})
+	}
+	_ = g.Wait() //nolint:errcheck // failures are recorded per source
+
+	var (
+		ok   []batch
+		errs []error
+	)
+	for i, r := range result.Sources {
+		if r.Err != nil {
+			errs = append(errs, r.Err)
+			continue
+		}
+		ok = append(ok, batches[i])
+	}
+	if len(errs) > 0 && len(ok) == 0 {
+		return result, v.refreshFailed(now, errors.Join(errs...))
+	}
+
+	changes, err := v.apply(ctx, now, v.prepare(ok))
+	if err != nil {
+		return result, v.refreshFailed(now, err)
+	}
+	v.refreshSucceeded(now)
+
+	v.logger.Debug("vault: refresh completed",
+		"sources", len(batches), "failed", len(errs), "duration", time.Since(start))
+	if len(changes) > 0 {
+		v.refreshed(ctx, changes)
+	}
+	return result, nil
+}
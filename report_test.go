@@ -0,0 +1,92 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestRefreshReport_partialFailure(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errDown := errors.New("down")
+	good := vault.NamedSource("env", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}}, nil
+	}))
+	bad := vault.NamedSource("ssm", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errDown
+	}))
+	other := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "c", Value: "3"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(good), vault.WithSource(bad), vault.WithSource(other))
+	result, err := v.RefreshReport(ctx)
+	require.NoError(t, err)
+	require.Len(t, result.Sources, 3)
+	assert.False(t, v.Stats(ctx).LastSuccessfulRefresh.IsZero())
+
+	assert.Equal(t, "env", result.Sources[0].Name)
+	assert.Equal(t, 2, result.Sources[0].Entries)
+	require.NoError(t, result.Sources[0].Err)
+
+	assert.Equal(t, "ssm", result.Sources[1].Name)
+	require.ErrorIs(t, result.Sources[1].Err, errDown)
+	require.ErrorIs(t, result.Sources[1].Err, vault.ErrSource)
+
+	assert.Equal(t, "source 2", result.Sources[2].Name)
+	assert.Equal(t, 1, result.Sources[2].Entries)
+
+	failed := result.Failed()
+	require.Len(t, failed, 1)
+	assert.Equal(t, "ssm", failed[0].Name)
+
+	for _, key := range []string{"a", "b", "c"} {
+		_, err := v.Get(ctx, key)
+		require.NoError(t, err, key)
+	}
+}
+
+func TestRefreshReport_allFail(t *testing.T) {
+	t.Parallel()
+
+	errA, errB := errors.New("a down"), errors.New("b down")
+	failing := func(err error) vault.Source {
+		return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, err })
+	}
+
+	v := vault.New(vault.WithSource(failing(errA)), vault.WithSource(failing(errB)))
+	result, err := v.RefreshReport(context.Background())
+	require.ErrorIs(t, err, errA)
+	require.ErrorIs(t, err, errB)
+	assert.Len(t, result.Failed(), 2)
+	assert.True(t, v.Stats(context.Background()).LastSuccessfulRefresh.IsZero())
+}
+
+func TestRefreshReport_doesNotCancelOtherSources(t *testing.T) {
+	t.Parallel()
+
+	errDown := errors.New("down")
+	failing := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errDown })
+	slow := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(failing), vault.WithSource(slow), vault.WithMaxConcurrency(1))
+	result, err := v.RefreshReport(context.Background())
+	require.NoError(t, err)
+	require.NoError(t, result.Sources[1].Err)
+
+	e, err := v.Get(context.Background(), "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+}
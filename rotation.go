@@ -0,0 +1,85 @@
+package vault
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Rotate replaces the value of key with one produced by gen. The current
+// entry is resolved as by [Vault.Get] and passed to gen, and the new value
+// is stored with [Entry.CreatedAt] and [Entry.LastRotated] set to now and
+// [Entry.Source] set to "rotate"; other fields are carried over. Concurrent
+// rotations of the same key run one at a time, so each generator sees the
+// value stored by the previous one. The stored entry is returned. A
+// read-only entry is not rotated: Rotate returns [ErrReadOnly] without
+// calling gen, even under [WithSkipReadOnly].
+func (v *vault) Rotate(ctx context.Context, key string, gen func(ctx context.Context, old Entry) (string, error)) (Entry, error) {
+	unlock := v.keys.lock(key)
+	defer unlock()
+
+	old, err := v.Get(ctx, key)
+	if err != nil {
+		return Entry{}, fmt.Errorf("vault: rotate %q: %w", key, err)
+	}
+	if old.ReadOnly {
+		return Entry{}, fmt.Errorf("vault: rotate %q: %w", key, ErrReadOnly)
+	}
+
+	value, err := gen(ctx, old)
+	if err != nil {
+		return Entry{}, fmt.Errorf("vault: rotate %q: generate: %w", key, err)
+	}
+
+	now := v.now()
+	next := old
+	next.Value = value
+	next.CreatedAt = now
+	next.LastRotated = now
+	next.Source = "rotate"
+
+	if err := v.Set(ctx, next); err != nil {
+		return Entry{}, fmt.Errorf("vault: rotate %q: %w", key, err)
+	}
+	return next, nil
+}
+
+// DueForRotation returns the entries whose rotation schedule has elapsed,
+// that is, entries with a non-zero [Entry.RotateEvery] whose last rotation
+// is at least that long ago. Results are sorted by key. The vault does not
+// rotate anything itself; this only surfaces what needs attention.
+func (v *vault) DueForRotation(ctx context.Context) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	now := v.now()
+	due := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if rotationDue(e, now) {
+			due = append(due, e)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool { return due[i].Key < due[j].Key })
+	return due, nil
+}
+
+func rotationDue(e Entry, now time.Time) bool {
+	if e.RotateEvery <= 0 {
+		return false
+	}
+
+	last := e.LastRotated
+	if last.IsZero() {
+		last = e.CreatedAt
+	}
+	return !now.Before(last.Add(e.RotateEvery))
+}
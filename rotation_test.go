@@ -0,0 +1,186 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestDueForRotation(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now()
+	v := vault.New()
+
+	seed := []vault.Entry{
+		{Key: "overdue", RotateEvery: time.Hour, LastRotated: now.Add(-2 * time.Hour)},
+		{Key: "recent", RotateEvery: time.Hour, LastRotated: now.Add(-time.Minute)},
+		{Key: "never-rotated", RotateEvery: 24 * time.Hour, CreatedAt: now.Add(-48 * time.Hour)},
+		{Key: "unscheduled", CreatedAt: now.Add(-365 * 24 * time.Hour)},
+	}
+	for _, e := range seed {
+		require.NoError(t, v.Set(ctx, e))
+	}
+
+	due, err := v.DueForRotation(ctx)
+	require.NoError(t, err)
+
+	keys := make([]string, len(due))
+	for i, e := range due {
+		keys[i] = e.Key
+	}
+	assert.Equal(t, []string{"never-rotated", "overdue"}, keys)
+}
+
+func TestRotate_passesOldValueAndStoresNew(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "api-key", Value: "old", RotateEvery: time.Hour}))
+
+	var seen vault.Entry
+	rotated, err := v.Rotate(ctx, "api-key", func(_ context.Context, old vault.Entry) (string, error) {
+		seen = old
+		return "new", nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "old", seen.Value)
+	assert.Equal(t, "new", rotated.Value)
+	assert.Equal(t, time.Hour, rotated.RotateEvery)
+	assert.False(t, rotated.LastRotated.IsZero())
+
+	got, err := v.Get(ctx, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "new", got.Value)
+	assert.Equal(t, "rotate", got.Source)
+}
+
+func TestRotate_serializesConcurrentRotations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	const n = 20
+	var (
+		mu   sync.Mutex
+		olds = make(map[string]bool)
+		wg   sync.WaitGroup
+	)
+	for range n {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Rotate(ctx, "k", func(_ context.Context, old vault.Entry) (string, error) {
+				mu.Lock()
+				olds[old.Value] = true
+				mu.Unlock()
+				return old.Value + "+", nil
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, olds, n, "each rotation should see the previous rotation's value")
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v"+strings.Repeat("+", n), got.Value)
+}
+
+func TestRotate_generatorError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	errGen := errors.New("entropy exhausted")
+	_, err := v.Rotate(ctx, "k", func(context.Context, vault.Entry) (string, error) {
+		return "", errGen
+	})
+	require.ErrorIs(t, err, errGen)
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+}
+
+func TestRotate_missingKey(t *testing.T) {
+	t.Parallel()
+
+	_, err := vault.New().Rotate(context.Background(), "nope", func(context.Context, vault.Entry) (string, error) {
+		return "x", nil
+	})
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestRotate_readOnly(t *testing.T) {
+	t.Parallel()
+
+	for name, opts := range map[string][]vault.Option{
+		"default":        nil,
+		"skip read-only": {vault.WithSkipReadOnly()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			v := vault.New(opts...)
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v", ReadOnly: true}))
+
+			called := false
+			_, err := v.Rotate(ctx, "k", func(context.Context, vault.Entry) (string, error) {
+				called = true
+				return "v2", nil
+			})
+			require.ErrorIs(t, err, vault.ErrReadOnly)
+			assert.False(t, called, "the generator is not run")
+
+			got, err := v.Get(ctx, "k")
+			require.NoError(t, err)
+			assert.Equal(t, "v", got.Value)
+		})
+	}
+}
+
+func TestStale(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	now := time.Now()
+	v := vault.New() // no TTL configured
+	for key, age := range map[string]time.Duration{
+		"fresh":   time.Minute,
+		"week":    7 * 24 * time.Hour,
+		"day":     24 * time.Hour,
+		"quarter": 90 * 24 * time.Hour,
+	} {
+		require.NoError(t, v.Set(ctx, vault.Entry{Key: key, CreatedAt: now.Add(-age)}))
+	}
+
+	stale, err := v.Stale(ctx, time.Hour)
+	require.NoError(t, err)
+
+	keys := make([]string, len(stale))
+	for i, e := range stale {
+		keys[i] = e.Key
+	}
+	assert.Equal(t, []string{"quarter", "week", "day"}, keys)
+
+	stale, err = v.Stale(ctx, 365*24*time.Hour)
+	require.NoError(t, err)
+	assert.Empty(t, stale)
+}
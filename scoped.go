@@ -0,0 +1,79 @@
+package vault
+
+// Scoped returns a view of the vault scoped to namespace ns, as if it had
+// been created with the same options and [WithNamespace](ns). The view
+// shares the vault's sources, TTLs, backing store and fetch semaphore, so
+// serving many namespaces needs no vault of its own for each, but it keeps
+// its own refresh state: a refresh through the view fetches the sources
+// and writes to the view's namespace alone. Operations through the view
+// count towards its own [Vault.Stats], and metrics go to the same
+// [Metrics].
+//
+// As with [WithNamespace], ns has no effect if the store does not
+// implement [Namespaced]. Scoped panics if ns is not valid according to
+// [ValidateNamespace], or if [WithStrictNamespace] is set and the store
+// does not implement [Namespaced]. Closing the view leaves the store open,
+// and closing the vault closes its views too.
+//
+// With [WithBroker], invalidations received by the vault also mark the
+// views of the namespace they apply to and notify their watchers. A view
+// that is no longer needed should then be closed, so that the vault
+// stops tracking it.
+func (v *vault) Scoped(ns string) Vault {
+	mustValidateNamespace(ns)
+
+	cfg := v.config
+	cfg.namespace = ns
+	cfg.metrics = v.counts.next
+
+	view, err := newVault(&cfg)
+	if err != nil {
+		panic(err)
+	}
+	view.parent = v
+	view.id = v.id
+	view.fetchSem = v.fetchSem
+
+	if root := v.root(); root.broker != nil && len(root.sources) > 0 {
+		root.viewsMu.Lock()
+		if root.views == nil {
+			root.views = make(map[*vault]struct{})
+		}
+		root.views[view] = struct{}{}
+		root.viewsMu.Unlock()
+
+		view.unsubscribe = func() {
+			root.viewsMu.Lock()
+			delete(root.views, view)
+			root.viewsMu.Unlock()
+		}
+	}
+	return view
+}
+
+// root returns the vault that v is a view of, following views of views,
+// or v itself.
+func (v *vault) root() *vault {
+	for v.parent != nil {
+		v = v.parent
+	}
+	return v
+}
+
+// viewsOf returns the open views affected by an invalidation of namespace
+// ns: those scoped to ns, or all of them when the store does not
+// implement [Namespaced] and so every view shares the same keys.
+func (v *vault) viewsOf(ns string) []*vault {
+	_, namespaced := v.config.store.(Namespaced)
+
+	v.viewsMu.Lock()
+	defer v.viewsMu.Unlock()
+
+	var views []*vault
+	for view := range v.views {
+		if !namespaced || view.namespace == ns {
+			views = append(views, view)
+		}
+	}
+	return views
+}
@@ -0,0 +1,136 @@
+package vault_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestScoped_isolatesNamespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store))
+	a, b := v.Scoped("a"), v.Scoped("b")
+
+	require.NoError(t, a.Set(ctx, vault.Entry{Key: "k", Value: "in-a"}))
+	require.NoError(t, b.Set(ctx, vault.Entry{Key: "k", Value: "in-b"}))
+
+	e, err := a.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "in-a", e.Value)
+	e, err = b.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "in-b", e.Value)
+
+	_, err = v.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	e, err = store.WithNamespace("a").Get(ctx, "k")
+	require.NoError(t, err, "views write to the shared backing store")
+	assert.Equal(t, "in-a", e.Value)
+}
+
+func TestScoped_sharesSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var fetches atomic.Int32
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		fetches.Add(1)
+		return []vault.Entry{{Key: "db-host", Value: "db.internal"}}, nil
+	})
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+	a, b := v.Scoped("a"), v.Scoped("b")
+
+	e, err := a.Get(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, "db.internal", e.Value)
+	assert.Equal(t, int32(1), fetches.Load())
+
+	_, err = store.WithNamespace("b").Get(ctx, "db-host")
+	require.ErrorIs(t, err, vault.ErrNotFound, "a refresh through one view writes only its namespace")
+
+	_, err = b.Get(ctx, "db-host")
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), fetches.Load())
+}
+
+func TestScoped_closedWithParent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	a := v.Scoped("a")
+
+	require.NoError(t, a.Close())
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}), "closing a view leaves the vault open")
+
+	b := v.Scoped("b")
+	require.NoError(t, v.Close())
+	require.ErrorIs(t, b.Set(ctx, vault.Entry{Key: "k"}), vault.ErrClosed)
+}
+
+func TestScoped_invalidNamespacePanics(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New()
+	assert.Panics(t, func() { v.Scoped("") })
+	assert.Panics(t, func() { v.Scoped("/a") })
+}
+
+func TestScoped_receivesInvalidations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var value atomic.Pointer[string]
+	set := func(s string) { value.Store(&s) }
+	set("v1")
+	src := vault.SourceFunc(func(context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: *value.Load()}}, nil
+	})
+
+	broker := vault.NewMemoryBroker()
+	a := vault.New(vault.WithBroker(broker))
+	b := vault.New(vault.WithSource(src), vault.WithTTL(time.Hour), vault.WithBroker(broker))
+	view, other := b.Scoped("t1"), b.Scoped("t2")
+	require.NoError(t, view.Refresh(ctx))
+	require.NoError(t, other.Refresh(ctx))
+
+	events, err := view.Watch(ctx)
+	require.NoError(t, err)
+	otherEvents, err := other.Watch(ctx)
+	require.NoError(t, err)
+
+	set("v2")
+	require.NoError(t, a.Scoped("t1").Set(ctx, vault.Entry{Key: "k", Value: "v2"}))
+
+	ev := receive(t, events)
+	assert.Equal(t, "k", ev.Key)
+	assert.Equal(t, vault.OpDelete, ev.Op)
+
+	e, err := view.Get(ctx, "k")
+	require.NoError(t, err, "the view refreshes within the TTL window")
+	assert.Equal(t, "v2", e.Value)
+
+	e, err = other.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", e.Value, "views of other namespaces are untouched")
+	select {
+	case ev := <-otherEvents:
+		t.Fatalf("unexpected event %+v", ev)
+	default:
+	}
+
+	require.NoError(t, view.Close())
+	require.NoError(t, a.Scoped("t1").Delete(ctx, "k"))
+	require.NoError(t, b.Close())
+}
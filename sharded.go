@@ -0,0 +1,113 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+// ShardedStore is a [Store] that spreads keys across several backing
+// stores. Each key is routed to exactly one shard; [ShardedStore.List]
+// fans out to every shard and merges the results. It implements
+// [Namespaced] by scoping each shard that supports it. Use
+// [NewShardedStore] to create one.
+type ShardedStore struct {
+	shards []Store
+	hash   func(key string) int
+}
+
+// NewShardedStore creates a store that routes each key to
+// shards[hash(key) % len(shards)]. If hash is nil, FNV-1a is used.
+// shards must not be empty, and the same shards must be given in the
+// same order every time for keys to be found again.
+func NewShardedStore(shards []Store, hash func(key string) int) *ShardedStore {
+	if hash == nil {
+		hash = fnvHash
+	}
+	return &ShardedStore{shards: shards, hash: hash}
+}
+
+// WithNamespace returns a [Store] whose shards are each scoped to the
+// given namespace. Shards that do not implement [Namespaced] are used
+// unscoped. It panics if ns is not valid according to
+// [ValidateNamespace].
+func (s *ShardedStore) WithNamespace(ns string) Store {
+	mustValidateNamespace(ns)
+	scoped := make([]Store, len(s.shards))
+	for i, shard := range s.shards {
+		scoped[i] = shard
+		if n, ok := shard.(Namespaced); ok {
+			scoped[i] = n.WithNamespace(ns)
+		}
+	}
+	return &ShardedStore{shards: scoped, hash: s.hash}
+}
+
+// Get retrieves an entry by key from its shard.
+func (s *ShardedStore) Get(ctx context.Context, key string) (Entry, error) {
+	return s.shard(key).Get(ctx, key)
+}
+
+// Set stores an entry in its key's shard.
+func (s *ShardedStore) Set(ctx context.Context, entry Entry) error {
+	return s.shard(entry.Key).Set(ctx, entry)
+}
+
+// Delete removes an entry by key from its shard.
+func (s *ShardedStore) Delete(ctx context.Context, key string) error {
+	return s.shard(key).Delete(ctx, key)
+}
+
+// List returns the entries of all shards, listed concurrently. If any
+// shard fails, the first error is returned.
+func (s *ShardedStore) List(ctx context.Context) ([]Entry, error) {
+	results := make([][]Entry, len(s.shards))
+	errs := make([]error, len(s.shards))
+
+	var wg sync.WaitGroup
+	for i, shard := range s.shards {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = shard.List(ctx)
+		}()
+	}
+	wg.Wait()
+
+	var entries []Entry
+	for i := range s.shards {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+		entries = append(entries, results[i]...)
+	}
+
+	return entries, nil
+}
+
+// Close closes every shard that implements [io.Closer] and joins their
+// errors.
+func (s *ShardedStore) Close() error {
+	var errs []error
+	for _, shard := range s.shards {
+		if err := closeStore(shard); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (s *ShardedStore) shard(key string) Store {
+	i := s.hash(key) % len(s.shards)
+	if i < 0 {
+		i += len(s.shards)
+	}
+	return s.shards[i]
+}
+
+func fnvHash(key string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key)) //nolint:errcheck // hash writes never fail
+	return int(h.Sum32())
+}
@@ -0,0 +1,88 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestShardedStore_routesDeterministically(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	even, odd := vault.NewMemory(), vault.NewMemory()
+	byLength := func(key string) int { return len(key) }
+
+	s := vault.NewShardedStore([]vault.Store{even, odd}, byLength)
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "ab", Value: "2"}))
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "abc", Value: "3"}))
+
+	_, err := even.Get(ctx, "ab")
+	require.NoError(t, err)
+	_, err = odd.Get(ctx, "abc")
+	require.NoError(t, err)
+	_, err = even.Get(ctx, "abc")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	got, err := s.Get(ctx, "abc")
+	require.NoError(t, err)
+	assert.Equal(t, "3", got.Value)
+
+	require.NoError(t, s.Delete(ctx, "abc"))
+	_, err = odd.Get(ctx, "abc")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestShardedStore_ListUnionsShards(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := vault.NewShardedStore([]vault.Store{vault.NewMemory(), vault.NewMemory()}, nil)
+
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		require.NoError(t, s.Set(ctx, vault.Entry{Key: k}))
+	}
+
+	entries, err := s.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+}
+
+func TestShardedStore_negativeHash(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := vault.NewShardedStore([]vault.Store{vault.NewMemory(), vault.NewMemory()}, func(string) int { return -3 })
+
+	require.NoError(t, s.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	got, err := s.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+}
+
+func TestShardedStore_Namespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	s := vault.NewShardedStore([]vault.Store{vault.NewMemory(), vault.NewMemory()}, nil)
+
+	prod := s.WithNamespace("prod")
+	qa := s.WithNamespace("qa")
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "db", Value: "prod-db"}))
+	require.NoError(t, qa.Set(ctx, vault.Entry{Key: "db", Value: "qa-db"}))
+
+	got, err := prod.Get(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-db", got.Value)
+
+	entries, err := qa.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "qa-db", entries[0].Value)
+}
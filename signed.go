@@ -0,0 +1,239 @@
+package vault
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// ErrTampered is returned, wrapped, when an entry read through a [Signed]
+// store does not match its signature, or has none and signatures are
+// required.
+var ErrTampered = errors.New("vault: signature mismatch")
+
+// SignOption configures [Signed].
+type SignOption func(*signedStore)
+
+// WithRequireSignature controls whether a [Signed] store rejects entries
+// that carry no signature. The default is true: reading one fails with
+// [ErrTampered], since otherwise anyone able to write to the underlying
+// store could strip the signature from an entry along with changing it.
+// With false, unsigned entries, such as those written before signing was
+// enabled, are returned unchanged with an empty [Entry.Signature]; use
+// it only while migrating.
+func WithRequireSignature(require bool) SignOption {
+	return func(s *signedStore) { s.require = require }
+}
+
+// Signed wraps inner so that every entry written is signed with an
+// HMAC-SHA256 under key, and every entry read is verified against that
+// signature. The signature covers the entry's namespace, key, value,
+// creation and expiry times, tags, metadata and read-only flag, and is
+// kept in [Entry.Signature]. Any of those changed directly in the
+// underlying store, including by copying the entry to another namespace,
+// fails verification with [ErrTampered]. Source and the rotation fields
+// are not signed.
+//
+// Signing detects tampering but does not hide values; combine it with
+// [Encrypted] for confidentiality. Close is passed on to inner when it
+// implements [io.Closer]. If inner implements [Namespaced], so does the
+// returned store.
+func Signed(inner Store, key []byte, opts ...SignOption) Store {
+	s := signedStore{Store: inner, key: key, require: true}
+	for _, opt := range opts {
+		opt(&s)
+	}
+	return s.wrap(inner)
+}
+
+type signedStore struct {
+	Store
+	key       []byte
+	require   bool
+	namespace string
+}
+
+type namespacedSignedStore struct {
+	signedStore
+	ns Namespaced
+}
+
+// wrap returns a copy of s over inner, keeping the namespace capability.
+func (s signedStore) wrap(inner Store) Store {
+	s.Store = inner
+	if ns, ok := inner.(Namespaced); ok {
+		return namespacedSignedStore{signedStore: s, ns: ns}
+	}
+	return s
+}
+
+func (s namespacedSignedStore) WithNamespace(namespace string) Store {
+	s.namespace = namespace
+	return s.wrap(s.ns.WithNamespace(namespace))
+}
+
+func (s signedStore) Close() error {
+	return closeStore(s.Store)
+}
+
+func (s signedStore) Get(ctx context.Context, key string) (Entry, error) {
+	e, err := s.Store.Get(ctx, key)
+	if err != nil {
+		return Entry{}, err
+	}
+	return e, s.verify(e)
+}
+
+func (s signedStore) GetMany(ctx context.Context, keys []string) (map[string]Entry, error) {
+	found, err := getMany(ctx, s.Store, keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range found {
+		if err := s.verify(e); err != nil {
+			return nil, err
+		}
+	}
+	return found, nil
+}
+
+func (s signedStore) Exists(ctx context.Context, key string) (bool, error) {
+	return exists(ctx, s.Store, key)
+}
+
+func (s signedStore) Keys(ctx context.Context) ([]string, error) {
+	return listKeys(ctx, s.Store)
+}
+
+func (s signedStore) Snapshot(ctx context.Context) (map[string]Entry, error) {
+	snap, err := snapshot(ctx, s.Store)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range snap {
+		if err := s.verify(e); err != nil {
+			return nil, err
+		}
+	}
+	return snap, nil
+}
+
+func (s signedStore) ListByTag(ctx context.Context, key, value string) ([]Entry, error) {
+	entries, err := listByTag(ctx, s.Store, key, value)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if err := s.verify(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s signedStore) ListPrefix(ctx context.Context, prefix string) ([]Entry, error) {
+	entries, err := listPrefix(ctx, s.Store, prefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if err := s.verify(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func (s signedStore) KeysPrefix(ctx context.Context, prefix string) ([]string, error) {
+	return keysPrefix(ctx, s.Store, prefix)
+}
+
+func (s signedStore) Set(ctx context.Context, entry Entry) error {
+	return s.Store.Set(ctx, s.sign(entry))
+}
+
+func (s signedStore) SetMany(ctx context.Context, entries []Entry) error {
+	signed := make([]Entry, len(entries))
+	for i, e := range entries {
+		signed[i] = s.sign(e)
+	}
+	return setMany(ctx, s.Store, signed)
+}
+
+func (s signedStore) DeleteMany(ctx context.Context, keys []string) error {
+	return deleteMany(ctx, s.Store, keys)
+}
+
+func (s signedStore) List(ctx context.Context) ([]Entry, error) {
+	entries, err := s.Store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, e := range entries {
+		if err := s.verify(e); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+// sign sets the signature of e.
+func (s signedStore) sign(e Entry) Entry {
+	e.Signature = base64.StdEncoding.EncodeToString(s.mac(e))
+	return e
+}
+
+// verify checks the signature of e.
+func (s signedStore) verify(e Entry) error {
+	if e.Signature == "" {
+		if s.require {
+			return fmt.Errorf("%w: %q: missing signature", ErrTampered, e.Key)
+		}
+		return nil
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(e.Signature)
+	if err != nil || !hmac.Equal(sig, s.mac(e)) {
+		return fmt.Errorf("%w: %q", ErrTampered, e.Key)
+	}
+	return nil
+}
+
+// mac computes the HMAC of the signed fields of e in the store's
+// namespace. Each field is length prefixed, and maps are written in key
+// order with their size first, so that no two distinct entries produce the
+// same input.
+func (s signedStore) mac(e Entry) []byte {
+	h := hmac.New(sha256.New, s.key)
+	writeField(h, s.namespace)
+	writeField(h, e.Key)
+	writeField(h, e.Value)
+	writeField(h, e.CreatedAt.UTC().Format(time.RFC3339Nano))
+	writeField(h, e.ExpiresAt.UTC().Format(time.RFC3339Nano))
+	writeMap(h, e.Tags)
+	writeMap(h, e.Metadata)
+	writeField(h, strconv.FormatBool(e.ReadOnly))
+	return h.Sum(nil)
+}
+
+func writeMap(h hash.Hash, m map[string]string) {
+	h.Write(binary.AppendVarint(nil, int64(len(m))))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		writeField(h, k)
+		writeField(h, m[k])
+	}
+}
+
+func writeField(h hash.Hash, field string) {
+	h.Write(binary.AppendVarint(nil, int64(len(field))))
+	h.Write([]byte(field))
+}
@@ -0,0 +1,139 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestSigned_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	store := vault.Signed(inner, testKey(1))
+
+	created := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "db-password", Value: "hunter2", CreatedAt: created}))
+
+	got, err := store.Get(ctx, "db-password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", got.Value)
+	assert.NotEmpty(t, got.Signature)
+
+	entries, err := store.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestSigned_detectsTampering(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]func(e *vault.Entry){
+		"value":      func(e *vault.Entry) { e.Value = "attacker" },
+		"created at": func(e *vault.Entry) { e.CreatedAt = e.CreatedAt.Add(time.Second) },
+		"expires at": func(e *vault.Entry) { e.ExpiresAt = e.ExpiresAt.Add(time.Hour) },
+		"tags":       func(e *vault.Entry) { e.Tags["env"] = "dev" },
+		"metadata":   func(e *vault.Entry) { e.Metadata = nil },
+		"read only":  func(e *vault.Entry) { e.ReadOnly = false },
+		"signature":  func(e *vault.Entry) { e.Signature = "bm90IGEgc2lnbmF0dXJl" },
+	}
+
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			inner := vault.NewMemory()
+			store := vault.Signed(inner, testKey(1))
+			require.NoError(t, store.Set(ctx, vault.Entry{
+				Key:       "k",
+				Value:     "v",
+				CreatedAt: time.Now(),
+				ExpiresAt: time.Now().Add(time.Hour),
+				Tags:      map[string]string{"env": "prod"},
+				Metadata:  map[string]string{"owner": "payments"},
+				ReadOnly:  true,
+			}))
+
+			raw, err := inner.Get(ctx, "k")
+			require.NoError(t, err)
+			mutate(&raw)
+			require.NoError(t, inner.Set(ctx, raw))
+
+			_, err = store.Get(ctx, "k")
+			require.ErrorIs(t, err, vault.ErrTampered)
+
+			_, err = store.List(ctx)
+			require.ErrorIs(t, err, vault.ErrTampered)
+		})
+	}
+}
+
+func TestSigned_wrongKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	require.NoError(t, vault.Signed(inner, testKey(1)).Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	_, err := vault.Signed(inner, testKey(2)).Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrTampered)
+}
+
+func TestSigned_unsignedEntries(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	require.NoError(t, inner.Set(ctx, vault.Entry{Key: "legacy", Value: "v"}))
+
+	_, err := vault.Signed(inner, testKey(1)).Get(ctx, "legacy")
+	require.ErrorIs(t, err, vault.ErrTampered, "signatures are required by default")
+
+	lenient := vault.Signed(inner, testKey(1), vault.WithRequireSignature(false))
+	got, err := lenient.Get(ctx, "legacy")
+	require.NoError(t, err)
+	assert.Empty(t, got.Signature)
+}
+
+func TestSigned_namespaced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	v := vault.New(vault.WithStore(vault.Signed(inner, testKey(1))), vault.WithNamespace("prod"))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	raw, err := inner.WithNamespace("prod").Get(ctx, "k")
+	require.NoError(t, err)
+	raw.Value = "changed"
+	require.NoError(t, inner.WithNamespace("prod").Set(ctx, raw))
+
+	_, err = v.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrTampered)
+}
+
+func TestSigned_bindsNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	inner := vault.NewMemory()
+	store, ok := vault.Signed(inner, testKey(1)).(vault.Namespaced)
+	require.True(t, ok)
+	require.NoError(t, store.WithNamespace("qa").Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	raw, err := inner.WithNamespace("qa").Get(ctx, "k")
+	require.NoError(t, err)
+	require.NoError(t, inner.WithNamespace("prod").Set(ctx, raw))
+
+	_, err = store.WithNamespace("qa").Get(ctx, "k")
+	require.NoError(t, err)
+	_, err = store.WithNamespace("prod").Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrTampered, "an entry copied to another namespace fails verification")
+}
@@ -0,0 +1,228 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// MergeFunc resolves a collision between two entries with the same key.
+// existing is the entry kept so far and incoming is the entry produced
+// by a later source. The returned entry is kept.
+type MergeFunc func(existing, incoming Entry) Entry
+
+// LastWins is a [MergeFunc] that keeps the entry from the later source.
+func LastWins(_, incoming Entry) Entry { return incoming }
+
+// FirstWins is a [MergeFunc] that keeps the entry from the earlier source.
+func FirstWins(existing, _ Entry) Entry { return existing }
+
+// NamedSource attaches a name to src. The returned [Source] implements
+// [Named].
+func NamedSource(name string, src Source) Source {
+	return &namedSource{name: name, src: src}
+}
+
+type namedSource struct {
+	name string
+	src  Source
+}
+
+func (n *namedSource) Name() string { return n.name }
+
+func (n *namedSource) Fetch(ctx context.Context) ([]Entry, error) { return n.src.Fetch(ctx) }
+
+func (n *namedSource) HealthCheck(ctx context.Context) error { return healthCheck(ctx, n.src) }
+
+// TransformSource rewrites the entries fetched from inner before they
+// reach the vault. fn is called once per entry in order; returning false
+// drops the entry. Transforms compose by wrapping one TransformSource in
+// another. If inner implements [Named], so does the result, under the
+// same name.
+func TransformSource(inner Source, fn func(Entry) (Entry, bool)) Source {
+	var src Source = &transformSource{src: inner, fn: fn}
+	if n, ok := inner.(Named); ok {
+		src = NamedSource(n.Name(), src)
+	}
+	return src
+}
+
+type transformSource struct {
+	src Source
+	fn  func(Entry) (Entry, bool)
+}
+
+func (t *transformSource) Fetch(ctx context.Context) ([]Entry, error) {
+	entries, err := t.src.Fetch(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		if e, ok := t.fn(e); ok {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// MultiSource is a [Source] that fans out to several child sources
+// concurrently and merges their results into one set of entries. Unlike
+// registering each source on the vault with [WithSource], a MultiSource is
+// a single unit: it can be wrapped as a whole and nested inside other
+// MultiSources. Use [NewMultiSource] to create one.
+type MultiSource struct {
+	sources []Source
+	merge   MergeFunc
+}
+
+// NewMultiSource creates a [MultiSource] over the given sources. Key
+// collisions are resolved with [LastWins] in source order; use
+// [MultiSource.WithMergeFunc] to change the policy.
+func NewMultiSource(sources ...Source) *MultiSource {
+	return &MultiSource{sources: sources, merge: LastWins}
+}
+
+// MergeSources combines sources into one [Source] that fetches them
+// concurrently and de-duplicates entries by key, the later source winning.
+// It is shorthand for [NewMultiSource]; call [MultiSource.WithMergeFunc]
+// on the result to change how duplicates are resolved.
+func MergeSources(sources ...Source) *MultiSource {
+	return NewMultiSource(sources...)
+}
+
+// WithMergeFunc returns a copy of the MultiSource that resolves key
+// collisions with fn. Children are merged in the order they were given,
+// regardless of which finishes first.
+func (m *MultiSource) WithMergeFunc(fn MergeFunc) *MultiSource {
+	return &MultiSource{sources: m.sources, merge: fn}
+}
+
+// Name combines the names of the children, e.g. "multi(env,ssm)".
+// Children that do not implement [Named] are identified by position.
+func (m *MultiSource) Name() string {
+	names := make([]string, len(m.sources))
+	for i, src := range m.sources {
+		names[i] = sourceName(i, src)
+	}
+	return "multi(" + strings.Join(names, ",") + ")"
+}
+
+// Fetch fetches from all children concurrently and merges the results.
+// If any child fails, the remaining fetches are cancelled and the first
+// error is returned.
+func (m *MultiSource) Fetch(ctx context.Context) ([]Entry, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	results := make([][]Entry, len(m.sources))
+	for i, src := range m.sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			entries, err := src.Fetch(ctx)
+			if err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("vault: fetch %s: %w", sourceName(i, src), err)
+					cancel()
+				})
+				return
+			}
+			results[i] = entries
+		}()
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	return mergeEntries(results, m.merge), nil
+}
+
+// mergeEntries flattens results, resolving duplicate keys with merge.
+// Keys keep the order in which they were first seen.
+func mergeEntries(results [][]Entry, merge MergeFunc) []Entry {
+	var merged []Entry
+	index := make(map[string]int)
+
+	for _, entries := range results {
+		for _, e := range entries {
+			if i, ok := index[e.Key]; ok {
+				merged[i] = merge(merged[i], e)
+				continue
+			}
+			index[e.Key] = len(merged)
+			merged = append(merged, e)
+		}
+	}
+
+	return merged
+}
+
+// HealthCheck checks every child that implements [HealthChecker] and
+// joins their errors.
+func (m *MultiSource) HealthCheck(ctx context.Context) error {
+	var errs []error
+	for i, src := range m.sources {
+		if err := healthCheck(ctx, src); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sourceName(i, src), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// healthCheck checks src if it implements [HealthChecker] and reports it
+// healthy otherwise.
+func healthCheck(ctx context.Context, src Source) error {
+	if hc, ok := src.(HealthChecker); ok {
+		return hc.HealthCheck(ctx)
+	}
+	return nil
+}
+
+// keyFetcher returns src as a [KeyFetcher], looking through the wrappers
+// added by [NamedSource] and [WithSourceNamespace], or false if src cannot
+// fetch single keys.
+func keyFetcher(src Source) (KeyFetcher, bool) {
+	switch s := src.(type) {
+	case *namedSource:
+		return keyFetcher(s.src)
+	case *mountedSource:
+		return keyFetcher(s.Source)
+	}
+	kf, ok := src.(KeyFetcher)
+	return kf, ok
+}
+
+// fetchKeySource adapts the FetchKey method of kf for key to a [Source],
+// so that single-key fetches go through the same retries, timeouts and
+// semaphore as full ones.
+func fetchKeySource(kf KeyFetcher, key string) Source {
+	return SourceFunc(func(ctx context.Context) ([]Entry, error) {
+		e, ok, err := kf.FetchKey(ctx, key)
+		if err != nil || !ok {
+			return nil, err
+		}
+		return []Entry{e}, nil
+	})
+}
+
+// sourceName identifies src for messages and configuration, preferring
+// its [Named] identity and falling back to its position.
+func sourceName(i int, src Source) string {
+	if n, ok := src.(Named); ok {
+		return n.Name()
+	}
+	return fmt.Sprintf("source %d", i)
+}
@@ -0,0 +1,223 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestMultiSource_fetchesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	started := make(chan struct{}, 2)
+	release := make(chan struct{})
+
+	blocking := func(key string) vault.Source {
+		return vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+			started <- struct{}{}
+			select {
+			case <-release:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return []vault.Entry{{Key: key, Value: key}}, nil
+		})
+	}
+
+	m := vault.NewMultiSource(blocking("a"), blocking("b"))
+
+	done := make(chan struct{})
+	var (
+		entries []vault.Entry
+		err     error
+	)
+	go func() {
+		defer close(done)
+		entries, err = m.Fetch(context.Background())
+	}()
+
+	for range 2 {
+		select {
+		case <-started:
+		case <-time.After(time.Second):
+			t.Fatal("children were not fetched concurrently")
+		}
+	}
+	close(release)
+	<-done
+
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestMultiSource_mergeLastWins(t *testing.T) {
+	t.Parallel()
+
+	first := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		time.Sleep(5 * time.Millisecond) // finish last; order must still win
+		return []vault.Entry{{Key: "k", Value: "first"}, {Key: "a", Value: "1"}}, nil
+	})
+	second := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "second"}}, nil
+	})
+
+	entries, err := vault.NewMultiSource(first, second).Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "k", entries[0].Key)
+	assert.Equal(t, "second", entries[0].Value)
+}
+
+func TestMultiSource_mergeFirstWins(t *testing.T) {
+	t.Parallel()
+
+	first := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "first"}}, nil
+	})
+	second := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "second"}}, nil
+	})
+
+	m := vault.NewMultiSource(first, second).WithMergeFunc(vault.FirstWins)
+
+	entries, err := m.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "first", entries[0].Value)
+}
+
+func TestMultiSource_errorCancelsSiblings(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("boom")
+	failing := vault.NamedSource("bad", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	}))
+	slow := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+
+	_, err := vault.NewMultiSource(slow, failing).Fetch(context.Background())
+	require.ErrorIs(t, err, errFetch)
+	assert.Contains(t, err.Error(), "bad")
+}
+
+func TestMultiSource_nested(t *testing.T) {
+	t.Parallel()
+
+	leaf := func(key string) vault.Source {
+		return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+			return []vault.Entry{{Key: key}}, nil
+		})
+	}
+
+	inner := vault.NewMultiSource(leaf("a"), leaf("b"))
+	outer := vault.NewMultiSource(inner, leaf("c"))
+
+	entries, err := outer.Fetch(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, entries, 3)
+}
+
+func TestMultiSource_Name(t *testing.T) {
+	t.Parallel()
+
+	env := vault.NamedSource("env", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, nil
+	}))
+	anon := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+
+	m := vault.NewMultiSource(env, anon)
+	assert.Equal(t, "multi(env,source 1)", m.Name())
+
+	nested := vault.NewMultiSource(m, env)
+	assert.Equal(t, "multi(multi(env,source 1),env)", nested.Name())
+}
+
+func TestMergeSources(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	static := func(entries ...vault.Entry) vault.Source {
+		return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return entries, nil })
+	}
+
+	inner := vault.MergeSources(static(vault.Entry{Key: "k", Value: "inner-1"}), static(vault.Entry{Key: "k", Value: "inner-2"}))
+	merged := vault.MergeSources(static(vault.Entry{Key: "k", Value: "outer"}, vault.Entry{Key: "a"}), inner)
+
+	entries, err := merged.Fetch(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "inner-2", entries[0].Value)
+
+	entries, err = merged.WithMergeFunc(vault.FirstWins).Fetch(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "outer", entries[0].Value)
+
+	errDown := errors.New("down")
+	failing := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errDown })
+	_, err = vault.MergeSources(static(vault.Entry{Key: "a"}), failing).Fetch(ctx)
+	require.ErrorIs(t, err, errDown)
+}
+
+func TestTransformSource_rewritesKeys(t *testing.T) {
+	t.Parallel()
+
+	inner := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "app/db-host", Value: "db"}, {Key: "app/port", Value: "5432"}}, nil
+	})
+	stripped := vault.TransformSource(inner, func(e vault.Entry) (vault.Entry, bool) {
+		e.Key = strings.TrimPrefix(e.Key, "app/")
+		return e, true
+	})
+	upper := vault.TransformSource(stripped, func(e vault.Entry) (vault.Entry, bool) {
+		e.Key = strings.ToUpper(e.Key)
+		return e, true
+	})
+
+	entries, err := upper.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, "DB-HOST", entries[0].Key)
+	assert.Equal(t, "db", entries[0].Value)
+	assert.Equal(t, "PORT", entries[1].Key)
+}
+
+func TestTransformSource_dropsEntries(t *testing.T) {
+	t.Parallel()
+
+	inner := vault.NamedSource("ssm", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "internal.token"}, {Key: "api-key"}}, nil
+	}))
+	src := vault.TransformSource(inner, func(e vault.Entry) (vault.Entry, bool) {
+		return e, !strings.HasPrefix(e.Key, "internal.")
+	})
+
+	entries, err := src.Fetch(context.Background())
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "api-key", entries[0].Key)
+
+	named, ok := src.(vault.Named)
+	require.True(t, ok)
+	assert.Equal(t, "ssm", named.Name())
+}
+
+func TestTransformSource_propagatesError(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("boom")
+	inner := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, errFetch })
+	src := vault.TransformSource(inner, func(e vault.Entry) (vault.Entry, bool) { return e, true })
+
+	_, err := src.Fetch(context.Background())
+	require.ErrorIs(t, err, errFetch)
+}
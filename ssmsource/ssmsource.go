@@ -0,0 +1,87 @@
+// Package ssmsource implements a [vault.Source] that reads parameters
+// from AWS Systems Manager Parameter Store.
+package ssmsource
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+
+	"github.com/bjaus/vault"
+)
+
+// Client is the subset of the SSM API used by [Source]. *ssm.Client
+// satisfies it.
+type Client interface {
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
+// Source is a [vault.Source] over every parameter beneath a path. It
+// implements [vault.Named] as "ssm".
+type Source struct {
+	client Client
+	path   string
+}
+
+// New creates a [Source] that reads the parameters under path, e.g.
+// "/myapp/prod", using client.
+func New(client Client, path string) *Source {
+	return &Source{client: client, path: path}
+}
+
+// Name returns "ssm".
+func (s *Source) Name() string { return "ssm" }
+
+// Fetch reads all parameters beneath the path, recursively and with
+// SecureString values decrypted, following pagination to the end. Each
+// parameter becomes an entry keyed by its name relative to the path, so
+// "/myapp/prod/db/password" under "/myapp/prod" has the key "db/password".
+// AWS credentials attached with [vault.WithCredentials] are used in place
+// of the client's own.
+func (s *Source) Fetch(ctx context.Context) ([]vault.Entry, error) {
+	prefix := strings.TrimSuffix(s.path, "/") + "/"
+
+	pages := ssm.NewGetParametersByPathPaginator(s.client, &ssm.GetParametersByPathInput{
+		Path:           aws.String(s.path),
+		Recursive:      aws.Bool(true),
+		WithDecryption: aws.Bool(true),
+	})
+
+	var entries []vault.Entry
+	for pages.HasMorePages() {
+		page, err := pages.NextPage(ctx, withCredentials(ctx)...)
+		if err != nil {
+			return nil, fmt.Errorf("ssmsource: get parameters by path %s: %w", s.path, err)
+		}
+		for _, p := range page.Parameters {
+			name := aws.ToString(p.Name)
+			entries = append(entries, vault.Entry{
+				Key:    strings.TrimPrefix(name, prefix),
+				Value:  aws.ToString(p.Value),
+				Source: "ssm",
+			})
+		}
+	}
+	return entries, nil
+}
+
+// withCredentials returns the request options that sign with the AWS
+// credentials attached to ctx, if any.
+func withCredentials(ctx context.Context) []func(*ssm.Options) {
+	creds, ok := vault.CredentialsFromContext(ctx)
+	if !ok || creds.AccessKeyID == "" {
+		return nil
+	}
+	provider := aws.CredentialsProviderFunc(func(context.Context) (aws.Credentials, error) {
+		return aws.Credentials{
+			AccessKeyID:     creds.AccessKeyID,
+			SecretAccessKey: creds.SecretAccessKey,
+			SessionToken:    creds.SessionToken,
+			Source:          "vault.WithCredentials",
+		}, nil
+	})
+	return []func(*ssm.Options){func(o *ssm.Options) { o.Credentials = provider }}
+}
@@ -0,0 +1,103 @@
+package ssmsource_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/ssmsource"
+)
+
+// fakeSSM serves pages in order, keyed by the incoming NextToken.
+type fakeSSM struct {
+	pages   map[string]*ssm.GetParametersByPathOutput
+	err     error
+	calls   []*ssm.GetParametersByPathInput
+	options []ssm.Options
+}
+
+func (f *fakeSSM) GetParametersByPath(_ context.Context, in *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	f.calls = append(f.calls, in)
+	var o ssm.Options
+	for _, fn := range optFns {
+		fn(&o)
+	}
+	f.options = append(f.options, o)
+	if f.err != nil {
+		return nil, f.err
+	}
+	return f.pages[aws.ToString(in.NextToken)], nil
+}
+
+func param(name, value string) types.Parameter {
+	return types.Parameter{Name: aws.String(name), Value: aws.String(value)}
+}
+
+func TestSource_Fetch_paginates(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeSSM{pages: map[string]*ssm.GetParametersByPathOutput{
+		"": {
+			Parameters: []types.Parameter{param("/myapp/prod/db-host", "db.internal")},
+			NextToken:  aws.String("page-2"),
+		},
+		"page-2": {
+			Parameters: []types.Parameter{param("/myapp/prod/db/password", "hunter2")},
+		},
+	}}
+
+	entries, err := ssmsource.New(client, "/myapp/prod").Fetch(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, []vault.Entry{
+		{Key: "db-host", Value: "db.internal", Source: "ssm"},
+		{Key: "db/password", Value: "hunter2", Source: "ssm"},
+	}, entries)
+
+	require.Len(t, client.calls, 2)
+	for _, in := range client.calls {
+		assert.Equal(t, "/myapp/prod", aws.ToString(in.Path))
+		assert.True(t, aws.ToBool(in.Recursive))
+		assert.True(t, aws.ToBool(in.WithDecryption))
+	}
+}
+
+func TestSource_Fetch_error(t *testing.T) {
+	t.Parallel()
+
+	errAWS := errors.New("AccessDeniedException")
+	client := &fakeSSM{err: errAWS}
+
+	_, err := ssmsource.New(client, "/myapp/prod").Fetch(context.Background())
+	require.ErrorIs(t, err, errAWS)
+	assert.Contains(t, err.Error(), "/myapp/prod")
+}
+
+func TestSource_Fetch_credentialsFromContext(t *testing.T) {
+	t.Parallel()
+
+	client := &fakeSSM{pages: map[string]*ssm.GetParametersByPathOutput{"": {}}}
+	ctx := vault.WithCredentials(context.Background(), vault.Credentials{
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		SessionToken:    "session",
+	})
+
+	_, err := ssmsource.New(client, "/myapp/prod").Fetch(ctx)
+	require.NoError(t, err)
+
+	require.Len(t, client.options, 1)
+	require.NotNil(t, client.options[0].Credentials)
+	creds, err := client.options[0].Credentials.Retrieve(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, "AKID", creds.AccessKeyID)
+	assert.Equal(t, "secret", creds.SecretAccessKey)
+	assert.Equal(t, "session", creds.SessionToken)
+}
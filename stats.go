@@ -0,0 +1,110 @@
+package vault
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+)
+
+// Stats is a point-in-time snapshot of the vault's operational state,
+// returned by [Vault.Stats].
+type Stats struct {
+	// RefreshInProgress is true while any refresh is running.
+	RefreshInProgress bool
+
+	// LastSuccessfulRefresh is when the most recent successful refresh
+	// completed. It is zero until a refresh succeeds.
+	LastSuccessfulRefresh time.Time
+
+	// Hits and Misses count lookups since the vault was created, as
+	// reported to [Metrics.IncHit] and [Metrics.IncMiss].
+	Hits   uint64
+	Misses uint64
+
+	// Refreshes counts the refreshes that have ended, explicit, automatic
+	// or in the background, and RefreshErrors how many of them failed.
+	Refreshes     uint64
+	RefreshErrors uint64
+}
+
+// counters keeps the cumulative counts reported by [Vault.Stats], so that
+// they are available without a [Metrics] implementation. It wraps the one
+// set with [WithMetrics], if any.
+type counters struct {
+	next Metrics
+
+	hits          atomic.Uint64
+	misses        atomic.Uint64
+	refreshes     atomic.Uint64
+	refreshErrors atomic.Uint64
+}
+
+func (c *counters) IncHit() {
+	c.hits.Add(1)
+	c.next.IncHit()
+}
+
+func (c *counters) IncMiss() {
+	c.misses.Add(1)
+	c.next.IncMiss()
+}
+
+func (c *counters) ObserveRefresh(d time.Duration, err error) {
+	c.refreshes.Add(1)
+	if err != nil {
+		c.refreshErrors.Add(1)
+	}
+	c.next.ObserveRefresh(d, err)
+}
+
+// Observer is notified of vault activity. Implementations must be safe
+// for concurrent use and should return quickly, since they are called
+// inline. Use [WithObserver] to attach one.
+type Observer interface {
+	// RefreshStarted is called when a refresh begins.
+	RefreshStarted()
+
+	// RefreshFinished is called when a refresh ends, with the error it
+	// returned or nil on success.
+	RefreshFinished(err error)
+}
+
+// Stats returns a snapshot of the vault's operational state. The counters
+// are read atomically but separately, so under concurrent use they may be
+// a few operations apart from each other.
+func (v *vault) Stats(_ context.Context) Stats {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	return Stats{
+		RefreshInProgress:     v.refreshing > 0,
+		LastSuccessfulRefresh: v.lastSuccess,
+		Hits:                  v.counts.hits.Load(),
+		Misses:                v.counts.misses.Load(),
+		Refreshes:             v.counts.refreshes.Load(),
+		RefreshErrors:         v.counts.refreshErrors.Load(),
+	}
+}
+
+func (v *vault) refreshStarted() {
+	v.mu.Lock()
+	v.refreshing++
+	v.mu.Unlock()
+
+	if v.observer != nil {
+		v.observer.RefreshStarted()
+	}
+}
+
+func (v *vault) refreshFinished(err error) {
+	v.mu.Lock()
+	v.refreshing--
+	if err == nil {
+		v.lastSuccess = v.now()
+	}
+	v.mu.Unlock()
+
+	if v.observer != nil {
+		v.observer.RefreshFinished(err)
+	}
+}
@@ -0,0 +1,159 @@
+package vault_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func TestStats_refreshInProgress(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		close(entered)
+		<-release
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+
+	stats := v.Stats(ctx)
+	assert.False(t, stats.RefreshInProgress)
+	assert.True(t, stats.LastSuccessfulRefresh.IsZero())
+
+	done := make(chan error)
+	go func() { done <- v.Refresh(ctx) }()
+
+	<-entered
+	assert.True(t, v.Stats(ctx).RefreshInProgress)
+
+	close(release)
+	require.NoError(t, <-done)
+
+	stats = v.Stats(ctx)
+	assert.False(t, stats.RefreshInProgress)
+	assert.WithinDuration(t, time.Now(), stats.LastSuccessfulRefresh, time.Second)
+}
+
+func TestStats_failedRefreshKeepsLastSuccess(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	fail := false
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		if fail {
+			return nil, errors.New("down")
+		}
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+	require.NoError(t, v.Refresh(ctx))
+	last := v.Stats(ctx).LastSuccessfulRefresh
+
+	fail = true
+	require.Error(t, v.Refresh(ctx))
+	assert.Equal(t, last, v.Stats(ctx).LastSuccessfulRefresh)
+}
+
+func TestStats_counters(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errFetch := errors.New("upstream down")
+	var failing bool
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		if failing {
+			return nil, errFetch
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	_, err := v.Get(ctx, "k") // miss, then refresh
+	require.NoError(t, err)
+	_, err = v.Get(ctx, "k") // hit
+	require.NoError(t, err)
+	_, err = v.Get(ctx, "missing") // miss, no refresh within the window
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	failing = true
+	require.ErrorIs(t, v.Refresh(ctx), errFetch)
+
+	stats := v.Stats(ctx)
+	assert.Equal(t, uint64(1), stats.Hits)
+	assert.Equal(t, uint64(2), stats.Misses)
+	assert.Equal(t, uint64(2), stats.Refreshes)
+	assert.Equal(t, uint64(1), stats.RefreshErrors)
+}
+
+func TestStats_countersConcurrent(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Go(func() {
+			for range 100 {
+				_, _ = v.Get(ctx, "k")       //nolint:errcheck // counted either way
+				_, _ = v.Get(ctx, "missing") //nolint:errcheck // counted either way
+			}
+		})
+	}
+	wg.Wait()
+
+	stats := v.Stats(ctx)
+	assert.Equal(t, uint64(800), stats.Hits)
+	assert.Equal(t, uint64(800), stats.Misses)
+}
+
+func TestObserver_notifiedOnTransitions(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("down")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	})
+
+	obs := &recordingObserver{}
+	v := vault.New(vault.WithSource(src), vault.WithObserver(obs))
+
+	require.Error(t, v.Refresh(context.Background()))
+
+	obs.mu.Lock()
+	defer obs.mu.Unlock()
+	assert.Equal(t, []string{"started", "finished"}, obs.events)
+	require.ErrorIs(t, obs.lastErr, errFetch)
+}
+
+type recordingObserver struct {
+	mu      sync.Mutex
+	events  []string
+	lastErr error
+}
+
+func (o *recordingObserver) RefreshStarted() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "started")
+}
+
+func (o *recordingObserver) RefreshFinished(err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.events = append(o.events, "finished")
+	o.lastErr = err
+}
@@ -0,0 +1,170 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+var (
+	errUnmarshalTarget = errors.New("want a non-nil pointer to a struct")
+	errUnsupportedType = errors.New("unsupported field type")
+)
+
+// Unmarshal lists the vault's entries and copies their values into the
+// struct pointed to by out. Fields are matched by a `vault:"key"` tag;
+// untagged fields are ignored, except nested structs and pointers to
+// structs, which are filled recursively and allocated as needed. A
+// pointer to a struct type that is already being filled, as in a linked
+// list, is left alone rather than followed.
+//
+// Supported field types are string, the signed and unsigned integer kinds,
+// bool and [time.Duration], or pointers to them. A key with no entry
+// leaves its field untouched unless the tag includes "required", as in
+// `vault:"db-host,required"`, in which case an error naming the field is
+// returned. Expired entries are treated as missing. Unmarshal does not
+// trigger a refresh.
+func (v *vault) Unmarshal(ctx context.Context, out any) error {
+	rv := reflect.ValueOf(out)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("vault: unmarshal: %w, got %T", errUnmarshalTarget, out)
+	}
+
+	entries, err := v.List(ctx)
+	if err != nil {
+		return fmt.Errorf("vault: unmarshal: %w", err)
+	}
+
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if !v.expired(e) {
+			values[e.Key] = e.Value
+		}
+	}
+
+	filling := map[reflect.Type]bool{rv.Elem().Type(): true}
+	return unmarshalStruct(rv.Elem(), values, "", filling)
+}
+
+// unmarshalStruct fills the tagged fields of rv from values. path is the
+// dotted name of rv within the top-level struct, used in errors. filling
+// holds the struct types being filled through pointers on the way to rv.
+func unmarshalStruct(rv reflect.Value, values map[string]string, path string, filling map[reflect.Type]bool) error {
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		field := rt.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := path + field.Name
+		fv := rv.Field(i)
+
+		tag, ok := field.Tag.Lookup("vault")
+		if tag == "-" {
+			continue
+		}
+		if !ok {
+			if err := unmarshalNested(fv, values, name, filling); err != nil {
+				return err
+			}
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		raw, found := values[key]
+		if !found {
+			if slices.Contains(strings.Split(opts, ","), "required") {
+				return fmt.Errorf("vault: unmarshal: field %s: required key %q: %w", name, key, ErrNotFound)
+			}
+			continue
+		}
+
+		if err := setField(fv, raw); err != nil {
+			return fmt.Errorf("vault: unmarshal: field %s: key %q value %q: %w", name, key, raw, err)
+		}
+	}
+	return nil
+}
+
+// unmarshalNested recurses into untagged struct and pointer-to-struct
+// fields. Other untagged fields are ignored. A nil pointer is only
+// allocated if something beneath it was set. Pointers to a type in
+// filling are skipped, so that recursive types terminate.
+func unmarshalNested(fv reflect.Value, values map[string]string, name string, filling map[reflect.Type]bool) error {
+	switch {
+	case fv.Kind() == reflect.Struct:
+		return unmarshalStruct(fv, values, name+".", filling)
+	case fv.Kind() == reflect.Pointer && fv.Type().Elem().Kind() == reflect.Struct:
+		elem := fv.Type().Elem()
+		if filling[elem] {
+			return nil
+		}
+		filling[elem] = true
+		defer delete(filling, elem)
+
+		if !fv.IsNil() {
+			return unmarshalStruct(fv.Elem(), values, name+".", filling)
+		}
+		tmp := reflect.New(elem)
+		if err := unmarshalStruct(tmp.Elem(), values, name+".", filling); err != nil {
+			return err
+		}
+		if !tmp.Elem().IsZero() {
+			fv.Set(tmp)
+		}
+	}
+	return nil
+}
+
+// setField parses raw into fv according to its type.
+func setField(fv reflect.Value, raw string) error {
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			fv.Set(reflect.New(fv.Type().Elem()))
+		}
+		fv = fv.Elem()
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		fv.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		fv.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, fv.Type().Bits())
+		if err != nil {
+			return unwrapNumError(err)
+		}
+		fv.SetUint(n)
+	default:
+		return fmt.Errorf("%w %s", errUnsupportedType, fv.Type())
+	}
+	return nil
+}
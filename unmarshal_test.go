@@ -0,0 +1,177 @@
+package vault_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func seededVault(t *testing.T, values map[string]string) vault.Vault {
+	t.Helper()
+
+	v := vault.New()
+	for k, val := range values {
+		require.NoError(t, v.Set(context.Background(), vault.Entry{Key: k, Value: val}))
+	}
+	return v
+}
+
+func TestUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	type database struct {
+		Host string `vault:"db-host"`
+		Port int    `vault:"db-port"`
+	}
+	type cache struct {
+		TTL time.Duration `vault:"cache-ttl"`
+	}
+	type tls struct {
+		Cert string `vault:"tls-cert"`
+	}
+	type config struct {
+		Name     string        `vault:"name"`
+		Debug    bool          `vault:"debug"`
+		Timeout  time.Duration `vault:"timeout"`
+		Workers  *int          `vault:"workers"`
+		Missing  string        `vault:"missing"`
+		Ignored  string
+		Skipped  string `vault:"-"`
+		Database database
+		Cache    *cache
+		TLS      *tls
+	}
+
+	v := seededVault(t, map[string]string{
+		"name":      "api",
+		"debug":     "true",
+		"timeout":   "5s",
+		"workers":   "8",
+		"db-host":   "db.internal",
+		"db-port":   "5432",
+		"cache-ttl": "1m",
+		"-":         "never",
+	})
+
+	cfg := config{Missing: "default"}
+	require.NoError(t, v.Unmarshal(context.Background(), &cfg))
+
+	assert.Equal(t, "api", cfg.Name)
+	assert.True(t, cfg.Debug)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	require.NotNil(t, cfg.Workers)
+	assert.Equal(t, 8, *cfg.Workers)
+	assert.Equal(t, "default", cfg.Missing, "missing keys leave the field untouched")
+	assert.Empty(t, cfg.Ignored)
+	assert.Empty(t, cfg.Skipped)
+	assert.Equal(t, database{Host: "db.internal", Port: 5432}, cfg.Database)
+	require.NotNil(t, cfg.Cache)
+	assert.Equal(t, time.Minute, cfg.Cache.TTL)
+	assert.Nil(t, cfg.TLS, "nested pointers with nothing set stay nil")
+}
+
+func TestUnmarshal_required(t *testing.T) {
+	t.Parallel()
+
+	var cfg struct {
+		Inner struct {
+			Token string `vault:"api-token,required"`
+		}
+	}
+
+	err := seededVault(t, nil).Unmarshal(context.Background(), &cfg)
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.Contains(t, err.Error(), "Inner.Token")
+	assert.Contains(t, err.Error(), "api-token")
+}
+
+func TestUnmarshal_requiredWithOtherOptions(t *testing.T) {
+	t.Parallel()
+
+	var cfg struct {
+		Token string `vault:"api-token,omitempty,required"`
+	}
+
+	err := seededVault(t, nil).Unmarshal(context.Background(), &cfg)
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+type node struct {
+	Name string `vault:"name"`
+	Next *node
+}
+
+func TestUnmarshal_recursiveType(t *testing.T) {
+	t.Parallel()
+
+	var cfg struct {
+		Head node
+	}
+
+	require.NoError(t, seededVault(t, map[string]string{"name": "n"}).Unmarshal(context.Background(), &cfg))
+	assert.Equal(t, "n", cfg.Head.Name)
+	require.NotNil(t, cfg.Head.Next)
+	assert.Equal(t, "n", cfg.Head.Next.Name)
+	assert.Nil(t, cfg.Head.Next.Next, "a type already being filled is not followed")
+
+	var list node
+	list.Next = &list
+	require.NoError(t, seededVault(t, map[string]string{"name": "n"}).Unmarshal(context.Background(), &list))
+	assert.Equal(t, "n", list.Name)
+}
+
+func TestUnmarshal_typeMismatch(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]any{
+		"int": &struct {
+			N int `vault:"k"`
+		}{},
+		"bool": &struct {
+			B bool `vault:"k"`
+		}{},
+		"duration": &struct {
+			D time.Duration `vault:"k"`
+		}{},
+		"overflow": &struct {
+			N int8 `vault:"k"`
+		}{},
+		"unsupported": &struct {
+			F float64 `vault:"k"`
+		}{},
+	}
+
+	for name, out := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			value := "not-a-value"
+			if name == "overflow" {
+				value = "1000"
+			}
+
+			err := seededVault(t, map[string]string{"k": value}).Unmarshal(context.Background(), out)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), `"k"`)
+			assert.Contains(t, err.Error(), value)
+		})
+	}
+}
+
+func TestUnmarshal_invalidTarget(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New()
+
+	var s struct{}
+	require.Error(t, v.Unmarshal(context.Background(), s))
+	require.Error(t, v.Unmarshal(context.Background(), nil))
+
+	var n int
+	require.Error(t, v.Unmarshal(context.Background(), &n))
+}
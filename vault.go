@@ -47,23 +47,91 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"hash/maphash"
+	"io"
+	"iter"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 // ErrNotFound is returned when an entry does not exist in the store.
 var ErrNotFound = errors.New("vault: not found")
 
+// ErrBackendUnavailable is wrapped by stores around failures that mean the
+// backend as a whole cannot be reached (a missing keyring daemon, a down
+// server), as opposed to a problem with a single key or value. Store
+// implementations should wrap both the sentinel and the underlying error,
+// e.g. fmt.Errorf("mystore: get %q: %w: %w", key, ErrBackendUnavailable, err),
+// so callers can detect degraded mode with errors.Is.
+var ErrBackendUnavailable = errors.New("vault: backend unavailable")
+
+// ErrNotNamespaced is returned when an operation needs a namespace but the
+// configured store does not implement [Namespaced].
+var ErrNotNamespaced = errors.New("vault: store does not support namespaces")
+
+// ErrReadOnly is returned when a write targets an entry marked
+// [Entry.ReadOnly], or any entry of a [ReadOnly] store or a vault created
+// with [WithReadOnly].
+var ErrReadOnly = errors.New("vault: read-only")
+
+// ErrClosed is returned by every operation on a [Vault] after
+// [Vault.Close] has been called.
+var ErrClosed = errors.New("vault: closed")
+
+// ErrKeyExists is returned by [Vault.Rename] when the new key is already
+// in use and [WithOverwriteOnRename] is not set.
+var ErrKeyExists = errors.New("vault: key already exists")
+
+// ErrValueTooLarge is returned, wrapped with the key and sizes, when an
+// entry's value exceeds the limit set by [WithMaxValueSize].
+var ErrValueTooLarge = errors.New("vault: value too large")
+
 // Entry is a configuration or secret value.
 type Entry struct {
 	Key       string    `json:"key"`
 	Value     string    `json:"value"`
 	CreatedAt time.Time `json:"created_at"`
 	Source    string    `json:"source"`
+
+	// ReadOnly protects the entry from being overwritten by a later
+	// [Vault.Set] or [Vault.Refresh], and from being removed by
+	// [Vault.Delete], [Vault.DeleteMany] or [Vault.Rename].
+	ReadOnly bool `json:"read_only,omitempty"`
+
+	// RotateEvery is how often the value should be rotated. Zero means the
+	// entry has no rotation schedule.
+	RotateEvery time.Duration `json:"rotate_every,omitempty"`
+
+	// LastRotated is when the value was last rotated. When zero,
+	// [Entry.CreatedAt] is used instead.
+	LastRotated time.Time `json:"last_rotated,omitzero"`
+
+	// ExpiresAt, when non-zero, is when the entry expires. It takes
+	// precedence over any TTL configured on the vault.
+	ExpiresAt time.Time `json:"expires_at,omitzero"`
+
+	// Tags are free-form labels, such as env=prod or team=payments, that
+	// [Vault.ListByTag] can filter on.
+	Tags map[string]string `json:"tags,omitempty"`
+
+	// Metadata holds arbitrary per-entry details, such as an owner or a
+	// rotation policy, that the vault stores but does not interpret. See
+	// [Entry.Meta] and [Entry.WithMeta].
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Signature is the HMAC written by a [Signed] store. It is empty for
+	// entries that were never written through one.
+	Signature string `json:"signature,omitempty"`
 }
 
 // Store persists entries locally. Implementations must be safe for
-// concurrent use.
+// concurrent use, return [ErrNotFound] for missing keys, and wrap
+// connection-level failures with [ErrBackendUnavailable].
 type Store interface {
 	Get(ctx context.Context, key string) (Entry, error)
 	Set(ctx context.Context, entry Entry) error
@@ -80,6 +148,84 @@ type Namespaced interface {
 	WithNamespace(namespace string) Store
 }
 
+// BatchGetter is an optional interface for stores that can retrieve
+// several keys in one call. Keys that do not exist are absent from the
+// returned map rather than reported as errors. The vault uses it when
+// available and otherwise falls back to one [Store.Get] per key.
+type BatchGetter interface {
+	GetMany(ctx context.Context, keys []string) (map[string]Entry, error)
+}
+
+// Exister is an optional interface for stores that can report whether a
+// key is present without reading its value.
+type Exister interface {
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// KeyLister is an optional interface for stores that can list their keys
+// without reading values. Keys are returned sorted.
+type KeyLister interface {
+	Keys(ctx context.Context) ([]string, error)
+}
+
+// BatchSetter is an optional interface for stores that can write several
+// entries atomically: either all of them are stored or, on error, none
+// are. [Vault.Refresh] uses it when available and otherwise falls back to
+// one [Store.Set] per entry.
+type BatchSetter interface {
+	SetMany(ctx context.Context, entries []Entry) error
+}
+
+// BatchDeleter is an optional interface for stores that can remove
+// several keys in one call. As with [Store.Delete], missing keys are not
+// an error. [Vault.DeleteMany] uses it when available and otherwise falls
+// back to one [Store.Delete] per key.
+type BatchDeleter interface {
+	DeleteMany(ctx context.Context, keys []string) error
+}
+
+// Renamer is an optional interface for stores that can move an entry to a
+// new key in one step. Rename stores the entry under newKey, replacing any
+// entry there, and removes oldKey, returning [ErrNotFound] if oldKey is
+// absent. [Vault.Rename] uses it when available and otherwise falls back
+// to [Store.Set] followed by [Store.Delete].
+type Renamer interface {
+	Rename(ctx context.Context, oldKey, newKey string) error
+}
+
+// TagLister is an optional interface for stores that can find entries by
+// tag without listing everything. The vault uses it when available and
+// otherwise filters [Store.List].
+type TagLister interface {
+	ListByTag(ctx context.Context, key, value string) ([]Entry, error)
+}
+
+// PrefixLister is an optional interface for stores that can find the
+// entries and keys starting with a prefix without listing everything. The
+// prefix is relative to the store's namespace. KeysPrefix returns keys
+// sorted. The vault uses it when available and otherwise filters
+// [Store.List] or the store's keys.
+type PrefixLister interface {
+	ListPrefix(ctx context.Context, prefix string) ([]Entry, error)
+	KeysPrefix(ctx context.Context, prefix string) ([]string, error)
+}
+
+// Snapshotter is an optional interface for stores that can copy their
+// entries atomically, so the result reflects a single point in time.
+type Snapshotter interface {
+	Snapshot(ctx context.Context) (map[string]Entry, error)
+}
+
+// Streamer is an optional interface for stores that can yield their
+// entries one at a time rather than materializing them all, for stores
+// too large to list comfortably. Iteration stops early when the caller
+// breaks out of the loop; an error is yielded once, with the zero
+// [Entry], and ends the iteration. [Vault.ListStream] uses it when
+// available and otherwise iterates over [Store.List].
+type Streamer interface {
+	ListStream(ctx context.Context) (iter.Seq2[Entry, error], error)
+}
+
 // Source fetches entries from an external system. Implementations
 // are read-only providers — they produce entries but do not store them.
 type Source interface {
@@ -92,22 +238,135 @@ type SourceFunc func(ctx context.Context) ([]Entry, error)
 // Fetch calls the underlying function.
 func (f SourceFunc) Fetch(ctx context.Context) ([]Entry, error) { return f(ctx) }
 
+// Named is an optional interface for sources with a stable, human-readable
+// identity. Names appear in error messages and are used wherever a source
+// needs to be referred to by configuration. Use [NamedSource] to attach a
+// name to an arbitrary [Source].
+type Named interface {
+	Name() string
+}
+
+// HealthChecker is an optional interface for sources that can report
+// whether their backend is reachable more cheaply than a full
+// [Source.Fetch]. [Vault.HealthCheck] calls it on every source that
+// implements it.
+type HealthChecker interface {
+	HealthCheck(ctx context.Context) error
+}
+
+// KeyFetcher is an optional interface for sources that can fetch a
+// single key without fetching everything they hold. FetchKey reports
+// false, with no error, when the source does not have key. The vault uses
+// it for [Vault.GetFresh], and for [Vault.Get] misses when every source
+// implements it.
+type KeyFetcher interface {
+	FetchKey(ctx context.Context, key string) (Entry, bool, error)
+}
+
 // Vault is a [Store] that resolves entries from external [Source]
 // providers and caches them in a local [Store]. Use [New] to create one.
 type Vault interface {
 	Store
 	Refresh(ctx context.Context) error
+	GetFresh(ctx context.Context, key string) (Entry, error)
+	GetMaxAge(ctx context.Context, key string, maxAge time.Duration) (Entry, error)
+	Scoped(ns string) Vault
+	ListStream(ctx context.Context) (iter.Seq2[Entry, error], error)
+	RefreshReport(ctx context.Context) (RefreshResult, error)
+	GetMany(ctx context.Context, keys []string) (map[string]Entry, error)
+	DeleteMany(ctx context.Context, keys []string) error
+	Rename(ctx context.Context, oldKey, newKey string) error
+	ListFresh(ctx context.Context) ([]Entry, error)
+	ListByTag(ctx context.Context, key, value string) ([]Entry, error)
+	ListPrefix(ctx context.Context, prefix string) ([]Entry, error)
+	KeysPrefix(ctx context.Context, prefix string) ([]string, error)
+	Stale(ctx context.Context, olderThan time.Duration) ([]Entry, error)
+	Exists(ctx context.Context, key string) (bool, error)
+	Keys(ctx context.Context) ([]string, error)
+	Snapshot(ctx context.Context) (map[string]Entry, error)
+	GetInt(ctx context.Context, key string) (int, error)
+	GetBool(ctx context.Context, key string) (bool, error)
+	GetDuration(ctx context.Context, key string) (time.Duration, error)
+	GetOr(ctx context.Context, key, def string) (Entry, error)
+	Unmarshal(ctx context.Context, out any) error
+	DueForRotation(ctx context.Context) ([]Entry, error)
+	Rotate(ctx context.Context, key string, gen func(ctx context.Context, old Entry) (string, error)) (Entry, error)
+	Stats(ctx context.Context) Stats
+	Describe() VaultInfo
+	Explain(ctx context.Context, key string) (Resolution, error)
+	GetWithMeta(ctx context.Context, key string) (Entry, GetMeta, error)
+	Watch(ctx context.Context) (<-chan Event, error)
+	Start(ctx context.Context)
+	Stop()
+	HealthCheck(ctx context.Context) error
+	Close() error
 }
 
 // New creates a [Vault] with the given options.
 // If no store is provided, an in-memory store is used.
+//
+// A namespace set with [WithNamespace] is ignored if the store does not
+// implement [Namespaced]. New panics if the configuration is invalid: if
+// the namespace is applied but fails [ValidateNamespace], or if
+// [WithStrictNamespace] is set and the namespace cannot be applied. Use
+// [NewE] to get an error instead.
 func New(opts ...Option) Vault {
+	v, err := NewE(opts...)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
+
+// NewE is like [New] but returns an error instead of panicking when the
+// configuration is invalid.
+func NewE(opts ...Option) (Vault, error) {
 	cfg := &config{
 		store: NewMemory(),
 	}
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if cfg.logger == nil {
+		cfg.logger = nopLogger{}
+	}
+	if cfg.metrics == nil {
+		cfg.metrics = nopMetrics{}
+	}
+	if cfg.clock == nil {
+		cfg.clock = realClock{}
+	}
+
+	v, err := newVault(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.broker != nil {
+		v.id = newVaultID()
+		if len(cfg.sources) > 0 {
+			v.unsubscribe = cfg.broker.Subscribe(v.receive)
+		}
+	}
+	return v, nil
+}
+
+// newVault builds a vault from cfg, which must have its defaults filled
+// in. It does not subscribe to [WithBroker], so that views created by
+// [Vault.Scoped] can share their parent's subscription.
+func newVault(cfg *config) (*vault, error) {
+	if cfg.namespace != "" {
+		_, ok := cfg.store.(Namespaced)
+		switch {
+		case ok:
+			if err := ValidateNamespace(cfg.namespace); err != nil {
+				return nil, err
+			}
+		case cfg.strictNamespace:
+			return nil, fmt.Errorf("vault: namespace %q: %T: %w", cfg.namespace, cfg.store, ErrNotNamespaced)
+		}
+	}
+	counts := &counters{next: cfg.metrics}
+	cfg.metrics = counts
 
 	store := cfg.store
 	if cfg.namespace != "" {
@@ -115,118 +374,972 @@ func New(opts ...Option) Vault {
 			store = ns.WithNamespace(cfg.namespace)
 		}
 	}
+	store = cfg.decorate(store)
 
-	return &vault{
-		store:   store,
-		sources: cfg.sources,
-		ttl:     cfg.ttl,
+	v := &vault{
+		config:     *cfg,
+		store:      store,
+		cache:      store,
+		refreshTTL: cfg.ttl,
+		jitterSeed: maphash.MakeSeed(),
+		counts:     counts,
+	}
+	if cfg.readOnly {
+		v.store = ReadOnly(store)
+	}
+	for _, ttl := range cfg.sourceTTLs {
+		if ttl > 0 && (v.refreshTTL <= 0 || ttl < v.refreshTTL) {
+			v.refreshTTL = ttl
+		}
+	}
+	if cfg.maxFetches > 0 {
+		v.fetchSem = make(chan struct{}, cfg.maxFetches)
 	}
+	v.fetchesKeys = len(cfg.sources) > 0
+	for _, src := range cfg.sources {
+		if _, ok := keyFetcher(src); !ok {
+			v.fetchesKeys = false
+		}
+	}
+
+	return v, nil
 }
 
 type vault struct {
-	store   Store
-	sources []Source
-	ttl     time.Duration
+	config
 
-	mu          sync.Mutex
-	lastRefresh time.Time
+	store    Store
+	fetchSem chan struct{}
+	counts   *counters
+
+	// cache is store without the [WithReadOnly] guard. Refreshes and other
+	// internal bookkeeping write through it.
+	cache Store
+
+	// keys serializes [Vault.Rotate] per key. writes serializes the
+	// read-then-write sequences of Set, Delete, Rename and Refresh per key,
+	// so that a readonly check and the write it guards are not split by
+	// another write of the same key. Rotate takes keys and then, through
+	// Set, writes, so the two must stay separate.
+	keys     keyLocks
+	writes   keyLocks
+	inflight singleflight.Group
+	watch    watchers
+
+	// refreshTTL is the shortest configured TTL, global or per-source.
+	// Auto-refresh may run once per refreshTTL so that entries from the
+	// fastest-expiring source can be renewed.
+	refreshTTL time.Duration
+
+	mu             sync.Mutex
+	lastRefresh    time.Time
+	lastFailure    time.Time
+	lastRefreshErr error
+	lastSuccess    time.Time
+	refreshing     int
+
+	// invalidated is set when a [WithBroker] invalidation dropped keys,
+	// so that the next miss refreshes regardless of the TTL window.
+	invalidated bool
+
+	restampMu sync.Mutex
+	restamped atomic.Bool
+
+	// fetchesKeys is set when every source implements [KeyFetcher], so
+	// that a miss fetches the missing key alone.
+	fetchesKeys bool
+
+	// parent is the vault a [Vault.Scoped] view was created from, or nil.
+	parent *vault
+
+	// id identifies the vault in the invalidations it publishes to
+	// [WithBroker], and unsubscribe stops it receiving them.
+	id          string
+	unsubscribe func()
+
+	// views holds the open [Vault.Scoped] views of a vault with a
+	// broker, so that the invalidations it receives reach them too.
+	viewsMu sync.Mutex
+	views   map[*vault]struct{}
+
+	revalidating atomic.Bool
+	breakers     circuitBreakers
+	negative     negativeCache
+	jitterSeed   maphash.Seed
+
+	loopMu   sync.Mutex
+	loopStop context.CancelFunc
+	loopDone chan struct{}
+
+	closed atomic.Bool
 }
 
 // Get retrieves an entry by key. If the entry is missing or expired and
 // sources are configured, an automatic refresh is attempted at most once
-// per TTL period.
+// per TTL period. Callers that miss together share the refresh, which
+// is not cancelled when ctx is but gives up after a minute; a caller
+// whose ctx ends first stops waiting and returns ctx's error.
 func (v *vault) Get(ctx context.Context, key string) (Entry, error) {
-	e, err := v.store.Get(ctx, key)
-	if err == nil && !v.expired(e) {
+	return v.resolve(ctx, key, 0, nil)
+}
+
+// GetMaxAge is like [Vault.Get] but treats the entry as expired once it
+// is older than maxAge, in place of the TTLs set with [WithTTL] and
+// [WithSourceTTL], so that each caller can choose how fresh it needs the
+// entry to be. An [Entry.ExpiresAt] that has passed still expires it. An
+// expired entry triggers an auto-refresh as in Get, which may run even
+// within the usual once-per-TTL window when the entry is older than
+// maxAge. A maxAge of zero or less behaves exactly like Get.
+func (v *vault) GetMaxAge(ctx context.Context, key string, maxAge time.Duration) (Entry, error) {
+	return v.resolve(ctx, key, maxAge, nil)
+}
+
+// resolve implements [Vault.Get] and, with a positive maxAge,
+// [Vault.GetMaxAge], recording each decision it makes in trace when trace
+// is non-nil.
+func (v *vault) resolve(ctx context.Context, key string, maxAge time.Duration, trace *Resolution) (Entry, error) {
+	if err := v.restampSeeded(ctx); err != nil {
+		return Entry{}, err
+	}
+
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+
+	e, err := store.Get(ctx, key)
+	if err == nil && !v.expiredFor(e, maxAge) {
+		v.metrics.IncHit()
+		trace.record(StepCacheHit)
 		return e, nil
 	}
 
-	miss := errors.Is(err, ErrNotFound) || (err == nil && v.expired(e))
+	miss := errors.Is(err, ErrNotFound) || (err == nil && v.expiredFor(e, maxAge))
 	if !miss {
+		v.logger.Error("vault: store get failed", "key", key, "error", err)
 		return Entry{}, err
 	}
+	v.metrics.IncMiss()
+	if err == nil {
+		trace.record(StepExpired)
+	} else {
+		trace.record(StepCacheMiss)
+	}
 
-	if !v.shouldAutoRefresh() {
+	if rerr := v.failingRefresh(); rerr != nil {
+		if err == nil {
+			trace.record(StepServedStale)
+			return e, nil // serve the stale value rather than the error
+		}
+		trace.record(StepRefreshFailed)
+		return Entry{}, rerr
+	}
+
+	if err != nil && v.knownMissing(ctx, key) {
+		trace.record(StepRefreshSkipped)
 		return Entry{}, ErrNotFound
 	}
 
-	if rerr := v.Refresh(ctx); rerr != nil {
+	var expiredAt time.Time
+	if err == nil {
+		expiredAt = v.expiresAt(e)
+	}
+	if maxAge > 0 && err == nil {
+		if byAge := e.CreatedAt.Add(maxAge); byAge.After(expiredAt) {
+			expiredAt = byAge
+		}
+	}
+
+	if err == nil && v.staleWhileRevalidate && v.shouldAutoRefresh(expiredAt) {
+		v.revalidate(ctx, expiredAt)
+		trace.record(StepServedStale)
+		return e, nil
+	}
+
+	if !v.shouldAutoRefresh(expiredAt) {
+		trace.record(StepRefreshSkipped)
+		return Entry{}, ErrNotFound
+	}
+
+	v.logger.Debug("vault: auto-refresh", "key", key, "expired", err == nil)
+	start := time.Now()
+	var rerr error
+	if v.fetchesKeys {
+		rerr = v.autoRefreshKey(ctx, key)
+	} else {
+		rerr = v.autoRefresh(ctx, expiredAt)
+	}
+	if trace != nil {
+		trace.RefreshDuration = time.Since(start)
+	}
+	if rerr != nil {
+		trace.record(StepRefreshFailed)
+		if err == nil && v.serveStaleOnError {
+			v.logger.Warn("vault: serving stale entry after failed refresh", "key", key, "error", rerr)
+			trace.record(StepServedStale)
+			return e, nil
+		}
 		return Entry{}, rerr
 	}
+	trace.record(StepRefreshed)
+
+	e, err = store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		v.rememberMissing(ctx, key)
+	}
+	if err != nil {
+		return Entry{}, err
+	}
+	trace.record(StepCacheHit)
+	return e, nil
+}
+
+// GetMany retrieves several entries at once. Keys that cannot be found are
+// absent from the result. Misses and expired entries are covered by a
+// single auto-refresh, subject to the same limits as [Vault.Get].
+func (v *vault) GetMany(ctx context.Context, keys []string) (map[string]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := v.restampSeeded(ctx); err != nil {
+		return nil, err
+	}
+
+	found, err := getMany(ctx, store, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	stale := make(map[string]Entry)
+	var (
+		missing   []string
+		expiredAt time.Time
+	)
+	for _, key := range keys {
+		e, ok := found[key]
+		switch {
+		case !ok && v.knownMissing(ctx, key):
+			v.metrics.IncMiss()
+		case !ok:
+			v.metrics.IncMiss()
+			missing = append(missing, key)
+		case v.expired(e):
+			v.metrics.IncMiss()
+			stale[key] = e
+			delete(found, key)
+			if at := v.expiresAt(e); at.After(expiredAt) {
+				expiredAt = at
+			}
+		default:
+			v.metrics.IncHit()
+		}
+	}
+	if len(missing) == 0 && len(stale) == 0 {
+		return found, nil
+	}
+
+	if rerr := v.failingRefresh(); rerr != nil {
+		if len(missing) > 0 {
+			return nil, rerr
+		}
+		for key, e := range stale {
+			found[key] = e // serve stale values rather than the error
+		}
+		return found, nil
+	}
+
+	if !v.shouldAutoRefresh(expiredAt) {
+		return found, nil
+	}
 
-	return v.store.Get(ctx, key)
+	v.logger.Debug("vault: auto-refresh", "missing", len(missing), "expired", len(stale))
+	if rerr := v.autoRefresh(ctx, expiredAt); rerr != nil {
+		if len(missing) > 0 || !v.serveStaleOnError {
+			return nil, rerr
+		}
+		v.logger.Warn("vault: serving stale entries after failed refresh", "expired", len(stale), "error", rerr)
+		for key, e := range stale {
+			found[key] = e
+		}
+		return found, nil
+	}
+
+	retry := missing
+	for key := range stale {
+		retry = append(retry, key)
+	}
+	refreshed, err := getMany(ctx, store, retry)
+	if err != nil {
+		return nil, err
+	}
+	for key, e := range refreshed {
+		found[key] = e
+	}
+	for _, key := range missing {
+		if _, ok := refreshed[key]; !ok {
+			v.rememberMissing(ctx, key)
+		}
+	}
+
+	return found, nil
+}
+
+// Exists reports whether key is present in the store. Exists never
+// triggers a refresh. When a TTL is configured, globally or per source,
+// the entry is read and an expired one does not count as present.
+// Otherwise, if the store implements [Exister], it answers without
+// reading the value, and an entry whose [Entry.ExpiresAt] has passed
+// counts as present until it is removed.
+func (v *vault) Exists(ctx context.Context, key string) (bool, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if ex, ok := store.(Exister); ok && v.refreshTTL <= 0 {
+		return ex.Exists(ctx, key)
+	}
+
+	e, err := store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return !v.expired(e), nil
+}
+
+// exists reports whether key is present in store, using [Exister] when
+// available and [Store.Get] otherwise.
+func exists(ctx context.Context, store Store, key string) (bool, error) {
+	if ex, ok := store.(Exister); ok {
+		return ex.Exists(ctx, key)
+	}
+	_, err := store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// closeStore closes store if it implements [io.Closer].
+func closeStore(store Store) error {
+	if c, ok := store.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// getMany retrieves keys from store in one call when it implements
+// [BatchGetter], and one key at a time otherwise.
+func getMany(ctx context.Context, store Store, keys []string) (map[string]Entry, error) {
+	if bg, ok := store.(BatchGetter); ok {
+		return bg.GetMany(ctx, keys)
+	}
+
+	found := make(map[string]Entry, len(keys))
+	for _, key := range keys {
+		e, err := store.Get(ctx, key)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		found[key] = e
+	}
+	return found, nil
+}
+
+// setMany writes entries to store, atomically when it implements
+// [BatchSetter] and one at a time otherwise.
+func setMany(ctx context.Context, store Store, entries []Entry) error {
+	if bs, ok := store.(BatchSetter); ok {
+		return bs.SetMany(ctx, entries)
+	}
+
+	for _, e := range entries {
+		if err := store.Set(ctx, e); err != nil {
+			return fmt.Errorf("set %q: %w", e.Key, err)
+		}
+	}
+	return nil
 }
 
 // Set stores an entry directly. If [Entry.CreatedAt] is zero it is set
 // to the current time. If [Entry.Source] is empty it defaults to "manual".
+// Overwriting a read-only entry returns [ErrReadOnly]. Writes of the same
+// key by Set, Delete, Rename and Refresh do not interleave: each holds a
+// lock on the key from its read-only check until its write is done.
 func (v *vault) Set(ctx context.Context, entry Entry) error {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := v.checkSize(entry); err != nil {
+		return fmt.Errorf("vault: set %q: %w", entry.Key, err)
+	}
+
+	unlock := v.writes.lock(entry.Key)
+	defer unlock()
+
+	skip, err := v.checkWritable(ctx, store, entry.Key)
+	if err != nil {
+		return fmt.Errorf("vault: set %q: %w", entry.Key, err)
+	}
+	if skip {
+		return nil
+	}
+
 	if entry.CreatedAt.IsZero() {
-		entry.CreatedAt = time.Now()
+		entry.CreatedAt = v.now()
 	}
 	if entry.Source == "" {
 		entry.Source = "manual"
 	}
-	return v.store.Set(ctx, entry)
+	if err := store.Set(ctx, entry); err != nil {
+		return err
+	}
+
+	v.watch.publish(Event{Key: entry.Key, Entry: entry, Op: OpSet})
+	v.invalidateScoped(ctx, entry.Key)
+	return nil
 }
 
-// Delete removes an entry by key.
+// Delete removes an entry by key. Deleting an entry marked
+// [Entry.ReadOnly] fails with [ErrReadOnly], or does nothing under
+// [WithSkipReadOnly].
 func (v *vault) Delete(ctx context.Context, key string) error {
-	return v.store.Delete(ctx, key)
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
+	unlock := v.writes.lock(key)
+	defer unlock()
+
+	skip, err := v.checkWritable(ctx, store, key)
+	if err != nil {
+		return fmt.Errorf("vault: delete %q: %w", key, err)
+	}
+	if skip {
+		return nil
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		return err
+	}
+
+	v.watch.publish(Event{Key: key, Op: OpDelete})
+	v.invalidateScoped(ctx, key)
+	return nil
 }
 
-// List returns all entries in the store.
-func (v *vault) List(ctx context.Context) ([]Entry, error) {
-	return v.store.List(ctx)
+// DeleteMany removes several entries. Missing keys are not an error. If
+// any entry is marked [Entry.ReadOnly], nothing is deleted and
+// [ErrReadOnly] is returned, unless [WithSkipReadOnly] is set, in which
+// case the read-only entries are left and the rest deleted.
+func (v *vault) DeleteMany(ctx context.Context, keys []string) error {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
+	unlock := v.writes.lock(keys...)
+	defer unlock()
+
+	found, err := getMany(ctx, store, keys)
+	if err != nil {
+		return err
+	}
+	deletable := make([]string, 0, len(keys))
+	for _, key := range keys {
+		skip, err := v.skipWrite(found[key])
+		if err != nil {
+			return fmt.Errorf("vault: delete %q: %w", key, err)
+		}
+		if !skip {
+			deletable = append(deletable, key)
+		}
+	}
+	keys = deletable
+
+	if err := deleteMany(ctx, store, keys); err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		v.watch.publish(Event{Key: key, Op: OpDelete})
+	}
+	v.invalidateScoped(ctx, keys...)
+	return nil
 }
 
-// Refresh fetches entries from all configured sources and writes them
-// to the store. This always executes regardless of TTL.
-func (v *vault) Refresh(ctx context.Context) error {
-	now := time.Now()
+// Rename moves the entry at oldKey to newKey, keeping its value and
+// metadata, including [Entry.CreatedAt] and [Entry.Source]. It returns
+// [ErrNotFound] if oldKey is absent, and [ErrKeyExists] if newKey is
+// already in use unless [WithOverwriteOnRename] is set. Stores that
+// implement [Renamer] move the entry in one step; with others it is
+// written under newKey before oldKey is deleted.
+func (v *vault) Rename(ctx context.Context, oldKey, newKey string) error {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return err
+	}
+
+	unlock := v.writes.lock(oldKey, newKey)
+	defer unlock()
+
+	e, err := store.Get(ctx, oldKey)
+	if err != nil {
+		return fmt.Errorf("vault: rename %q: %w", oldKey, err)
+	}
+	if oldKey == newKey {
+		return nil
+	}
+	skip, err := v.skipWrite(e)
+	if err != nil {
+		return fmt.Errorf("vault: rename %q: %w", oldKey, err)
+	}
+	if skip {
+		return nil
+	}
 
-	for _, src := range v.sources {
-		entries, err := src.Fetch(ctx)
+	taken, err := exists(ctx, store, newKey)
+	if err != nil {
+		return fmt.Errorf("vault: rename %q to %q: %w", oldKey, newKey, err)
+	}
+	if taken {
+		if !v.overwriteOnRename {
+			return fmt.Errorf("vault: rename %q to %q: %w", oldKey, newKey, ErrKeyExists)
+		}
+		skip, err = v.checkWritable(ctx, store, newKey)
 		if err != nil {
-			return fmt.Errorf("vault: refresh: %w", err)
+			return fmt.Errorf("vault: rename %q to %q: %w", oldKey, newKey, err)
 		}
+		if skip {
+			return nil
+		}
+	}
+
+	moved := e
+	moved.Key = newKey
+	if r, ok := store.(Renamer); ok {
+		err = r.Rename(ctx, oldKey, newKey)
+	} else if err = store.Set(ctx, moved); err == nil {
+		err = store.Delete(ctx, oldKey)
+	}
+	if err != nil {
+		return fmt.Errorf("vault: rename %q to %q: %w", oldKey, newKey, err)
+	}
+
+	v.watch.publish(Event{Key: oldKey, Op: OpDelete})
+	v.watch.publish(Event{Key: newKey, Entry: moved, Op: OpSet})
+	v.invalidateScoped(ctx, oldKey, newKey)
+	return nil
+}
+
+// deleteMany removes keys from store, using [BatchDeleter] when available
+// and one [Store.Delete] per key otherwise.
+func deleteMany(ctx context.Context, store Store, keys []string) error {
+	if bd, ok := store.(BatchDeleter); ok {
+		return bd.DeleteMany(ctx, keys)
+	}
+
+	for _, key := range keys {
+		if err := store.Delete(ctx, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// List returns all entries in the store.
+func (v *vault) List(ctx context.Context) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return store.List(ctx)
+}
+
+// ListStream returns the entries in the store one at a time, streamed when
+// the store implements [Streamer]. The namespace set by
+// [WithNamespaceContext] applies as for [Vault.List].
+func (v *vault) ListStream(ctx context.Context) (iter.Seq2[Entry, error], error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listStream(ctx, store)
+}
+
+// listStream streams the entries of store when it implements [Streamer],
+// and otherwise iterates over the result of [Store.List].
+func listStream(ctx context.Context, store Store) (iter.Seq2[Entry, error], error) {
+	if s, ok := store.(Streamer); ok {
+		return s.ListStream(ctx)
+	}
 
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(yield func(Entry, error) bool) {
 		for _, e := range entries {
-			e.CreatedAt = now
-			if serr := v.store.Set(ctx, e); serr != nil {
-				return fmt.Errorf("vault: refresh: set %q: %w", e.Key, serr)
+			if !yield(e, nil) {
+				return
 			}
 		}
+	}, nil
+}
+
+// ListFresh returns the entries in the store that have not expired. If
+// any have, a single auto-refresh is attempted first, subject to the same
+// limits as [Vault.Get], so that refreshed values are included.
+func (v *vault) ListFresh(ctx context.Context) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	v.mu.Lock()
-	v.lastRefresh = now
-	v.mu.Unlock()
+	if err := v.restampSeeded(ctx); err != nil {
+		return nil, err
+	}
 
-	return nil
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		stale     int
+		expiredAt time.Time
+	)
+	for _, e := range entries {
+		if !v.expired(e) {
+			continue
+		}
+		stale++
+		if at := v.expiresAt(e); at.After(expiredAt) {
+			expiredAt = at
+		}
+	}
+
+	if stale > 0 && v.failingRefresh() == nil && v.shouldAutoRefresh(expiredAt) {
+		v.logger.Debug("vault: auto-refresh", "expired", stale)
+		if rerr := v.autoRefresh(ctx, expiredAt); rerr != nil {
+			return nil, rerr
+		}
+		if entries, err = store.List(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	return slices.DeleteFunc(entries, v.expired), nil
 }
 
-func (v *vault) shouldAutoRefresh() bool {
-	if len(v.sources) == 0 {
-		return false
+// Stale returns the entries created more than olderThan ago, oldest
+// first. Unlike expiry, this ignores any configured TTL, so it can find
+// entries that have not been refreshed recently even when nothing
+// expires.
+func (v *vault) Stale(ctx context.Context, olderThan time.Duration) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	v.mu.Lock()
-	defer v.mu.Unlock()
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
 
-	if v.lastRefresh.IsZero() {
-		return true
+	cutoff := v.now().Add(-olderThan)
+	stale := slices.DeleteFunc(entries, func(e Entry) bool { return !e.CreatedAt.Before(cutoff) })
+	slices.SortFunc(stale, func(a, b Entry) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Key, b.Key)
+	})
+	return stale, nil
+}
+
+// ListByTag returns the entries whose [Entry.Tags] map key to value. Like
+// [Vault.List], it includes expired entries and never triggers a refresh.
+func (v *vault) ListByTag(ctx context.Context, key, value string) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listByTag(ctx, store, key, value)
+}
+
+// listByTag finds the entries of store tagged key=value, using
+// [TagLister] when available and filtering [Store.List] otherwise.
+func listByTag(ctx context.Context, store Store, key, value string) ([]Entry, error) {
+	if tl, ok := store.(TagLister); ok {
+		return tl.ListByTag(ctx, key, value)
+	}
+
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(entries, func(e Entry) bool { return !e.hasTag(key, value) }), nil
+}
+
+// ListPrefix returns the entries whose key starts with prefix. The prefix
+// is relative to the vault's namespace, so with namespace "prod" the
+// prefix "db." matches prod's "db.host" but not another namespace's.
+// Like [Vault.List], it includes expired entries and never triggers a
+// refresh.
+func (v *vault) ListPrefix(ctx context.Context, prefix string) ([]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listPrefix(ctx, store, prefix)
+}
+
+// KeysPrefix returns the keys that start with prefix, sorted. The prefix
+// is relative to the vault's namespace, as with [Vault.ListPrefix].
+func (v *vault) KeysPrefix(ctx context.Context, prefix string) ([]string, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return keysPrefix(ctx, store, prefix)
+}
+
+// listPrefix returns the entries of store whose key starts with prefix,
+// using [PrefixLister] when available and filtering [Store.List]
+// otherwise.
+func listPrefix(ctx context.Context, store Store, prefix string) ([]Entry, error) {
+	if pl, ok := store.(PrefixLister); ok {
+		return pl.ListPrefix(ctx, prefix)
+	}
+
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(entries, func(e Entry) bool { return !strings.HasPrefix(e.Key, prefix) }), nil
+}
+
+// keysPrefix returns the sorted keys of store that start with prefix,
+// using [PrefixLister] when available and filtering the store's keys
+// otherwise.
+func keysPrefix(ctx context.Context, store Store, prefix string) ([]string, error) {
+	if pl, ok := store.(PrefixLister); ok {
+		return pl.KeysPrefix(ctx, prefix)
+	}
+
+	keys, err := listKeys(ctx, store)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(keys, func(k string) bool { return !strings.HasPrefix(k, prefix) }), nil
+}
+
+// hasTag reports whether e is tagged key=value.
+func (e Entry) hasTag(key, value string) bool {
+	v, ok := e.Tags[key]
+	return ok && v == value
+}
+
+// Keys returns the keys in the store, sorted. Like [Vault.List], it
+// includes expired entries and never triggers a refresh. Stores that
+// implement [KeyLister] answer without reading any values.
+func (v *vault) Keys(ctx context.Context) ([]string, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return listKeys(ctx, store)
+}
+
+// listKeys returns the sorted keys of store, using [KeyLister] when
+// available and [Store.List] otherwise.
+func listKeys(ctx context.Context, store Store) ([]string, error) {
+	if kl, ok := store.(KeyLister); ok {
+		return kl.Keys(ctx)
+	}
+
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		keys[i] = e.Key
+	}
+	slices.Sort(keys)
+	return keys, nil
+}
+
+// Snapshot returns a copy of every entry in the store, keyed by
+// [Entry.Key]. The copy is taken atomically when the store implements
+// [Snapshotter]; otherwise it is built from [Store.List] and may
+// interleave with concurrent writes.
+func (v *vault) Snapshot(ctx context.Context) (map[string]Entry, error) {
+	store, err := v.scoped(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return snapshot(ctx, store)
+}
+
+// snapshot copies the entries of store, using [Snapshotter] when
+// available and [Store.List] otherwise.
+func snapshot(ctx context.Context, store Store) (map[string]Entry, error) {
+	if sn, ok := store.(Snapshotter); ok {
+		return sn.Snapshot(ctx)
 	}
 
-	if v.ttl > 0 {
-		return time.Since(v.lastRefresh) > v.ttl
+	entries, err := store.List(ctx)
+	if err != nil {
+		return nil, err
 	}
+	snap := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		snap[e.Key] = e
+	}
+	return snap, nil
+}
+
+// restampSeeded runs once, on first use, when [WithRestampSeededEntries]
+// is set. It resets the CreatedAt of entries that did not come from a
+// source so a newly applied TTL does not expire them immediately. A failed
+// attempt is retried on the next use.
+func (v *vault) restampSeeded(ctx context.Context) error {
+	if !v.restamp || v.restamped.Load() {
+		return nil
+	}
+
+	v.restampMu.Lock()
+	defer v.restampMu.Unlock()
+
+	if v.restamped.Load() {
+		return nil
+	}
+
+	entries, err := v.store.List(ctx)
+	if err != nil {
+		return fmt.Errorf("vault: restamp: %w", err)
+	}
+
+	now := v.now()
+	for _, e := range entries {
+		if e.Source != "" && e.Source != "manual" {
+			continue
+		}
+		e.CreatedAt = now
+		if err := v.cache.Set(ctx, e); err != nil {
+			return fmt.Errorf("vault: restamp %q: %w", e.Key, err)
+		}
+	}
+
+	v.restamped.Store(true)
+	return nil
+}
+
+// checkWritable reports whether a write to key in store should be skipped
+// because the stored entry is read-only, or returns [ErrReadOnly] when such
+// writes are not configured to be skipped.
+func (v *vault) checkWritable(ctx context.Context, store Store, key string) (bool, error) {
+	existing, err := store.Get(ctx, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return v.skipWrite(existing)
+}
+
+// skipWrite is [vault.checkWritable] for an entry already read.
+func (v *vault) skipWrite(e Entry) (bool, error) {
+	if !e.ReadOnly {
+		return false, nil
+	}
+	if v.skipReadOnly {
+		return true, nil
+	}
+	return false, ErrReadOnly
+}
 
-	return false
+// checkSize enforces [WithMaxValueSize].
+func (v *vault) checkSize(e Entry) error {
+	if v.maxValueSize > 0 && len(e.Value) > v.maxValueSize {
+		return fmt.Errorf("%w: %d bytes exceeds the %d byte limit", ErrValueTooLarge, len(e.Value), v.maxValueSize)
+	}
+	return nil
 }
 
+// expiredFor is [vault.expired] with maxAge in place of the configured
+// TTLs when it is positive, for [Vault.GetMaxAge].
+func (v *vault) expiredFor(e Entry, maxAge time.Duration) bool {
+	if maxAge <= 0 {
+		return v.expired(e)
+	}
+	if !e.ExpiresAt.IsZero() && !v.now().Before(e.ExpiresAt) {
+		return true
+	}
+	return v.since(e.CreatedAt) > maxAge
+}
+
+// expired reports whether e has outlived its lifetime. A non-zero
+// [Entry.ExpiresAt] is authoritative; otherwise the TTL configured for its
+// [Entry.Source] applies if any, falling back to the global TTL.
 func (v *vault) expired(e Entry) bool {
-	if v.ttl <= 0 {
+	if !e.ExpiresAt.IsZero() {
+		return !v.now().Before(e.ExpiresAt)
+	}
+	ttl := v.entryTTL(e)
+	if ttl <= 0 {
 		return false
 	}
-	return time.Since(e.CreatedAt) > v.ttl
+	return v.since(e.CreatedAt) > v.jitter(e.Key, ttl)
+}
+
+// expiresAt returns when e expires: its [Entry.ExpiresAt] if set,
+// otherwise its creation time plus its jittered TTL, or the zero time when
+// no TTL applies. Passing this to [vault.shouldAutoRefresh] lets an entry
+// that jitter expired before the refresh window elapsed still trigger a
+// refresh.
+func (v *vault) expiresAt(e Entry) time.Time {
+	if !e.ExpiresAt.IsZero() {
+		return e.ExpiresAt
+	}
+	ttl := v.entryTTL(e)
+	if ttl <= 0 {
+		return time.Time{}
+	}
+	return e.CreatedAt.Add(v.jitter(e.Key, ttl))
+}
+
+// entryTTL returns the TTL configured for e's [Entry.Source], falling
+// back to the global TTL.
+func (v *vault) entryTTL(e Entry) time.Duration {
+	if sttl, ok := v.sourceTTLs[e.Source]; ok {
+		return sttl
+	}
+	return v.ttl
+}
+
+// jitter shortens ttl for key by up to the fraction set with
+// [WithTTLJitter]. The amount is derived from a hash of key seeded per
+// vault, so it is stable for the vault's lifetime but differs between
+// vaults.
+func (v *vault) jitter(key string, ttl time.Duration) time.Duration {
+	if v.ttlJitter <= 0 {
+		return ttl
+	}
+	u := float64(maphash.String(v.jitterSeed, key)>>11) / (1 << 53) // uniform in [0, 1)
+	return ttl - time.Duration(float64(ttl)*v.ttlJitter*u)
 }
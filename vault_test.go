@@ -1,8 +1,13 @@
 package vault_test
 
 import (
+	"bytes"
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -10,6 +15,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/clocktest"
 )
 
 func TestNew_defaultsToMemoryStore(t *testing.T) {
@@ -85,12 +91,13 @@ func TestGet_expired_autoRefresh(t *testing.T) {
 
 	ctx := context.Background()
 	store := vault.NewMemory()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
 
-	// Seed with an old entry.
+	// Seed with an entry that expires a minute from now.
 	require.NoError(t, store.Set(ctx, vault.Entry{
 		Key:       "stale",
 		Value:     "old",
-		CreatedAt: time.Now().Add(-time.Hour),
+		CreatedAt: clock.Now().Add(-59 * time.Minute),
 		Source:    "seed",
 	}))
 
@@ -103,14 +110,321 @@ func TestGet_expired_autoRefresh(t *testing.T) {
 	v := vault.New(
 		vault.WithStore(store),
 		vault.WithSource(src),
-		vault.WithTTL(time.Millisecond),
+		vault.WithTTL(time.Hour),
+		vault.WithClock(clock),
 	)
 
 	got, err := v.Get(ctx, "stale")
 	require.NoError(t, err)
+	assert.Equal(t, "old", got.Value, "entry has not expired yet")
+
+	clock.Advance(2 * time.Minute)
+
+	got, err = v.Get(ctx, "stale")
+	require.NoError(t, err)
 	assert.Equal(t, "fresh", got.Value)
 }
 
+func TestGet_staleWhileRevalidate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	release := make(chan struct{})
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		if calls.Add(1) > 1 {
+			<-release
+		}
+		return []vault.Entry{{Key: "k", Value: fmt.Sprint("v", calls.Load())}}, nil
+	})
+
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithTTL(time.Hour),
+		vault.WithClock(clock),
+		vault.WithStaleWhileRevalidate(),
+	)
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err, "a missing entry is refreshed synchronously")
+	assert.Equal(t, "v1", got.Value)
+
+	clock.Advance(2 * time.Hour)
+
+	for range 5 {
+		got, err = v.Get(ctx, "k")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", got.Value, "the stale value is served while the refresh is blocked")
+	}
+	close(release)
+
+	require.Eventually(t, func() bool {
+		got, err := v.Get(ctx, "k")
+		return err == nil && got.Value == "v2"
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, int32(2), calls.Load(), "background refreshes are collapsed")
+}
+
+func TestGet_staleWhileRevalidate_nothingToRevalidate(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	t.Run("no sources", func(t *testing.T) {
+		t.Parallel()
+
+		v := vault.New(vault.WithTTL(time.Hour), vault.WithClock(clock), vault.WithStaleWhileRevalidate())
+		require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v", CreatedAt: clock.Now().Add(-2 * time.Hour)}))
+
+		_, err := v.Get(ctx, "k")
+		require.ErrorIs(t, err, vault.ErrNotFound)
+		_, err = v.GetMaxAge(ctx, "k", time.Minute)
+		require.ErrorIs(t, err, vault.ErrNotFound)
+	})
+
+	t.Run("dropped by source", func(t *testing.T) {
+		t.Parallel()
+
+		clock := clocktest.New(clock.Now())
+		var calls atomic.Int32
+		src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+			if calls.Add(1) > 1 {
+				return nil, nil
+			}
+			return []vault.Entry{{Key: "k", Value: "v"}}, nil
+		})
+		v := vault.New(vault.WithSource(src), vault.WithTTL(time.Hour), vault.WithClock(clock), vault.WithStaleWhileRevalidate())
+
+		_, err := v.Get(ctx, "k")
+		require.NoError(t, err)
+		clock.Advance(2 * time.Hour)
+
+		require.Eventually(t, func() bool {
+			_, err := v.Get(ctx, "k")
+			return errors.Is(err, vault.ErrNotFound)
+		}, time.Second, time.Millisecond, "once a refresh has run, the stale entry is no longer served")
+	})
+}
+
+func TestGet_negativeCache(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithTTL(time.Minute),
+		vault.WithNegativeCacheTTL(time.Hour),
+		vault.WithClock(clock),
+	)
+
+	_, err := v.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.Equal(t, int32(1), calls.Load())
+
+	for range 3 {
+		clock.Advance(2 * time.Minute) // past the refresh TTL each time
+
+		_, err = v.Get(ctx, "missing")
+		require.ErrorIs(t, err, vault.ErrNotFound)
+		found, err := v.GetMany(ctx, []string{"missing"})
+		require.NoError(t, err)
+		assert.Empty(t, found)
+	}
+	assert.Equal(t, int32(1), calls.Load(), "a known-missing key must not refresh again")
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", got.Value)
+	assert.Equal(t, int32(2), calls.Load(), "existing keys still refresh when expired")
+
+	clock.Advance(time.Hour)
+	_, err = v.Get(ctx, "missing")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	assert.Equal(t, int32(3), calls.Load(), "the negative entry expires")
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "missing", Value: "now here"}))
+	got, err = v.Get(ctx, "missing")
+	require.NoError(t, err)
+	assert.Equal(t, "now here", got.Value)
+}
+
+func TestGet_negativeCacheManyKeys(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		return nil, nil
+	})
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithTTL(time.Minute),
+		vault.WithNegativeCacheTTL(time.Hour),
+		vault.WithClock(clock),
+	)
+
+	miss := func(key string) int32 {
+		before := calls.Load()
+		_, err := v.Get(ctx, key)
+		require.ErrorIs(t, err, vault.ErrNotFound)
+		return calls.Load() - before
+	}
+
+	for i := range 500 {
+		clock.Advance(2 * time.Minute)
+		assert.Equal(t, int32(1), miss(fmt.Sprint("key-", i)), "a new key refreshes")
+	}
+	clock.Advance(2 * time.Minute)
+	assert.Zero(t, miss("key-499"), "a recent miss is still remembered after pruning")
+	assert.Equal(t, int32(1), miss("key-0"), "an expired miss refreshes again")
+}
+
+func TestTTLJitter_spreadsExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	store := vault.NewMemory()
+
+	keys := make([]string, 32)
+	for i := range keys {
+		keys[i] = fmt.Sprint("key-", i)
+		require.NoError(t, store.Set(ctx, vault.Entry{Key: keys[i], CreatedAt: clock.Now(), Source: "src"}))
+	}
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Hour), vault.WithTTLJitter(0.5), vault.WithClock(clock))
+
+	live := func() int {
+		n := 0
+		for _, key := range keys {
+			ok, err := v.Exists(ctx, key)
+			require.NoError(t, err)
+			if ok {
+				n++
+			}
+		}
+		return n
+	}
+
+	clock.Advance(29 * time.Minute)
+	assert.Equal(t, len(keys), live(), "jitter never shortens the TTL by more than the fraction")
+
+	clock.Advance(16 * time.Minute)
+	n := live()
+	assert.Positive(t, n, "some keys outlive the jittered window")
+	assert.Less(t, n, len(keys), "keys created together expire at different times")
+	assert.Equal(t, n, live(), "jitter is stable across reads")
+
+	clock.Advance(16 * time.Minute)
+	assert.Zero(t, live(), "jitter never extends the TTL")
+}
+
+func TestTTLJitter_refreshesEarlyExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	keys := make([]string, 32)
+	for i := range keys {
+		keys[i] = fmt.Sprint("key-", i)
+	}
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		entries := make([]vault.Entry, len(keys))
+		for i, key := range keys {
+			entries[i] = vault.Entry{Key: key, Value: "v"}
+		}
+		return entries, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithTTL(time.Hour), vault.WithTTLJitter(0.5), vault.WithClock(clock))
+	require.NoError(t, v.Refresh(ctx))
+
+	for range 12 {
+		clock.Advance(5 * time.Minute)
+		for _, key := range keys {
+			_, err := v.Get(ctx, key)
+			require.NoError(t, err, "%s is still supplied by the source", key)
+		}
+	}
+}
+
+func TestGet_entryExpiry_overridesTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		n := calls.Add(1)
+		return []vault.Entry{{
+			Key:       "token",
+			Value:     fmt.Sprintf("v%d", n),
+			ExpiresAt: time.Now().Add(20 * time.Millisecond),
+		}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithTTL(time.Hour))
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v1", got.Value)
+
+	time.Sleep(30 * time.Millisecond)
+
+	got, err = v.Get(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2", got.Value, "per-entry expiry must trigger a refresh within the global TTL")
+}
+
+func TestGet_entryExpiry_outlivesTTL(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "static",
+		Value:     "kept",
+		CreatedAt: time.Now().Add(-time.Hour),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Minute))
+
+	got, err := v.Get(ctx, "static")
+	require.NoError(t, err)
+	assert.Equal(t, "kept", got.Value)
+}
+
+func TestSet_preservesExpiresAt(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	expires := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v", ExpiresAt: expires}))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, expires.Equal(got.ExpiresAt))
+}
+
 func TestGet_storeError_propagated(t *testing.T) {
 	t.Parallel()
 
@@ -184,6 +498,32 @@ func TestList(t *testing.T) {
 	assert.Len(t, entries, 2)
 }
 
+func TestListStream(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for name, store := range map[string]vault.Store{
+		"streamer": vault.NewMemory(),
+		"fallback": listOnlyStore{vault.NewMemory()},
+	} {
+		v := vault.New(vault.WithStore(store))
+		for _, key := range []string{"a", "b", "c"} {
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: key, Value: key}), name)
+		}
+
+		seq, err := v.ListStream(ctx)
+		require.NoError(t, err, name)
+		var seen int
+		for _, err := range seq {
+			require.NoError(t, err, name)
+			if seen++; seen == 2 {
+				break
+			}
+		}
+		assert.Equal(t, 2, seen, name)
+	}
+}
+
 func TestRefresh(t *testing.T) {
 	t.Parallel()
 
@@ -257,16 +597,24 @@ func TestAutoRefresh_retriggersAfterTTL(t *testing.T) {
 		}, nil
 	})
 
+	clock := clocktest.New(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
 	v := vault.New(
 		vault.WithSource(src),
-		vault.WithTTL(time.Millisecond),
+		vault.WithTTL(time.Hour),
+		vault.WithClock(clock),
 	)
 
 	_, err := v.Get(ctx, "k")
 	require.NoError(t, err)
 	assert.Equal(t, 1, calls)
 
-	time.Sleep(5 * time.Millisecond)
+	clock.Advance(30 * time.Minute)
+
+	_, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "should not refresh within the TTL")
+
+	clock.Advance(31 * time.Minute)
 
 	_, err = v.Get(ctx, "k")
 	require.NoError(t, err)
@@ -321,3 +669,1574 @@ func (f *failStore) Set(_ context.Context, _ vault.Entry) error { return nil }
 func (f *failStore) Delete(_ context.Context, _ string) error { return nil }
 
 func (f *failStore) List(_ context.Context) ([]vault.Entry, error) { return nil, nil }
+
+func TestFailFast_missReturnsLastRefreshError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errFetch := errors.New("upstream down")
+	calls := 0
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls++
+		return nil, errFetch
+	})
+
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithTTL(time.Hour),
+		vault.WithFailFastOnRefreshError(),
+	)
+
+	_, err := v.Get(ctx, "k")
+	require.ErrorIs(t, err, errFetch)
+	assert.Equal(t, 1, calls)
+
+	_, err = v.Get(ctx, "other")
+	require.ErrorIs(t, err, errFetch)
+	assert.Equal(t, 1, calls, "miss within backoff window should not retry the refresh")
+}
+
+func TestFailFast_servesStaleValue(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "k",
+		Value:     "stale",
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+	}))
+
+	errFetch := errors.New("upstream down")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	})
+
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithSource(src),
+		vault.WithTTL(time.Hour),
+		vault.WithFailFastOnRefreshError(),
+	)
+
+	require.ErrorIs(t, v.Refresh(ctx), errFetch)
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "stale", got.Value)
+}
+
+func TestServeStaleOnError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "k",
+		Value:     "stale",
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+	}))
+
+	errFetch := errors.New("upstream down")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	})
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithSource(src),
+		vault.WithTTL(time.Hour),
+		vault.WithServeStaleOnError(),
+	)
+
+	got, meta, err := v.GetWithMeta(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "stale", got.Value)
+	assert.True(t, meta.Stale)
+	assert.False(t, meta.Refreshed)
+
+	many, err := v.GetMany(ctx, []string{"k"})
+	require.NoError(t, err)
+	assert.Equal(t, "stale", many["k"].Value)
+
+	_, err = v.Get(ctx, "missing")
+	require.ErrorIs(t, err, errFetch, "with no stored value the refresh error is returned")
+}
+
+func TestServeStaleOnError_disabledReturnsError(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "k",
+		Value:     "stale",
+		CreatedAt: time.Now().Add(-2 * time.Hour),
+	}))
+
+	errFetch := errors.New("upstream down")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	})
+	v := vault.New(vault.WithStore(store), vault.WithSource(src), vault.WithTTL(time.Hour))
+
+	_, err := v.Get(ctx, "k")
+	require.ErrorIs(t, err, errFetch)
+}
+
+func TestFailFast_disabledRetriesRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	calls := 0
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls++
+		return nil, errors.New("upstream down")
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithTTL(time.Hour))
+
+	_, err := v.Get(ctx, "k")
+	require.Error(t, err)
+	_, err = v.Get(ctx, "k")
+	require.Error(t, err)
+	assert.Equal(t, 2, calls)
+}
+
+func TestReadOnly_setRejected(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "platform", ReadOnly: true}))
+
+	err := v.Set(ctx, vault.Entry{Key: "k", Value: "clobbered"})
+	require.ErrorIs(t, err, vault.ErrReadOnly)
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", got.Value)
+	assert.True(t, got.ReadOnly)
+}
+
+func TestReadOnly_refreshSkipped(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{
+			{Key: "k", Value: "from-source", Source: "src"},
+			{Key: "other", Value: "written", Source: "src"},
+		}, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithSkipReadOnly())
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "platform", ReadOnly: true}))
+
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", got.Value)
+
+	got, err = v.Get(ctx, "other")
+	require.NoError(t, err)
+	assert.Equal(t, "written", got.Value)
+}
+
+func TestReadOnly_refreshRejectedByDefault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "from-source"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "platform", ReadOnly: true}))
+
+	require.ErrorIs(t, v.Refresh(ctx), vault.ErrReadOnly)
+}
+
+func TestReadOnly_deleteRejected(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "platform", ReadOnly: true}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "other", Value: "v"}))
+
+	require.ErrorIs(t, v.Delete(ctx, "k"), vault.ErrReadOnly)
+	require.ErrorIs(t, v.DeleteMany(ctx, []string{"other", "k"}), vault.ErrReadOnly)
+	require.ErrorIs(t, v.Rename(ctx, "k", "moved"), vault.ErrReadOnly)
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", got.Value)
+	_, err = v.Get(ctx, "other")
+	require.NoError(t, err, "DeleteMany deletes nothing when it fails")
+	_, err = v.Get(ctx, "moved")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestReadOnly_deleteSkipped(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New(vault.WithSkipReadOnly())
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "platform", ReadOnly: true}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "other", Value: "v"}))
+
+	require.NoError(t, v.Delete(ctx, "k"))
+	require.NoError(t, v.Rename(ctx, "k", "moved"))
+	require.NoError(t, v.DeleteMany(ctx, []string{"other", "k"}))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "platform", got.Value)
+	_, err = v.Get(ctx, "moved")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	_, err = v.Get(ctx, "other")
+	require.ErrorIs(t, err, vault.ErrNotFound, "writable entries are still deleted")
+}
+
+func TestMaxValueSize_set(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New(vault.WithMaxValueSize(4))
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "fits", Value: "1234"}))
+
+	err := v.Set(ctx, vault.Entry{Key: "big", Value: "12345"})
+	require.ErrorIs(t, err, vault.ErrValueTooLarge)
+	assert.Contains(t, err.Error(), `"big"`)
+	assert.Contains(t, err.Error(), "5 bytes")
+	assert.Contains(t, err.Error(), "4 byte limit")
+
+	_, err = v.Get(ctx, "big")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestMaxValueSize_refresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "small", Value: "ok"}, {Key: "big", Value: "too large"}}, nil
+	})
+
+	strict := vault.New(vault.WithSource(src), vault.WithMaxValueSize(4))
+	require.ErrorIs(t, strict.Refresh(ctx), vault.ErrValueTooLarge)
+
+	lenient := vault.New(vault.WithSource(src), vault.WithMaxValueSize(4), vault.WithSkipOversized())
+	require.NoError(t, lenient.Refresh(ctx))
+
+	got, err := lenient.Get(ctx, "small")
+	require.NoError(t, err)
+	assert.Equal(t, "ok", got.Value)
+
+	keys, err := lenient.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"small"}, keys)
+}
+
+func TestRename(t *testing.T) {
+	t.Parallel()
+
+	newStores := map[string]func(t *testing.T) vault.Store{
+		"renamer": func(_ *testing.T) vault.Store { return vault.NewMemory() },
+		"fallback": func(t *testing.T) vault.Store {
+			s, err := vault.Encrypted(vault.NewMemory(), bytes.Repeat([]byte{1}, 32))
+			require.NoError(t, err)
+			return s
+		},
+	}
+
+	for name, newStore := range newStores {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			v := vault.New(vault.WithStore(newStore(t)))
+
+			created := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: "api-key", Value: "sk-1", Source: "ssm", CreatedAt: created}))
+
+			events, err := v.Watch(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, v.Rename(ctx, "api-key", "api-token"))
+
+			_, err = v.Get(ctx, "api-key")
+			require.ErrorIs(t, err, vault.ErrNotFound)
+			got, err := v.Get(ctx, "api-token")
+			require.NoError(t, err)
+			assert.Equal(t, "sk-1", got.Value)
+			assert.Equal(t, "ssm", got.Source)
+			assert.True(t, created.Equal(got.CreatedAt))
+
+			assert.Equal(t, vault.Event{Key: "api-key", Op: vault.OpDelete}, <-events)
+			assert.Equal(t, "api-token", (<-events).Key)
+		})
+	}
+}
+
+func TestRename_conflict(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "old", Value: "1"}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "new", Value: "2"}))
+
+	v := vault.New(vault.WithStore(store))
+	require.ErrorIs(t, v.Rename(ctx, "old", "new"), vault.ErrKeyExists)
+	require.ErrorIs(t, v.Rename(ctx, "missing", "other"), vault.ErrNotFound)
+
+	got, err := v.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "2", got.Value, "a refused rename changes nothing")
+
+	overwriting := vault.New(vault.WithStore(store), vault.WithOverwriteOnRename())
+	require.NoError(t, overwriting.Rename(ctx, "old", "new"))
+
+	got, err = v.Get(ctx, "new")
+	require.NoError(t, err)
+	assert.Equal(t, "1", got.Value)
+	_, err = v.Get(ctx, "old")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestFetchSemaphore_boundsConcurrentFetches(t *testing.T) {
+	t.Parallel()
+
+	const limit = 2
+
+	var (
+		mu      sync.Mutex
+		active  int
+		maxSeen int
+	)
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		mu.Lock()
+		active++
+		maxSeen = max(maxSeen, active)
+		mu.Unlock()
+
+		time.Sleep(5 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithSource(src), vault.WithFetchSemaphore(limit))
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, v.Refresh(context.Background()))
+		}()
+	}
+	wg.Wait()
+
+	assert.LessOrEqual(t, maxSeen, limit)
+}
+
+func TestFetchSemaphore_honorsCancellation(t *testing.T) {
+	t.Parallel()
+
+	release := make(chan struct{})
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		<-release
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithFetchSemaphore(1))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		assert.NoError(t, v.Refresh(context.Background()))
+	}()
+	time.Sleep(5 * time.Millisecond) // let the first refresh take the slot
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.ErrorIs(t, v.Refresh(ctx), context.Canceled)
+
+	close(release)
+	<-done
+}
+
+func TestSourceSelector_routesKeysBySource(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	both := func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{
+			{Key: "aws.db", Value: "v"},
+			{Key: "env.port", Value: "v"},
+		}, nil
+	}
+
+	aws := vault.NamedSource("aws", vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		entries, err := both(ctx)
+		for i := range entries {
+			entries[i].Source = "aws"
+		}
+		return entries, err
+	}))
+	env := vault.NamedSource("env", vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		entries, err := both(ctx)
+		for i := range entries {
+			entries[i].Source = "env"
+		}
+		return entries, err
+	}))
+
+	v := vault.New(
+		vault.WithSource(aws),
+		vault.WithSource(env),
+		vault.WithSourceSelector(func(sourceName, key string) bool {
+			return strings.HasPrefix(key, sourceName+".")
+		}),
+	)
+
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "aws.db")
+	require.NoError(t, err)
+	assert.Equal(t, "aws", got.Source)
+
+	got, err = v.Get(ctx, "env.port")
+	require.NoError(t, err)
+	assert.Equal(t, "env", got.Source)
+}
+
+func TestBase64Values_roundTrip(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	v := vault.New(vault.WithStore(store), vault.WithBase64Values())
+
+	value := "line one\nline two\x00\xff trailing"
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "bin", Value: value}))
+
+	raw, err := store.Get(ctx, "bin")
+	require.NoError(t, err)
+	assert.NotContains(t, raw.Value, "\n", "store should only see the encoded form")
+
+	got, err := v.Get(ctx, "bin")
+	require.NoError(t, err)
+	assert.Equal(t, value, got.Value)
+
+	entries, err := v.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, value, entries[0].Value)
+}
+
+func TestBase64Values_noDoubleEncoding(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New(vault.WithBase64Values())
+
+	// A value that already looks encoded must still round-trip exactly.
+	value := "b64:aGVsbG8="
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: value}))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, value, got.Value)
+
+	// Re-storing a value read through the vault does not stack encodings.
+	require.NoError(t, v.Set(ctx, got))
+	got, err = v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, value, got.Value)
+}
+
+func TestBase64Values_readsLegacyPlaintext(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "k", Value: "plain", CreatedAt: time.Now()}))
+
+	v := vault.New(vault.WithStore(store), vault.WithBase64Values())
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "plain", got.Value)
+}
+
+func TestSourceTTL_fasterSourceExpiresSooner(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var fastCalls, slowCalls int
+	fast := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		fastCalls++
+		return []vault.Entry{{Key: "fast-key", Value: "v", Source: "env"}}, nil
+	})
+	slow := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		slowCalls++
+		return []vault.Entry{{Key: "slow-key", Value: "v", Source: "ssm"}}, nil
+	})
+
+	v := vault.New(
+		vault.WithSource(fast),
+		vault.WithSource(slow),
+		vault.WithTTL(time.Hour),
+		vault.WithSourceTTL("env", time.Millisecond),
+	)
+
+	require.NoError(t, v.Refresh(ctx))
+	time.Sleep(5 * time.Millisecond)
+
+	_, err := v.Get(ctx, "slow-key")
+	require.NoError(t, err)
+	assert.Equal(t, 1, slowCalls, "slow entry is still within the global TTL")
+
+	_, err = v.Get(ctx, "fast-key")
+	require.NoError(t, err)
+	assert.Equal(t, 2, fastCalls, "fast entry should expire and trigger a refresh")
+}
+
+func TestRestampSeededEntries_keepsManualDataAccessible(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	old := time.Now().Add(-30 * 24 * time.Hour)
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "seeded", Value: "s", CreatedAt: old}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "manual", Value: "m", CreatedAt: old, Source: "manual"}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "fetched", Value: "f", CreatedAt: old, Source: "ssm"}))
+
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithTTL(time.Hour),
+		vault.WithRestampSeededEntries(),
+	)
+
+	got, err := v.Get(ctx, "seeded")
+	require.NoError(t, err)
+	assert.Equal(t, "s", got.Value)
+
+	got, err = v.Get(ctx, "manual")
+	require.NoError(t, err)
+	assert.Equal(t, "m", got.Value)
+
+	_, err = v.Get(ctx, "fetched")
+	require.ErrorIs(t, err, vault.ErrNotFound, "source-produced entries still expire")
+}
+
+func TestRestampSeededEntries_disabledHidesOldData(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "seeded", CreatedAt: time.Now().Add(-2 * time.Hour)}))
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Hour))
+
+	_, err := v.Get(ctx, "seeded")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestSourceNamespace_writesToMountedNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	shared := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "org-token", Value: "t", Source: "shared"}}, nil
+	})
+	scoped := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "db", Value: "prod-db", Source: "ssm"}}, nil
+	})
+
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithNamespace("prod"),
+		vault.WithSource(scoped),
+		vault.WithSourceNamespace(shared, "shared"),
+	)
+
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := store.WithNamespace("shared").Get(ctx, "org-token")
+	require.NoError(t, err)
+	assert.Equal(t, "t", got.Value)
+
+	_, err = store.WithNamespace("prod").Get(ctx, "org-token")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	got, err = store.WithNamespace("prod").Get(ctx, "db")
+	require.NoError(t, err)
+	assert.Equal(t, "prod-db", got.Value)
+}
+
+func TestSourceNamespace_requiresNamespacedStore(t *testing.T) {
+	t.Parallel()
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) { return nil, nil })
+	v := vault.New(
+		vault.WithStore(&failStore{}),
+		vault.WithSourceNamespace(src, "shared"),
+	)
+
+	require.ErrorIs(t, v.Refresh(context.Background()), vault.ErrNotNamespaced)
+}
+
+func TestGetMany_singleRefreshForAllMisses(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	calls := 0
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls++
+		return []vault.Entry{
+			{Key: "a", Value: "1", Source: "src"},
+			{Key: "b", Value: "2", Source: "src"},
+			{Key: "c", Value: "3", Source: "src"},
+		}, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "cached", Value: "0"}))
+
+	got, err := v.GetMany(ctx, []string{"cached", "a", "b", "c", "nope"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, calls, "all misses should share one refresh")
+	assert.Len(t, got, 4)
+	assert.Equal(t, "0", got["cached"].Value)
+	assert.Equal(t, "2", got["b"].Value)
+	assert.NotContains(t, got, "nope")
+}
+
+func TestGetMany_allHitsSkipRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	calls := 0
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls++
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+	got, err := v.GetMany(ctx, []string{"a", "b"})
+	require.NoError(t, err)
+	assert.Len(t, got, 2)
+	assert.Zero(t, calls)
+}
+
+func TestGetMany_sequentialFallback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errBroken := errors.New("store is broken")
+	v := vault.New(vault.WithStore(&failStore{err: errBroken}))
+
+	_, err := v.GetMany(ctx, []string{"a"})
+	require.ErrorIs(t, err, errBroken)
+}
+
+func TestRefresh_fetchesSourcesConcurrently(t *testing.T) {
+	t.Parallel()
+
+	aStarted, bStarted := make(chan struct{}), make(chan struct{})
+	blocking := func(started, other chan struct{}, key string) vault.Source {
+		return vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+			close(started)
+			select {
+			case <-other: // only reachable if both fetches run at once
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			return []vault.Entry{{Key: key, Value: key}}, nil
+		})
+	}
+
+	v := vault.New(
+		vault.WithSource(blocking(aStarted, bStarted, "a")),
+		vault.WithSource(blocking(bStarted, aStarted, "b")),
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	require.NoError(t, v.Refresh(ctx))
+
+	entries, err := v.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestRefresh_errorCancelsOtherSources(t *testing.T) {
+	t.Parallel()
+
+	errFetch := errors.New("boom")
+	cancelled := make(chan struct{})
+	slow := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		<-ctx.Done()
+		close(cancelled)
+		return nil, ctx.Err()
+	})
+	failing := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return nil, errFetch
+	})
+
+	v := vault.New(vault.WithSource(slow), vault.WithSource(failing))
+
+	require.ErrorIs(t, v.Refresh(context.Background()), errFetch)
+	<-cancelled
+}
+
+func TestRefresh_laterSourceWins(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	first := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		time.Sleep(5 * time.Millisecond)
+		return []vault.Entry{{Key: "k", Value: "first"}}, nil
+	})
+	second := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "second"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(first), vault.WithSource(second))
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "second", got.Value)
+}
+
+func TestRefresh_sourcePriority(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	first := vault.NamedSource("vault", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "first", Source: "vault"}}, nil
+	}))
+	second := vault.NamedSource("ssm", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "second", Source: "ssm"}, {Key: "other", Value: "o"}}, nil
+	}))
+
+	v := vault.New(vault.WithSource(first), vault.WithSource(second), vault.WithSourcePriority())
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "first", got.Value)
+	assert.Equal(t, "vault", got.Source)
+
+	_, err = v.Get(ctx, "other")
+	require.NoError(t, err)
+}
+
+func TestRefresh_mergeFunc(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := func(name, value string) vault.Source {
+		return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+			return []vault.Entry{{Key: "hosts", Value: value, Source: name}}, nil
+		})
+	}
+
+	var calls int
+	merge := func(existing, incoming vault.Entry) vault.Entry {
+		calls++
+		existing.Value += "," + incoming.Value
+		return existing
+	}
+
+	v := vault.New(
+		vault.WithSource(src("a", "h1")),
+		vault.WithSource(src("b", "h2")),
+		vault.WithSource(src("c", "h3")),
+		vault.WithMergeFunc(merge),
+	)
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := v.Get(ctx, "hosts")
+	require.NoError(t, err)
+	assert.Equal(t, "h1,h2,h3", got.Value)
+	assert.Equal(t, "a", got.Source)
+	assert.Equal(t, 2, calls)
+}
+
+func TestRefresh_mergeFuncPerNamespace(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	shared := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "shared"}}, nil
+	})
+	own := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "own"}}, nil
+	})
+
+	v := vault.New(
+		vault.WithStore(store),
+		vault.WithSourceNamespace(shared, "shared"),
+		vault.WithSource(own),
+		vault.WithSourcePriority(),
+	)
+	require.NoError(t, v.Refresh(ctx))
+
+	got, err := store.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "own", got.Value, "keys in different stores do not conflict")
+
+	got, err = store.WithNamespace("shared").Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "shared", got.Value)
+}
+
+// batchStore is a [vault.BatchSetter] that records how it was written to
+// and fails SetMany with err when set.
+type batchStore struct {
+	*vault.Memory
+	err      error
+	sets     atomic.Int32
+	setManys atomic.Int32
+}
+
+func (b *batchStore) Set(ctx context.Context, e vault.Entry) error {
+	b.sets.Add(1)
+	return b.Memory.Set(ctx, e)
+}
+
+func (b *batchStore) SetMany(ctx context.Context, entries []vault.Entry) error {
+	b.setManys.Add(1)
+	if b.err != nil {
+		return b.err
+	}
+	return b.Memory.SetMany(ctx, entries)
+}
+
+func TestRefresh_usesBatchSetter(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "a", Value: "1"}, {Key: "b", Value: "2"}, {Key: "c", Value: "3"}}, nil
+	})
+	store := &batchStore{Memory: vault.NewMemory()}
+
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+	require.NoError(t, v.Refresh(ctx))
+
+	assert.Equal(t, int32(1), store.setManys.Load())
+	assert.Zero(t, store.sets.Load())
+
+	keys, err := v.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, keys)
+}
+
+func TestRefresh_failedBatchLeavesStoreUnchanged(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errBatch := errors.New("batch rejected")
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "a", Value: "new"}, {Key: "b", Value: "2"}}, nil
+	})
+	store := &batchStore{Memory: vault.NewMemory(), err: errBatch}
+	require.NoError(t, store.Memory.Set(ctx, vault.Entry{Key: "a", Value: "old"}))
+
+	v := vault.New(vault.WithStore(store), vault.WithSource(src))
+	require.ErrorIs(t, v.Refresh(ctx), errBatch)
+
+	entries, err := store.Memory.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "old", entries[0].Value)
+	assert.Zero(t, store.sets.Load(), "a failed batch must not fall back to per-entry writes")
+}
+
+func TestMaxConcurrency_limitsParallelFetches(t *testing.T) {
+	t.Parallel()
+
+	var (
+		mu      sync.Mutex
+		active  int
+		maxSeen int
+	)
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		mu.Lock()
+		active++
+		maxSeen = max(maxSeen, active)
+		mu.Unlock()
+
+		time.Sleep(2 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+		return nil, nil
+	})
+
+	v := vault.New(
+		vault.WithSource(src),
+		vault.WithSource(src),
+		vault.WithSource(src),
+		vault.WithMaxConcurrency(1),
+	)
+
+	require.NoError(t, v.Refresh(context.Background()))
+	assert.Equal(t, 1, maxSeen)
+}
+
+func TestAutoRefresh_concurrentMissesShareOneFetch(t *testing.T) {
+	t.Parallel()
+
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		time.Sleep(10 * time.Millisecond)
+		return []vault.Entry{{Key: "present", Value: "v"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := v.Get(context.Background(), "missing")
+			assert.ErrorIs(t, err, vault.ErrNotFound)
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestAutoRefresh_outlivesFirstCaller(t *testing.T) {
+	t.Parallel()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		if calls.Add(1) == 1 {
+			close(started)
+		}
+		<-release
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error, 1)
+	go func() {
+		_, err := v.Get(ctx, "k")
+		first <- err
+	}()
+	<-started
+
+	second := make(chan error, 1)
+	go func() {
+		_, err := v.Get(context.Background(), "k")
+		second <- err
+	}()
+
+	cancel()
+	require.ErrorIs(t, <-first, context.Canceled, "a caller stops waiting when its context ends")
+	close(release)
+	require.NoError(t, <-second, "the shared refresh is not cancelled with the first caller")
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestExists(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "fresh", CreatedAt: time.Now()}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "expired", CreatedAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, store.WithNamespace("other").Set(ctx, vault.Entry{Key: "scoped", CreatedAt: time.Now()}))
+
+	calls := 0
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls++
+		return nil, nil
+	})
+
+	v := vault.New(vault.WithStore(store), vault.WithSource(src), vault.WithTTL(time.Minute))
+
+	tests := map[string]bool{
+		"fresh":   true,
+		"expired": false,
+		"missing": false,
+		"scoped":  false,
+	}
+	for key, want := range tests {
+		got, err := v.Exists(ctx, key)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, key)
+	}
+	assert.Zero(t, calls, "Exists must not trigger a refresh")
+}
+
+func TestExists_namespaced(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	prod := vault.New(vault.WithStore(store), vault.WithNamespace("prod"))
+	qa := vault.New(vault.WithStore(store), vault.WithNamespace("qa"))
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "db"}))
+
+	ok, err := prod.Exists(ctx, "db")
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = qa.Exists(ctx, "db")
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestExists_entryExpiry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "gone", CreatedAt: time.Now(), ExpiresAt: time.Now().Add(-time.Second)}))
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Hour))
+
+	ok, err := v.Exists(ctx, "gone")
+	require.NoError(t, err)
+	assert.False(t, ok, "with a TTL configured, the entry is read to check its expiry")
+}
+
+// existsCounter counts the reads of a store, to check which of them
+// [vault.Vault.Exists] makes.
+type existsCounter struct {
+	*vault.Memory
+	gets, exists atomic.Int32
+}
+
+func (s *existsCounter) Get(ctx context.Context, key string) (vault.Entry, error) {
+	s.gets.Add(1)
+	return s.Memory.Get(ctx, key)
+}
+
+func (s *existsCounter) Exists(ctx context.Context, key string) (bool, error) {
+	s.exists.Add(1)
+	return s.Memory.Exists(ctx, key)
+}
+
+func TestExists_oneRead(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &existsCounter{Memory: vault.NewMemory()}
+	require.NoError(t, store.Memory.Set(ctx, vault.Entry{Key: "k", CreatedAt: time.Now()}))
+
+	ok, err := vault.New(vault.WithStore(store)).Exists(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), store.exists.Load())
+	assert.Zero(t, store.gets.Load(), "an Exister is trusted without reading the value")
+
+	ok, err = vault.New(vault.WithStore(store), vault.WithTTL(time.Hour)).Exists(ctx, "k")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), store.exists.Load(), "with a TTL the entry is read instead")
+	assert.Equal(t, int32(1), store.gets.Load())
+}
+
+func TestKeys_sortedAndScoped(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	prod := vault.New(vault.WithStore(store), vault.WithNamespace("prod"))
+	qa := vault.New(vault.WithStore(store), vault.WithNamespace("qa"))
+
+	for _, k := range []string{"zeta", "alpha", "mid"} {
+		require.NoError(t, prod.Set(ctx, vault.Entry{Key: k}))
+	}
+	require.NoError(t, qa.Set(ctx, vault.Entry{Key: "other"}))
+
+	keys, err := prod.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"alpha", "mid", "zeta"}, keys)
+
+	keys, err = qa.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"other"}, keys)
+}
+
+func TestKeys_fallsBackToList(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New(vault.WithStore(&failStore{}))
+
+	keys, err := v.Keys(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	v = vault.New(vault.WithStore(vault.NewMemory()), vault.WithBase64Values())
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "b"}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "a"}))
+
+	keys, err = v.Keys(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, keys)
+}
+
+func TestSnapshot(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	for name, store := range map[string]vault.Store{
+		"snapshotter": vault.NewMemory(),
+		"list":        &listOnlyStore{Store: vault.NewMemory()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := vault.New(vault.WithStore(store), vault.WithBase64Values())
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: "a", Value: "1"}))
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: "b", Value: "2"}))
+
+			snap, err := v.Snapshot(ctx)
+			require.NoError(t, err)
+			require.NoError(t, v.Set(ctx, vault.Entry{Key: "a", Value: "changed"}))
+
+			require.Len(t, snap, 2)
+			assert.Equal(t, "1", snap["a"].Value)
+			assert.Equal(t, "2", snap["b"].Value)
+		})
+	}
+}
+
+// listOnlyStore hides the optional interfaces of the wrapped store.
+type listOnlyStore struct {
+	vault.Store
+}
+
+// plainNamespaced is a namespaced Memory whose namespaces hide the
+// optional interfaces.
+type plainNamespaced struct {
+	*vault.Memory
+}
+
+func (p plainNamespaced) WithNamespace(ns string) vault.Store {
+	return listOnlyStore{p.Memory.WithNamespace(ns)}
+}
+
+func TestListPrefix(t *testing.T) {
+	t.Parallel()
+
+	stores := map[string]func() vault.Store{
+		"prefix lister": func() vault.Store { return vault.NewMemory() },
+		"fallback":      func() vault.Store { return plainNamespaced{vault.NewMemory()} },
+	}
+
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			store := newStore()
+			prod := vault.New(vault.WithStore(store), vault.WithNamespace("prod"))
+			qa := vault.New(vault.WithStore(store), vault.WithNamespace("qa"))
+
+			for _, key := range []string{"db.host", "db.port", "dbx", "api.key"} {
+				require.NoError(t, prod.Set(ctx, vault.Entry{Key: key, Value: "prod"}))
+			}
+			require.NoError(t, qa.Set(ctx, vault.Entry{Key: "db.host", Value: "qa"}))
+
+			keys, err := prod.KeysPrefix(ctx, "db.")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"db.host", "db.port"}, keys)
+
+			entries, err := prod.ListPrefix(ctx, "db.")
+			require.NoError(t, err)
+			require.Len(t, entries, 2)
+			for _, e := range entries {
+				assert.Equal(t, "prod", e.Value)
+			}
+
+			keys, err = prod.KeysPrefix(ctx, "")
+			require.NoError(t, err)
+			assert.Equal(t, []string{"api.key", "db.host", "db.port", "dbx"}, keys)
+
+			keys, err = prod.KeysPrefix(ctx, "prod/")
+			require.NoError(t, err)
+			assert.Empty(t, keys, "the prefix is relative to the namespace")
+		})
+	}
+}
+
+func TestListFresh_filtersExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := vault.NewMemory()
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "fresh", Value: "v", CreatedAt: time.Now()}))
+	require.NoError(t, store.Set(ctx, vault.Entry{Key: "stale", Value: "v", CreatedAt: time.Now().Add(-time.Hour)}))
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "pinned",
+		Value:     "v",
+		CreatedAt: time.Now().Add(-time.Hour),
+		ExpiresAt: time.Now().Add(time.Hour),
+	}))
+	require.NoError(t, store.Set(ctx, vault.Entry{
+		Key:       "lapsed",
+		Value:     "v",
+		CreatedAt: time.Now(),
+		ExpiresAt: time.Now().Add(-time.Second),
+	}))
+
+	v := vault.New(vault.WithStore(store), vault.WithTTL(time.Minute))
+
+	fresh, err := v.ListFresh(ctx)
+	require.NoError(t, err)
+	keys := make([]string, len(fresh))
+	for i, e := range fresh {
+		keys[i] = e.Key
+	}
+	assert.ElementsMatch(t, []string{"fresh", "pinned"}, keys)
+
+	all, err := v.List(ctx)
+	require.NoError(t, err)
+	assert.Len(t, all, 4, "List still returns expired entries")
+}
+
+func TestListFresh_refreshesExpired(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		n := calls.Add(1)
+		return []vault.Entry{{Key: "k", Value: fmt.Sprintf("v%d", n)}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithTTL(10*time.Millisecond))
+	require.NoError(t, v.Refresh(ctx))
+
+	fresh, err := v.ListFresh(ctx)
+	require.NoError(t, err)
+	require.Len(t, fresh, 1)
+	assert.Equal(t, "v1", fresh[0].Value)
+
+	time.Sleep(20 * time.Millisecond)
+
+	fresh, err = v.ListFresh(ctx)
+	require.NoError(t, err)
+	require.Len(t, fresh, 1)
+	assert.Equal(t, "v2", fresh[0].Value)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestListByTag(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	seed := []vault.Entry{
+		{Key: "db", Tags: map[string]string{"env": "prod", "team": "payments"}},
+		{Key: "cache", Tags: map[string]string{"env": "prod"}},
+		{Key: "staging-db", Tags: map[string]string{"env": "staging", "team": "payments"}},
+		{Key: "untagged"},
+	}
+
+	stores := map[string]func() vault.Store{
+		"memory":    func() vault.Store { return vault.NewMemory() },
+		"list only": func() vault.Store { return listOnlyStore{vault.NewMemory()} },
+	}
+	for name, newStore := range stores {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := vault.New(vault.WithStore(newStore()), vault.WithBase64Values())
+			for _, e := range seed {
+				e.Value = "value of " + e.Key
+				require.NoError(t, v.Set(ctx, e))
+			}
+
+			keysOf := func(entries []vault.Entry) []string {
+				keys := make([]string, len(entries))
+				for i, e := range entries {
+					keys[i] = e.Key
+				}
+				return keys
+			}
+
+			prod, err := v.ListByTag(ctx, "env", "prod")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"db", "cache"}, keysOf(prod))
+			for _, e := range prod {
+				assert.Equal(t, "value of "+e.Key, e.Value)
+			}
+
+			payments, err := v.ListByTag(ctx, "team", "payments")
+			require.NoError(t, err)
+			assert.ElementsMatch(t, []string{"db", "staging-db"}, keysOf(payments))
+
+			none, err := v.ListByTag(ctx, "env", "dev")
+			require.NoError(t, err)
+			assert.Empty(t, none)
+		})
+	}
+}
+
+func TestDeleteMany(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	for name, store := range map[string]vault.Store{
+		"batch":    vault.NewMemory(),
+		"fallback": listOnlyStore{vault.NewMemory()},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			v := vault.New(vault.WithStore(store))
+			for _, key := range []string{"a", "b", "c"} {
+				require.NoError(t, v.Set(ctx, vault.Entry{Key: key}))
+			}
+
+			events, err := v.Watch(ctx)
+			require.NoError(t, err)
+
+			require.NoError(t, v.DeleteMany(ctx, []string{"a", "c", "missing"}))
+
+			keys, err := v.Keys(ctx)
+			require.NoError(t, err)
+			assert.Equal(t, []string{"b"}, keys)
+
+			for _, want := range []string{"a", "c", "missing"} {
+				ev := <-events
+				assert.Equal(t, vault.OpDelete, ev.Op)
+				assert.Equal(t, want, ev.Key)
+			}
+		})
+	}
+}
+
+// flakySource fails its first failures fetches with err, then succeeds.
+func flakySource(failures int32, err error) (vault.Source, *atomic.Int32) {
+	var calls atomic.Int32
+	return vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		if calls.Add(1) <= failures {
+			return nil, err
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	}), &calls
+}
+
+func TestRetry(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errTransient := errors.New("connection reset")
+
+	t.Run("succeeds after failures", func(t *testing.T) {
+		t.Parallel()
+
+		src, calls := flakySource(2, errTransient)
+		v := vault.New(vault.WithSource(src), vault.WithRetry(3, time.Millisecond))
+
+		require.NoError(t, v.Refresh(ctx))
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("gives up after attempts", func(t *testing.T) {
+		t.Parallel()
+
+		src, calls := flakySource(2, errTransient)
+		v := vault.New(vault.WithSource(src), vault.WithRetry(2, time.Millisecond))
+
+		require.ErrorIs(t, v.Refresh(ctx), errTransient)
+		assert.Equal(t, int32(2), calls.Load())
+	})
+
+	t.Run("predicate", func(t *testing.T) {
+		t.Parallel()
+
+		src, calls := flakySource(2, errTransient)
+		v := vault.New(
+			vault.WithSource(src),
+			vault.WithRetry(5, time.Millisecond),
+			vault.WithRetryIf(func(err error) bool { return !errors.Is(err, errTransient) }),
+		)
+
+		require.ErrorIs(t, v.Refresh(ctx), errTransient)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+
+	t.Run("negative backoff", func(t *testing.T) {
+		t.Parallel()
+
+		src, calls := flakySource(2, errTransient)
+		v := vault.New(vault.WithSource(src), vault.WithRetry(3, -time.Second))
+
+		require.NoError(t, v.Refresh(ctx), "a negative backoff retries without waiting")
+		assert.Equal(t, int32(3), calls.Load())
+	})
+
+	t.Run("cancelled while waiting", func(t *testing.T) {
+		t.Parallel()
+
+		src, calls := flakySource(2, errTransient)
+		v := vault.New(vault.WithSource(src), vault.WithRetry(3, time.Hour))
+
+		cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		require.ErrorIs(t, v.Refresh(cctx), errTransient)
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Equal(t, int32(1), calls.Load())
+	})
+}
+
+func TestSourceTimeout_abandonedFetchHoldsSemaphore(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	block := make(chan struct{})
+	var calls atomic.Int32
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		calls.Add(1)
+		<-block // ignores its context entirely
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(src), vault.WithSourceTimeout(10*time.Millisecond), vault.WithFetchSemaphore(1))
+	require.ErrorIs(t, v.Refresh(ctx), context.DeadlineExceeded)
+
+	wctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, v.Refresh(wctx), context.DeadlineExceeded)
+	assert.Equal(t, int32(1), calls.Load(), "the abandoned fetch still holds the only slot")
+
+	close(block)
+	require.Eventually(t, func() bool { return v.Refresh(ctx) == nil }, time.Second, 10*time.Millisecond)
+}
+
+func TestSourceTimeout(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	block := make(chan struct{})
+	t.Cleanup(func() { close(block) })
+
+	hung := vault.NamedSource("hung", vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		<-block // ignores its context entirely
+		return nil, nil
+	}))
+	fast := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(vault.WithSource(fast), vault.WithSource(hung), vault.WithSourceTimeout(20*time.Millisecond))
+
+	start := time.Now()
+	err := v.Refresh(ctx)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Contains(t, err.Error(), "hung")
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestSourceTimeout_retried(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	var calls atomic.Int32
+	slowOnce := vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		if calls.Add(1) == 1 {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})
+
+	v := vault.New(
+		vault.WithSource(slowOnce),
+		vault.WithSourceTimeout(10*time.Millisecond),
+		vault.WithRetry(2, time.Millisecond),
+	)
+
+	require.NoError(t, v.Refresh(ctx))
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+// gatedStore blocks the first Get until release is closed, reporting on
+// entered once it is waiting.
+type gatedStore struct {
+	vault.Store
+	calls   atomic.Int32
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *gatedStore) Get(ctx context.Context, key string) (vault.Entry, error) {
+	if s.calls.Add(1) == 1 {
+		close(s.entered)
+		<-s.release
+	}
+	return s.Store.Get(ctx, key)
+}
+
+func TestSet_serializedWithRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	store := &gatedStore{Store: vault.NewMemory(), entered: make(chan struct{}), release: make(chan struct{})}
+	v := vault.New(vault.WithStore(store), vault.WithSource(vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "k", Value: "refreshed"}}, nil
+	})))
+
+	refreshed := make(chan error, 1)
+	go func() { refreshed <- v.Refresh(ctx) }()
+	<-store.entered // the refresh is between its readonly check and its write
+
+	set := make(chan error, 1)
+	go func() { set <- v.Set(ctx, vault.Entry{Key: "k", Value: "pinned", ReadOnly: true}) }()
+
+	select {
+	case <-set:
+		t.Fatal("Set completed while a refresh of the same key was in progress")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(store.release)
+
+	require.NoError(t, <-refreshed)
+	require.NoError(t, <-set)
+
+	e, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "pinned", e.Value)
+	assert.True(t, e.ReadOnly)
+}
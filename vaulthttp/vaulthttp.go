@@ -0,0 +1,154 @@
+// Package vaulthttp exposes a [vault.Vault] over HTTP as a small JSON API:
+//
+//	GET    /entries/{key...}   the entry, as a JSON [vault.Entry]
+//	PUT    /entries/{key...}   store the JSON [vault.Entry] in the body
+//	DELETE /entries/{key...}   remove the entry
+//	GET    /entries            every entry, sorted by key; ?redact=true hides values
+//	POST   /refresh            refresh from the vault's sources
+//
+// The key is the rest of the path, so keys containing slashes work. A
+// PUT body may leave its key empty; if it names a different key than the
+// path, the request is rejected with 400 Bad Request.
+//
+// [vault.ErrNotFound] is reported as 404 Not Found, [vault.ErrReadOnly] as
+// 409 Conflict and [vault.ErrBackendUnavailable] as 503 Service
+// Unavailable. Every call is bound to the request context, so it stops
+// when the client goes away.
+package vaulthttp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/bjaus/vault"
+)
+
+// maxBody bounds the size of a PUT request body.
+const maxBody = 1 << 20
+
+// Handler returns an [http.Handler] serving v. Mount it under a prefix
+// with [http.StripPrefix] to serve it alongside other routes.
+func Handler(v vault.Vault) http.Handler {
+	h := handler{v: v}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /entries/{key...}", h.get)
+	mux.HandleFunc("PUT /entries/{key...}", h.set)
+	mux.HandleFunc("DELETE /entries/{key...}", h.delete)
+	mux.HandleFunc("GET /entries", h.list)
+	mux.HandleFunc("POST /refresh", h.refresh)
+	return mux
+}
+
+type handler struct {
+	v vault.Vault
+}
+
+func (h handler) get(w http.ResponseWriter, r *http.Request) {
+	e, err := h.v.Get(r.Context(), r.PathValue("key"))
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	writeJSON(w, e)
+}
+
+func (h handler) set(w http.ResponseWriter, r *http.Request) {
+	var e vault.Entry
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxBody)).Decode(&e); err != nil {
+		http.Error(w, fmt.Sprintf("vaulthttp: decode entry: %v", err), http.StatusBadRequest)
+		return
+	}
+	key := r.PathValue("key")
+	if e.Key != "" && e.Key != key {
+		http.Error(w, fmt.Sprintf("vaulthttp: body key %q does not match path key %q", e.Key, key), http.StatusBadRequest)
+		return
+	}
+	e.Key = key
+	if err := h.v.Set(r.Context(), e); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) delete(w http.ResponseWriter, r *http.Request) {
+	if err := h.v.Delete(r.Context(), r.PathValue("key")); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h handler) list(w http.ResponseWriter, r *http.Request) {
+	redact, ok := redactParam(r)
+	if !ok {
+		http.Error(w, "vaulthttp: invalid redact parameter", http.StatusBadRequest)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := vault.ExportJSON(r.Context(), h.v, &buf, vault.WithRedactedValues(redact)); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = buf.WriteTo(w) //nolint:errcheck // the client has gone away
+}
+
+func (h handler) refresh(w http.ResponseWriter, r *http.Request) {
+	if err := h.v.Refresh(r.Context()); err != nil {
+		writeError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// redactParam reads the redact query parameter, reporting false if it is
+// not a boolean. A bare ?redact counts as true.
+func redactParam(r *http.Request) (bool, bool) {
+	q := r.URL.Query()
+	if !q.Has("redact") {
+		return false, true
+	}
+	s := q.Get("redact")
+	if s == "" {
+		return true, true
+	}
+	redact, err := strconv.ParseBool(s)
+	return redact, err == nil
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(data) //nolint:errcheck // the client has gone away
+}
+
+// writeError responds with the status that err maps to and its message.
+func writeError(w http.ResponseWriter, err error) {
+	http.Error(w, err.Error(), statusCode(err))
+}
+
+func statusCode(err error) int {
+	switch {
+	case errors.Is(err, vault.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, vault.ErrReadOnly):
+		return http.StatusConflict
+	case errors.Is(err, vault.ErrBackendUnavailable):
+		return http.StatusServiceUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,193 @@
+package vaulthttp_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+	"github.com/bjaus/vault/vaulthttp"
+)
+
+func do(t *testing.T, h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return rec
+}
+
+func TestHandler_get(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New()
+	require.NoError(t, v.Set(context.Background(), vault.Entry{Key: "db-host", Value: "db.internal"}))
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodGet, "/entries/db-host", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+
+	var e vault.Entry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e))
+	assert.Equal(t, "db-host", e.Key)
+	assert.Equal(t, "db.internal", e.Value)
+
+	rec = do(t, h, http.MethodGet, "/entries/missing", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_put(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodPut, "/entries/api-key", `{"key": "api-key", "value": "sk-1"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	e, err := v.Get(ctx, "api-key")
+	require.NoError(t, err)
+	assert.Equal(t, "sk-1", e.Value)
+
+	rec = do(t, h, http.MethodPut, "/entries/api-key", `{"key": "other", "value": "sk-2"}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	_, err = v.Get(ctx, "other")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	rec = do(t, h, http.MethodPut, "/entries/api-key", `not json`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	ro := vaulthttp.Handler(vault.New(vault.WithReadOnly()))
+	rec = do(t, ro, http.MethodPut, "/entries/api-key", `{"value": "sk-1"}`)
+	assert.Equal(t, http.StatusConflict, rec.Code)
+}
+
+func TestHandler_slashInKey(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodPut, "/entries/db/primary/password", `{"value": "hunter2"}`)
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	e, err := v.Get(ctx, "db/primary/password")
+	require.NoError(t, err)
+	assert.Equal(t, "hunter2", e.Value)
+
+	rec = do(t, h, http.MethodGet, "/entries/db/primary/password", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var got vault.Entry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &got))
+	assert.Equal(t, "db/primary/password", got.Key)
+
+	rec = do(t, h, http.MethodDelete, "/entries/db/primary/password", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+	_, err = v.Get(ctx, "db/primary/password")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+}
+
+func TestHandler_delete(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodDelete, "/entries/k", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	_, err := v.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	rec = do(t, h, http.MethodGet, "/entries/k", "")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
+
+func TestHandler_list(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	v := vault.New()
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "b", Value: "secret-b"}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "a", Value: "secret-a"}))
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodGet, "/entries", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	var entries []vault.Entry
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &entries))
+	require.Len(t, entries, 2)
+	assert.Equal(t, "a", entries[0].Key)
+	assert.Equal(t, "secret-a", entries[0].Value)
+
+	for _, target := range []string{"/entries?redact=true", "/entries?redact"} {
+		rec = do(t, h, http.MethodGet, target, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.NotContains(t, rec.Body.String(), "secret", target)
+		assert.Contains(t, rec.Body.String(), `"key": "b"`, target)
+	}
+
+	rec = do(t, h, http.MethodGet, "/entries?redact=maybe", "")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestHandler_refresh(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	errDown := errors.New("down")
+	fail := false
+	v := vault.New(vault.WithSource(vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		if fail {
+			return nil, errDown
+		}
+		return []vault.Entry{{Key: "k", Value: "v"}}, nil
+	})))
+	h := vaulthttp.Handler(v)
+
+	rec := do(t, h, http.MethodPost, "/refresh", "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	e, err := v.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "v", e.Value)
+
+	fail = true
+	rec = do(t, h, http.MethodPost, "/refresh", "")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), "down")
+
+	rec = do(t, h, http.MethodGet, "/refresh", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestHandler_requestContext(t *testing.T) {
+	t.Parallel()
+
+	v := vault.New(vault.WithSource(vault.SourceFunc(func(ctx context.Context) ([]vault.Entry, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequestWithContext(ctx, http.MethodPost, "/refresh", nil)
+	vaulthttp.Handler(v).ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Contains(t, rec.Body.String(), context.Canceled.Error())
+}
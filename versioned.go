@@ -0,0 +1,146 @@
+package vault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// ErrVersionNotFound is returned, wrapped, when [MemoryVersioned.Rollback]
+// asks for a version older than the retained history.
+var ErrVersionNotFound = errors.New("vault: version not found")
+
+type versionedState struct {
+	mu       sync.RWMutex
+	versions map[string][]Entry // oldest first
+	max      int
+}
+
+// MemoryVersioned is an in-memory [Store] that keeps the last few versions
+// of each entry, for auditing rotations. Reads return the latest version;
+// [MemoryVersioned.History] returns them all. It is safe for concurrent
+// use and implements [Namespaced], with separate history per namespace.
+type MemoryVersioned struct {
+	state  *versionedState
+	prefix string
+}
+
+// NewMemoryVersioned creates an empty store that keeps up to maxVersions
+// versions of each key, discarding the oldest beyond that. Values less
+// than one are treated as one.
+func NewMemoryVersioned(maxVersions int) *MemoryVersioned {
+	return &MemoryVersioned{
+		state: &versionedState{
+			versions: make(map[string][]Entry),
+			max:      max(maxVersions, 1),
+		},
+	}
+}
+
+// WithNamespace returns a [Store] scoped to the given namespace. The
+// returned store shares the same backing data as the original. It panics
+// if ns is not valid according to [ValidateNamespace].
+func (m *MemoryVersioned) WithNamespace(ns string) Store {
+	mustValidateNamespace(ns)
+	return &MemoryVersioned{
+		state:  m.state,
+		prefix: ns + "/",
+	}
+}
+
+// Get returns the latest version of an entry.
+func (m *MemoryVersioned) Get(_ context.Context, key string) (Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	h := m.state.versions[m.prefix+key]
+	if len(h) == 0 {
+		return Entry{}, ErrNotFound
+	}
+	return h[len(h)-1].clone(), nil
+}
+
+// History returns the retained versions of an entry, newest first. It
+// returns [ErrNotFound] if the key has no versions.
+func (m *MemoryVersioned) History(_ context.Context, key string) ([]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	h := m.state.versions[m.prefix+key]
+	if len(h) == 0 {
+		return nil, ErrNotFound
+	}
+	history := make([]Entry, len(h))
+	for i, e := range h {
+		history[len(h)-1-i] = e.clone()
+	}
+	return history, nil
+}
+
+// Set stores entry as the latest version of its key.
+func (m *MemoryVersioned) Set(_ context.Context, entry Entry) error {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	m.state.push(m.prefix+entry.Key, entry.clone())
+	return nil
+}
+
+// Rollback makes an older version of key current again. versionsBack
+// counts back from the current version, so 1 restores the version before
+// it. The restored entry is appended as a new version, unchanged, so the
+// rollback is itself recorded and can be undone the same way. It returns
+// [ErrNotFound] if key has no versions and [ErrVersionNotFound] if
+// versionsBack is less than one or reaches past the retained history.
+func (m *MemoryVersioned) Rollback(_ context.Context, key string, versionsBack int) error {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	h := m.state.versions[m.prefix+key]
+	if len(h) == 0 {
+		return ErrNotFound
+	}
+	if versionsBack < 1 || versionsBack >= len(h) {
+		return fmt.Errorf("vault: rollback %q by %d: %w (%d retained)", key, versionsBack, ErrVersionNotFound, len(h))
+	}
+	m.state.push(m.prefix+key, h[len(h)-1-versionsBack])
+	return nil
+}
+
+// Delete removes an entry and its history.
+func (m *MemoryVersioned) Delete(_ context.Context, key string) error {
+	m.state.mu.Lock()
+	defer m.state.mu.Unlock()
+
+	delete(m.state.versions, m.prefix+key)
+	return nil
+}
+
+// List returns the latest version of every entry in the current
+// namespace.
+func (m *MemoryVersioned) List(_ context.Context) ([]Entry, error) {
+	m.state.mu.RLock()
+	defer m.state.mu.RUnlock()
+
+	entries := make([]Entry, 0, len(m.state.versions))
+	for k, h := range m.state.versions {
+		if rest, ok := strings.CutPrefix(k, m.prefix); ok && rest != "" {
+			entries = append(entries, h[len(h)-1].clone())
+		}
+	}
+
+	return entries, nil
+}
+
+// push appends e as the latest version of k, dropping the oldest versions
+// beyond the limit. The caller must hold s.mu.
+func (s *versionedState) push(k string, e Entry) {
+	h := append(s.versions[k], e)
+	if over := len(h) - s.max; over > 0 {
+		h = slices.Delete(h, 0, over)
+	}
+	s.versions[k] = h
+}
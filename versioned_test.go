@@ -0,0 +1,147 @@
+package vault_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func values(entries []vault.Entry) []string {
+	out := make([]string, len(entries))
+	for i, e := range entries {
+		out[i] = e.Value
+	}
+	return out
+}
+
+func TestMemoryVersioned_history(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(3)
+
+	for i := range 5 {
+		require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: fmt.Sprint(i)}))
+	}
+
+	got, err := m.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "4", got.Value)
+
+	history, err := m.History(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4", "3", "2"}, values(history))
+
+	entries, err := m.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"4"}, values(entries))
+}
+
+func TestMemoryVersioned_deleteClearsHistory(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(2)
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: "a"}))
+	require.NoError(t, m.Delete(ctx, "k"))
+
+	_, err := m.Get(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+	_, err = m.History(ctx, "k")
+	require.ErrorIs(t, err, vault.ErrNotFound)
+
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: "b"}))
+	history, err := m.History(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, values(history))
+}
+
+func TestMemoryVersioned_namespaces(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(5)
+	prod, ok := m.WithNamespace("prod").(*vault.MemoryVersioned)
+	require.True(t, ok)
+	qa, ok := m.WithNamespace("qa").(*vault.MemoryVersioned)
+	require.True(t, ok)
+
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "k", Value: "p1"}))
+	require.NoError(t, prod.Set(ctx, vault.Entry{Key: "k", Value: "p2"}))
+	require.NoError(t, qa.Set(ctx, vault.Entry{Key: "k", Value: "q1"}))
+
+	history, err := prod.History(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"p2", "p1"}, values(history))
+
+	history, err = qa.History(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"q1"}, values(history))
+
+	entries, err := qa.List(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"q1"}, values(entries))
+}
+
+func TestMemoryVersioned_rotationThroughVault(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(2)
+	v := vault.New(vault.WithStore(m))
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "token", Value: "v1"}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "token", Value: "v2"}))
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "token", Value: "v3"}))
+
+	history, err := m.History(ctx, "token")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v3", "v2"}, values(history))
+}
+
+func TestMemoryVersioned_Rollback(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(5)
+	for _, v := range []string{"good", "better", "broken"} {
+		require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: v}))
+	}
+
+	require.NoError(t, m.Rollback(ctx, "k", 2))
+
+	got, err := m.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "good", got.Value)
+
+	history, err := m.History(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good", "broken", "better", "good"}, values(history))
+
+	require.NoError(t, m.Rollback(ctx, "k", 1), "a rollback can itself be rolled back")
+	got, err = m.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "broken", got.Value)
+}
+
+func TestMemoryVersioned_Rollback_tooFar(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	m := vault.NewMemoryVersioned(5)
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: "a"}))
+	require.NoError(t, m.Set(ctx, vault.Entry{Key: "k", Value: "b"}))
+
+	require.ErrorIs(t, m.Rollback(ctx, "k", 2), vault.ErrVersionNotFound)
+	require.ErrorIs(t, m.Rollback(ctx, "k", 0), vault.ErrVersionNotFound)
+	require.ErrorIs(t, m.Rollback(ctx, "missing", 1), vault.ErrNotFound)
+
+	got, err := m.Get(ctx, "k")
+	require.NoError(t, err)
+	assert.Equal(t, "b", got.Value)
+}
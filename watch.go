@@ -0,0 +1,108 @@
+package vault
+
+import (
+	"context"
+	"sync"
+)
+
+// Op describes the kind of change an [Event] reports.
+type Op int
+
+// Change operations reported by [Vault.Watch].
+const (
+	// OpSet reports an entry written with [Store.Set], including rotations.
+	OpSet Op = iota + 1
+	// OpDelete reports an entry removed with [Store.Delete].
+	OpDelete
+	// OpRefresh reports an entry written by [Vault.Refresh].
+	OpRefresh
+)
+
+// String returns the lower-case name of the operation.
+func (o Op) String() string {
+	switch o {
+	case OpSet:
+		return "set"
+	case OpDelete:
+		return "delete"
+	case OpRefresh:
+		return "refresh"
+	default:
+		return "unknown"
+	}
+}
+
+// Event is a change delivered by [Vault.Watch]. For [OpDelete], Entry is
+// the zero value.
+type Event struct {
+	Key   string
+	Entry Entry
+	Op    Op
+}
+
+// watchBuffer is the number of events buffered per watcher. When a
+// watcher falls behind, its oldest buffered event is dropped to make room.
+const watchBuffer = 64
+
+// watchers fans events out to the channels returned by [Vault.Watch].
+type watchers struct {
+	mu   sync.Mutex
+	subs map[chan Event]struct{}
+}
+
+// Watch returns a channel of changes made through the vault. The channel
+// is closed when ctx is cancelled.
+//
+// Each watcher has a buffer of 64 events. Publishing never blocks: if a
+// watcher's buffer is full, its oldest event is discarded to make room, so
+// a slow reader sees the most recent changes but may miss older ones.
+// Changes made directly to the underlying store are not observed.
+func (v *vault) Watch(ctx context.Context) (<-chan Event, error) {
+	if err := v.checkOpen(); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	ch := make(chan Event, watchBuffer)
+
+	v.watch.mu.Lock()
+	if v.watch.subs == nil {
+		v.watch.subs = make(map[chan Event]struct{})
+	}
+	v.watch.subs[ch] = struct{}{}
+	v.watch.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		v.watch.mu.Lock()
+		delete(v.watch.subs, ch)
+		close(ch)
+		v.watch.mu.Unlock()
+	}()
+
+	return ch, nil
+}
+
+// publish delivers ev to every watcher without blocking.
+func (w *watchers) publish(ev Event) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for ch := range w.subs {
+		for {
+			select {
+			case ch <- ev:
+			default:
+				select {
+				case <-ch: // drop the oldest event and try again
+				default:
+				}
+				continue
+			}
+			break
+		}
+	}
+}
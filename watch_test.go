@@ -0,0 +1,113 @@
+package vault_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bjaus/vault"
+)
+
+func receive(t *testing.T, ch <-chan vault.Event) vault.Event {
+	t.Helper()
+
+	select {
+	case ev, ok := <-ch:
+		require.True(t, ok, "channel closed")
+		return ev
+	case <-time.After(time.Second):
+		t.Fatal("no event received")
+		return vault.Event{}
+	}
+}
+
+func TestWatch_set(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v := vault.New()
+	events, err := v.Watch(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Set(ctx, vault.Entry{Key: "k", Value: "v"}))
+
+	ev := receive(t, events)
+	assert.Equal(t, vault.OpSet, ev.Op)
+	assert.Equal(t, "k", ev.Key)
+	assert.Equal(t, "v", ev.Entry.Value)
+}
+
+func TestWatch_deleteAndRefresh(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	src := vault.SourceFunc(func(_ context.Context) ([]vault.Entry, error) {
+		return []vault.Entry{{Key: "remote", Value: "r"}}, nil
+	})
+	v := vault.New(vault.WithSource(src))
+
+	events, err := v.Watch(ctx)
+	require.NoError(t, err)
+
+	require.NoError(t, v.Refresh(ctx))
+	ev := receive(t, events)
+	assert.Equal(t, vault.OpRefresh, ev.Op)
+	assert.Equal(t, "remote", ev.Key)
+
+	require.NoError(t, v.Delete(ctx, "remote"))
+	ev = receive(t, events)
+	assert.Equal(t, vault.OpDelete, ev.Op)
+	assert.Equal(t, "remote", ev.Key)
+}
+
+func TestWatch_cancelClosesChannel(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	v := vault.New()
+	events, err := v.Watch(ctx)
+	require.NoError(t, err)
+
+	cancel()
+
+	select {
+	case _, ok := <-events:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("channel was not closed")
+	}
+
+	// Publishing after the watcher is gone must not panic.
+	require.NoError(t, v.Set(context.Background(), vault.Entry{Key: "k"}))
+}
+
+func TestWatch_slowWatcherDropsOldest(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	v := vault.New()
+	events, err := v.Watch(ctx)
+	require.NoError(t, err)
+
+	const writes = 200
+	for i := range writes {
+		require.NoError(t, v.Set(ctx, vault.Entry{Key: fmt.Sprintf("k%d", i)}))
+	}
+
+	var last vault.Event
+	for len(events) > 0 {
+		last = <-events
+	}
+	assert.Equal(t, fmt.Sprintf("k%d", writes-1), last.Key, "the newest event must be kept")
+}